@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"cafe-pos/backend/domain/abandoned"
+	"cafe-pos/backend/domain/cashier"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AbandonedShiftRepository interface {
+	Create(ctx context.Context, rec *abandoned.Record) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*abandoned.Record, error)
+	FindByShiftID(ctx context.Context, shiftID primitive.ObjectID) (*abandoned.Record, error)
+	Update(ctx context.Context, id primitive.ObjectID, rec *abandoned.Record) error
+	FindByStatus(ctx context.Context, status abandoned.Status) ([]*abandoned.Record, error)
+}
+
+type OpenCashierShiftRepository interface {
+	FindByStatus(ctx context.Context, status cashier.CashierShiftStatus) ([]*cashier.CashierShift, error)
+}
+
+// AbandonedShiftService finds shifts staff forgot to end and flags them for the manager.
+// Flagged waiter and barista shifts can be force-ended, after which a manager has to
+// reconcile their actual end time and cash.
+type AbandonedShiftService struct {
+	recordRepo       AbandonedShiftRepository
+	shiftRepo        ShiftRepository
+	cashierShiftRepo OpenCashierShiftRepository
+	shiftService     *ShiftService
+	policy           abandoned.Policy
+}
+
+func NewAbandonedShiftService(
+	recordRepo AbandonedShiftRepository,
+	shiftRepo ShiftRepository,
+	cashierShiftRepo OpenCashierShiftRepository,
+	shiftService *ShiftService,
+	policy abandoned.Policy,
+) *AbandonedShiftService {
+	return &AbandonedShiftService{
+		recordRepo:       recordRepo,
+		shiftRepo:        shiftRepo,
+		cashierShiftRepo: cashierShiftRepo,
+		shiftService:     shiftService,
+		policy:           policy,
+	}
+}
+
+// DetectAbandonedShifts flags the open shifts past their deadline at now and returns the newly
+// flagged ones. With ForceEnd set in the policy, flagged waiter and barista shifts are ended.
+// Flags of shifts that have since been ended by their staff member are closed.
+func (s *AbandonedShiftService) DetectAbandonedShifts(ctx context.Context, now time.Time) ([]*abandoned.Record, error) {
+	openShifts, err := s.shiftRepo.FindOpenShifts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []*abandoned.Record
+	for _, shift := range openShifts {
+		candidates = append(candidates, &abandoned.Record{
+			ShiftKind: abandoned.KindShift,
+			ShiftID:   shift.ID,
+			UserID:    shift.UserID,
+			UserName:  shift.UserName,
+			Role:      string(shift.RoleType),
+			StartedAt: shift.StartedAt,
+		})
+	}
+	for _, status := range []cashier.CashierShiftStatus{cashier.CashierShiftOpen, cashier.CashierShiftClosureInitiated} {
+		cashierShifts, err := s.cashierShiftRepo.FindByStatus(ctx, status)
+		if err != nil {
+			return nil, err
+		}
+		for _, shift := range cashierShifts {
+			candidates = append(candidates, &abandoned.Record{
+				ShiftKind: abandoned.KindCashierShift,
+				ShiftID:   shift.ID,
+				UserID:    shift.CashierID,
+				UserName:  shift.CashierName,
+				Role:      "cashier",
+				StartedAt: shift.StartTime,
+			})
+		}
+	}
+
+	open := make(map[primitive.ObjectID]bool, len(candidates))
+	var flagged []*abandoned.Record
+	for _, rec := range candidates {
+		open[rec.ShiftID] = true
+		rec.Deadline, rec.Reason = s.policy.Deadline(rec.StartedAt)
+		if now.Before(rec.Deadline) {
+			continue
+		}
+
+		existing, err := s.recordRepo.FindByShiftID(ctx, rec.ShiftID)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			rec.Status = abandoned.StatusFlagged
+			rec.DetectedAt = now
+			if err := s.recordRepo.Create(ctx, rec); err != nil {
+				return nil, err
+			}
+			log.Printf("[Shifts] ⚠️ %s shift of %s started %s is still open (%s), flagged for the manager",
+				rec.Role, rec.UserName, rec.StartedAt.Local().Format("02/01 15:04"), rec.Reason)
+			flagged = append(flagged, rec)
+			existing = rec
+		}
+
+		if s.policy.ForceEnd && existing.Status == abandoned.StatusFlagged && existing.ShiftKind == abandoned.KindShift {
+			if err := s.forceEnd(ctx, existing, "system", now); err != nil {
+				log.Printf("[Shifts] Failed to force-end shift %s: %v", existing.ShiftID.Hex(), err)
+			}
+		}
+	}
+
+	// Staff who end a flagged shift themselves clear the flag
+	stillFlagged, err := s.recordRepo.FindByStatus(ctx, abandoned.StatusFlagged)
+	if err != nil {
+		return nil, err
+	}
+	for _, rec := range stillFlagged {
+		if !open[rec.ShiftID] {
+			rec.Status = abandoned.StatusClosed
+			if err := s.recordRepo.Update(ctx, rec.ID, rec); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return flagged, nil
+}
+
+// RunAbandonedShiftCheck checks for abandoned shifts every interval until ctx is cancelled
+func (s *AbandonedShiftService) RunAbandonedShiftCheck(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.DetectAbandonedShifts(ctx, time.Now()); err != nil {
+			log.Printf("[Shifts] Failed to check for abandoned shifts: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetRecords returns the abandoned shift records, optionally only those with status
+func (s *AbandonedShiftService) GetRecords(ctx context.Context, status abandoned.Status) ([]*abandoned.Record, error) {
+	return s.recordRepo.FindByStatus(ctx, status)
+}
+
+// GetAlerts returns the flagged and force-ended shifts a manager still has to act on, with
+// the number no manager has acknowledged yet. Manager screens poll it like the late orders.
+func (s *AbandonedShiftService) GetAlerts(ctx context.Context) (*abandoned.Alerts, error) {
+	var records []*abandoned.Record
+	for _, status := range []abandoned.Status{abandoned.StatusFlagged, abandoned.StatusForceEnded} {
+		found, err := s.recordRepo.FindByStatus(ctx, status)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, found...)
+	}
+	alerts := abandoned.Summarize(records)
+	return &alerts, nil
+}
+
+// Acknowledge marks a flagged shift as seen so it no longer counts as unread
+func (s *AbandonedShiftService) Acknowledge(ctx context.Context, id primitive.ObjectID, by string) (*abandoned.Record, error) {
+	rec, err := s.recordRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("abandoned shift not found")
+	}
+	rec.Acknowledge(by, time.Now())
+	if err := s.recordRepo.Update(ctx, rec.ID, rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// ForceEnd ends a flagged waiter or barista shift as of its deadline
+func (s *AbandonedShiftService) ForceEnd(ctx context.Context, id primitive.ObjectID, by string) (*abandoned.Record, error) {
+	rec, err := s.recordRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("abandoned shift not found")
+	}
+	// The manager ending the shift has seen the flag
+	rec.Acknowledge(by, time.Now())
+	if err := s.forceEnd(ctx, rec, by, time.Now()); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+func (s *AbandonedShiftService) forceEnd(ctx context.Context, rec *abandoned.Record, by string, now time.Time) error {
+	if err := rec.CanForceEnd(); err != nil {
+		return err
+	}
+	rec.MarkForceEnded(by, now)
+	if _, err := s.shiftService.ForceEndShift(ctx, rec.ShiftID, *rec.AssumedEndAt); err != nil {
+		return err
+	}
+	log.Printf("[Shifts] Force-ended %s shift of %s by %s, reconciliation required", rec.Role, rec.UserName, by)
+	return s.recordRepo.Update(ctx, rec.ID, rec)
+}
+
+// Reconcile records the actual end time and cash of a force-ended shift
+func (s *AbandonedShiftService) Reconcile(ctx context.Context, id primitive.ObjectID, req *abandoned.ReconcileRequest, by string) (*abandoned.Record, error) {
+	rec, err := s.recordRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("abandoned shift not found")
+	}
+	if err := rec.Reconcile(req, by, time.Now()); err != nil {
+		return nil, err
+	}
+	if _, err := s.shiftService.ReconcileShift(ctx, rec.ShiftID, req.ActualEndAt, req.EndCash); err != nil {
+		return nil, err
+	}
+	if err := s.recordRepo.Update(ctx, rec.ID, rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
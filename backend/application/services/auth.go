@@ -60,6 +60,37 @@ func (a *AuthService) Login(ctx context.Context, req *user.LoginRequest) (*user.
 	}, nil
 }
 
+// IssuePriceOverrideToken verifies manager credentials and returns a short-lived
+// token authorising price overrides on orders
+func (a *AuthService) IssuePriceOverrideToken(ctx context.Context, req *user.LoginRequest) (*user.OverrideTokenResponse, error) {
+	u, err := a.userRepo.FindByUsername(ctx, req.Username)
+	if err != nil {
+		return nil, errors.New("invalid credentials")
+	}
+
+	if !u.Active {
+		return nil, errors.New("user is inactive")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(req.Password)); err != nil {
+		return nil, errors.New("invalid credentials")
+	}
+
+	if u.Role != user.RoleManager {
+		return nil, errors.New("only managers can authorise price overrides")
+	}
+
+	token, expiresAt, err := a.jwtService.GeneratePriceOverrideToken(u)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user.OverrideTokenResponse{
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
 func (a *AuthService) HashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
 	return string(bytes), err
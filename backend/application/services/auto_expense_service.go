@@ -4,12 +4,15 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
 	"cafe-pos/backend/domain/expense"
 	"cafe-pos/backend/domain/facility"
 	"cafe-pos/backend/domain/ingredient"
+	"cafe-pos/backend/domain/payroll"
+	"cafe-pos/backend/domain/purchasing"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -101,6 +104,7 @@ func (s *AutoExpenseService) TrackIngredientPurchase(ctx context.Context, ing *i
 		Description:   fmt.Sprintf("Nhập nguyên liệu: %s", ing.Name),
 		PaymentMethod: expense.PaymentMethodCash, // Default to cash
 		Vendor:        ing.Supplier,
+		SupplierID:    ing.SupplierID,
 		Notes:         fmt.Sprintf("Số lượng: %.2f %s", quantity, ing.Unit),
 		SourceType:    expense.SourceTypeIngredient,
 		SourceID:      ing.ID,
@@ -119,6 +123,93 @@ func (s *AutoExpenseService) TrackIngredientPurchase(ctx context.Context, ing *i
 	return nil
 }
 
+// TrackPurchaseOrderReceipt creates an expense record for a delivery received against a purchase order
+// and returns the expense ID. This is called when goods are received.
+func (s *AutoExpenseService) TrackPurchaseOrderReceipt(ctx context.Context, po *purchasing.PurchaseOrder, receipt *purchasing.Receipt, username string) (primitive.ObjectID, error) {
+	// Skip if nothing to pay
+	if receipt.Total <= 0 {
+		log.Printf("[AutoExpense] Skipping purchase order tracking: zero amount (PO: %s)", po.PONumber)
+		return primitive.NilObjectID, nil
+	}
+
+	// Get or create category
+	categoryID, err := s.GetOrCreateCategory(ctx, expense.CategoryIngredient)
+	if err != nil {
+		log.Printf("[AutoExpense] Failed to get/create category for purchase order: %v", err)
+		return primitive.NilObjectID, err
+	}
+
+	items := make([]string, 0, len(receipt.Lines))
+	for _, l := range receipt.Lines {
+		items = append(items, fmt.Sprintf("%s %.2f %s", l.IngredientName, l.Quantity, l.Unit))
+	}
+
+	// Create expense record
+	exp := &expense.Expense{
+		Date:          receipt.ReceivedAt,
+		CategoryID:    categoryID,
+		Amount:        receipt.Total,
+		Description:   fmt.Sprintf("Nhập hàng theo đơn %s", po.PONumber),
+		PaymentMethod: expense.PaymentMethodCash, // Default to cash
+		Vendor:        po.Supplier,
+		SupplierID:    po.SupplierID,
+		Notes:         strings.Join(items, ", "),
+		SourceType:    expense.SourceTypePurchaseOrder,
+		SourceID:      po.ID,
+		CreatedBy:     username, // Set to the person receiving the goods
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	if err := s.expenseService.CreateExpense(ctx, exp); err != nil {
+		log.Printf("[AutoExpense] Failed to create expense for purchase order: %v", err)
+		return primitive.NilObjectID, err
+	}
+
+	log.Printf("[AutoExpense] Tracked purchase order receipt: %s - Amount: %.2f VND", po.PONumber, receipt.Total)
+	return exp.ID, nil
+}
+
+// TrackPayroll creates a salary expense for the net pay of a payroll run and returns the
+// expense ID. This is called when the run is posted.
+func (s *AutoExpenseService) TrackPayroll(ctx context.Context, run *payroll.PayrollRun, username string) (primitive.ObjectID, error) {
+	// Skip if nothing to pay
+	if run.TotalNet <= 0 {
+		log.Printf("[AutoExpense] Skipping payroll tracking: zero amount (run: %s)", run.ID.Hex())
+		return primitive.NilObjectID, nil
+	}
+
+	// Get or create category
+	categoryID, err := s.GetOrCreateCategory(ctx, expense.CategorySalary)
+	if err != nil {
+		log.Printf("[AutoExpense] Failed to get/create category for payroll: %v", err)
+		return primitive.NilObjectID, err
+	}
+
+	period := fmt.Sprintf("%s - %s", run.From.Format("02/01/2006"), run.To.Format("02/01/2006"))
+	exp := &expense.Expense{
+		Date:          time.Now(),
+		CategoryID:    categoryID,
+		Amount:        run.TotalNet,
+		Description:   fmt.Sprintf("Lương nhân viên %s", period),
+		PaymentMethod: expense.PaymentMethodCash, // Default to cash
+		Notes:         fmt.Sprintf("%d phiếu lương, tổng lương %.0f, khấu trừ %.0f", len(run.Payslips), run.TotalGross, run.TotalDeductions),
+		SourceType:    expense.SourceTypePayroll,
+		SourceID:      run.ID,
+		CreatedBy:     username, // Set to the person posting the payroll
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	if err := s.expenseService.CreateExpense(ctx, exp); err != nil {
+		log.Printf("[AutoExpense] Failed to create expense for payroll: %v", err)
+		return primitive.NilObjectID, err
+	}
+
+	log.Printf("[AutoExpense] Tracked payroll %s - Amount: %.2f VND", period, run.TotalNet)
+	return exp.ID, nil
+}
+
 // TrackFacilityPurchase creates an expense record for facility purchase
 // This is called when creating a new facility
 func (s *AutoExpenseService) TrackFacilityPurchase(ctx context.Context, fac *facility.Facility, username string) error {
@@ -143,6 +234,7 @@ func (s *AutoExpenseService) TrackFacilityPurchase(ctx context.Context, fac *fac
 		Description:   fmt.Sprintf("Mua thiết bị: %s", fac.Name),
 		PaymentMethod: expense.PaymentMethodCash, // Default to cash
 		Vendor:        fac.Supplier,
+		SupplierID:    fac.SupplierID,
 		Notes:         fmt.Sprintf("Loại: %s, Khu vực: %s, Số lượng: %d", fac.Type, fac.Area, fac.Quantity),
 		SourceType:    expense.SourceTypeFacility,
 		SourceID:      fac.ID,
@@ -163,6 +255,15 @@ func (s *AutoExpenseService) TrackFacilityPurchase(ctx context.Context, fac *fac
 // TrackMaintenance creates an expense record for facility maintenance
 // This is called when creating a maintenance record
 func (s *AutoExpenseService) TrackMaintenance(ctx context.Context, facilityID primitive.ObjectID, facilityName string, cost float64, maintenanceDate time.Time, notes string, username string) error {
+	return s.trackMaintenance(ctx, facilityID, facilityName, cost, maintenanceDate, notes, "", nil, username)
+}
+
+// TrackMaintenanceRecord creates an expense record for a maintenance record, attributed to its vendor
+func (s *AutoExpenseService) TrackMaintenanceRecord(ctx context.Context, record *facility.MaintenanceRecord, facilityName string) error {
+	return s.trackMaintenance(ctx, record.FacilityID, facilityName, record.Cost, record.Date, record.Description, record.Vendor, record.VendorID, record.Username)
+}
+
+func (s *AutoExpenseService) trackMaintenance(ctx context.Context, facilityID primitive.ObjectID, facilityName string, cost float64, maintenanceDate time.Time, notes, vendor string, vendorID *primitive.ObjectID, username string) error {
 	// Skip if no cost
 	if cost <= 0 {
 		log.Printf("[AutoExpense] Skipping maintenance tracking: zero cost (facility: %s)", facilityName)
@@ -183,7 +284,8 @@ func (s *AutoExpenseService) TrackMaintenance(ctx context.Context, facilityID pr
 		Amount:        cost,
 		Description:   fmt.Sprintf("Bảo trì: %s", facilityName),
 		PaymentMethod: expense.PaymentMethodCash, // Default to cash
-		Vendor:        "",                         // Vendor info might be in notes
+		Vendor:        vendor,
+		SupplierID:    vendorID,
 		Notes:         notes,
 		SourceType:    expense.SourceTypeMaintenance,
 		SourceID:      facilityID,
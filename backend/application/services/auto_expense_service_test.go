@@ -22,6 +22,12 @@ func setupAutoExpenseTestDB(t *testing.T) (*mongo.Database, *AutoExpenseService,
 	if err != nil {
 		t.Fatalf("Failed to connect to MongoDB: %v", err)
 	}
+	pingCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	if err := client.Ping(pingCtx, nil); err != nil {
+		client.Disconnect(ctx)
+		t.Skipf("MongoDB not available: %v", err)
+	}
 
 	dbName := "cafe_pos_auto_expense_test_" + primitive.NewObjectID().Hex()
 	db := client.Database(dbName)
@@ -130,7 +136,7 @@ func TestTrackIngredientPurchase(t *testing.T) {
 		}
 		quantity := 5.0
 
-		err := service.TrackIngredientPurchase(ctx, ing, quantity)
+		err := service.TrackIngredientPurchase(ctx, ing, quantity, "admin")
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
 		}
@@ -172,7 +178,7 @@ func TestTrackIngredientPurchase(t *testing.T) {
 		}
 		quantity := 5.0
 
-		err := service.TrackIngredientPurchase(ctx, ing, quantity)
+		err := service.TrackIngredientPurchase(ctx, ing, quantity, "admin")
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
 		}
@@ -195,7 +201,7 @@ func TestTrackIngredientPurchase(t *testing.T) {
 		}
 		quantity := 0.0 // Zero quantity
 
-		err := service.TrackIngredientPurchase(ctx, ing, quantity)
+		err := service.TrackIngredientPurchase(ctx, ing, quantity, "admin")
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
 		}
@@ -226,7 +232,7 @@ func TestTrackFacilityPurchase(t *testing.T) {
 			Status:       facility.StatusInUse,
 		}
 
-		err := service.TrackFacilityPurchase(ctx, fac)
+		err := service.TrackFacilityPurchase(ctx, fac, "admin")
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
 		}
@@ -273,7 +279,7 @@ func TestTrackFacilityPurchase(t *testing.T) {
 			Status:       facility.StatusInUse,
 		}
 
-		err := service.TrackFacilityPurchase(ctx, fac)
+		err := service.TrackFacilityPurchase(ctx, fac, "admin")
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
 		}
@@ -298,7 +304,7 @@ func TestTrackMaintenance(t *testing.T) {
 		maintenanceDate := time.Now()
 		notes := "Replaced grinding blades"
 
-		err := service.TrackMaintenance(ctx, facilityID, facilityName, cost, maintenanceDate, notes)
+		err := service.TrackMaintenance(ctx, facilityID, facilityName, cost, maintenanceDate, notes, "admin")
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
 		}
@@ -340,7 +346,7 @@ func TestTrackMaintenance(t *testing.T) {
 		maintenanceDate := time.Now()
 		notes := "Free warranty service"
 
-		err := service.TrackMaintenance(ctx, facilityID, facilityName, cost, maintenanceDate, notes)
+		err := service.TrackMaintenance(ctx, facilityID, facilityName, cost, maintenanceDate, notes, "admin")
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
 		}
@@ -145,12 +145,12 @@ func (s *CashHandoverService) ConfirmHandoverWithReconciliation(
 	cashierName string,
 ) error {
 	// Get handover
-	handover, err := s.handoverRepo.FindByID(ctx, handoverID)
+	h, err := s.handoverRepo.FindByID(ctx, handoverID)
 	if err != nil {
 		return fmt.Errorf("failed to find handover: %w", err)
 	}
 
-	if handover == nil {
+	if h == nil {
 		return errors.New("handover not found")
 	}
 
@@ -169,7 +169,7 @@ func (s *CashHandoverService) ConfirmHandoverWithReconciliation(
 	}
 
 	// Confirm handover
-	if err := handover.ConfirmHandover(
+	if err := h.ConfirmHandover(
 		cashierShiftID,
 		cashierID,
 		cashierName,
@@ -181,25 +181,25 @@ func (s *CashHandoverService) ConfirmHandoverWithReconciliation(
 	}
 
 	// Update handover in database
-	if err := s.handoverRepo.Update(ctx, handoverID, handover); err != nil {
+	if err := s.handoverRepo.Update(ctx, handoverID, h); err != nil {
 		return fmt.Errorf("failed to update handover: %w", err)
 	}
 
 	// Update cash amounts in shifts
-	if err := s.updateCashAmounts(ctx, handover); err != nil {
+	if err := s.updateCashAmounts(ctx, h); err != nil {
 		return fmt.Errorf("failed to update cash amounts: %w", err)
 	}
 
 	// Create discrepancy record if needed
-	if handover.HasDiscrepancy() {
-		if err := s.createDiscrepancyRecord(ctx, handover); err != nil {
+	if h.HasDiscrepancy() {
+		if err := s.createDiscrepancyRecord(ctx, h); err != nil {
 			return fmt.Errorf("failed to create discrepancy record: %w", err)
 		}
 	}
 
 	// If this was an END_SHIFT handover and it's confirmed, end the waiter shift
-	if handover.Type == handover.HandoverTypeEndShift && handover.Status == handover.HandoverStatusConfirmed {
-		if err := s.endWaiterShift(ctx, handover.WaiterShiftID); err != nil {
+	if h.Type == handover.HandoverTypeEndShift && h.Status == handover.HandoverStatusConfirmed {
+		if err := s.endWaiterShift(ctx, h.WaiterShiftID); err != nil {
 			return fmt.Errorf("failed to end waiter shift: %w", err)
 		}
 	}
@@ -238,31 +238,34 @@ func (s *CashHandoverService) RejectHandover(
 	return nil
 }
 
-// ApproveDiscrepancy handles manager approval/rejection of discrepancies
+// ApproveDiscrepancy handles manager approval/rejection of discrepancies. The discrepancy
+// record is escalated to the manager and gets their resolution, so approved shortages are
+// resolved and can be deducted from pay.
 func (s *CashHandoverService) ApproveDiscrepancy(
 	ctx context.Context,
 	handoverID primitive.ObjectID,
 	managerID primitive.ObjectID,
+	managerName string,
 	approved bool,
 	managerNotes string,
 ) error {
 	// Get handover
-	handover, err := s.handoverRepo.FindByID(ctx, handoverID)
+	h, err := s.handoverRepo.FindByID(ctx, handoverID)
 	if err != nil {
 		return fmt.Errorf("failed to find handover: %w", err)
 	}
 
-	if handover == nil {
+	if h == nil {
 		return errors.New("handover not found")
 	}
 
 	// Approve/reject discrepancy
-	if err := handover.ApproveDiscrepancy(managerID, approved, managerNotes); err != nil {
+	if err := h.ApproveDiscrepancy(managerID, approved, managerNotes); err != nil {
 		return fmt.Errorf("failed to approve discrepancy: %w", err)
 	}
 
 	// Update handover in database
-	if err := s.handoverRepo.Update(ctx, handoverID, handover); err != nil {
+	if err := s.handoverRepo.Update(ctx, handoverID, h); err != nil {
 		return fmt.Errorf("failed to update handover: %w", err)
 	}
 
@@ -273,11 +276,14 @@ func (s *CashHandoverService) ApproveDiscrepancy(
 	}
 
 	if discrepancy != nil {
-		if approved {
-			if err := discrepancy.Resolve("Manager approved discrepancy"); err != nil {
-				return fmt.Errorf("failed to resolve discrepancy: %w", err)
+		if discrepancy.Status == handover.DiscrepancyStatusPending {
+			if err := discrepancy.Escalate(); err != nil {
+				return fmt.Errorf("failed to escalate discrepancy: %w", err)
 			}
 		}
+		if err := discrepancy.SetManagerResolution(managerID, managerName, approved, managerNotes); err != nil {
+			return fmt.Errorf("failed to resolve discrepancy: %w", err)
+		}
 
 		if err := s.discrepancyRepo.Update(ctx, discrepancy.ID, discrepancy); err != nil {
 			return fmt.Errorf("failed to update discrepancy: %w", err)
@@ -345,35 +351,35 @@ func (s *CashHandoverService) GetPendingApprovals(ctx context.Context) ([]*hando
 // Private helper methods
 
 // createDiscrepancyRecord creates a discrepancy record for tracking
-func (s *CashHandoverService) createDiscrepancyRecord(ctx context.Context, handover *handover.CashHandover) error {
-	if !handover.HasDiscrepancy() {
+func (s *CashHandoverService) createDiscrepancyRecord(ctx context.Context, h *handover.CashHandover) error {
+	if !h.HasDiscrepancy() {
 		return nil
 	}
 
 	// Determine responsibility (simplified logic - can be enhanced)
 	responsibility := handover.ResponsibilityUnknown
-	if handover.DiscrepancyReason != nil {
+	if h.DiscrepancyReason != nil {
 		// This would be set during reconciliation
-		if handover.Responsibility != nil {
-			responsibility = *handover.Responsibility
+		if h.Responsibility != nil {
+			responsibility = *h.Responsibility
 		}
 	}
 
 	reason := "Cash discrepancy during handover"
-	if handover.DiscrepancyReason != nil {
-		reason = *handover.DiscrepancyReason
+	if h.DiscrepancyReason != nil {
+		reason = *h.DiscrepancyReason
 	}
 
 	discrepancy, err := handover.NewCashDiscrepancy(
-		handover.ID,
-		handover.RequestedAmount,
-		*handover.ActualAmount,
+		h.ID,
+		h.RequestedAmount,
+		*h.ActualAmount,
 		responsibility,
 		reason,
-		handover.WaiterID,
-		handover.WaiterName,
-		*handover.CashierID,
-		*handover.CashierName,
+		h.WaiterID,
+		h.WaiterName,
+		*h.CashierID,
+		*h.CashierName,
 	)
 	if err != nil {
 		return err
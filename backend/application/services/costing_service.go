@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"cafe-pos/backend/domain/costing"
+	"cafe-pos/backend/domain/ingredient"
+	"cafe-pos/backend/domain/menu"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CostingService calculates the theoretical recipe cost and gross margin of menu items
+// from ingredient costs, and stores a snapshot on each item
+type CostingService struct {
+	menuRepo            MenuRepository
+	ingredientRepo      IngredientRepository
+	orderRepo           OrderRepository
+	targetMarginPercent float64
+}
+
+// MenuEngineeringReport classifies menu items by popularity and contribution margin
+type MenuEngineeringReport struct {
+	From        time.Time                  `json:"from"`
+	To          time.Time                  `json:"to"`
+	Items       []*costing.EngineeringItem `json:"items"`
+	Categories  []costing.CategoryAverages `json:"categories"`
+	GeneratedAt time.Time                  `json:"generated_at"`
+}
+
+func NewCostingService(menuRepo MenuRepository, ingredientRepo IngredientRepository, targetMarginPercent float64) *CostingService {
+	if targetMarginPercent <= 0 {
+		targetMarginPercent = costing.DefaultTargetMarginPercent
+	}
+	return &CostingService{
+		menuRepo:            menuRepo,
+		ingredientRepo:      ingredientRepo,
+		targetMarginPercent: targetMarginPercent,
+	}
+}
+
+// SetOrderRepository sets the order repository used for the menu engineering report
+func (s *CostingService) SetOrderRepository(orderRepo OrderRepository) {
+	s.orderRepo = orderRepo
+}
+
+// TargetMarginPercent returns the configured gross margin target
+func (s *CostingService) TargetMarginPercent() float64 {
+	return s.targetMarginPercent
+}
+
+// GetItemCosts calculates the cost of every menu item
+func (s *CostingService) GetItemCosts(ctx context.Context) ([]*costing.ItemCost, error) {
+	items, err := s.menuRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ingredients, err := s.loadIngredients(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	costs := make([]*costing.ItemCost, 0, len(items))
+	for _, item := range items {
+		costs = append(costs, costing.Calculate(item, ingredients, s.targetMarginPercent, now))
+	}
+	return costs, nil
+}
+
+// GetItemCost calculates the cost of one menu item with the breakdown per recipe line
+func (s *CostingService) GetItemCost(ctx context.Context, id primitive.ObjectID) (*costing.ItemCost, error) {
+	item, err := s.menuRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ingredients, err := s.loadIngredients(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return costing.Calculate(item, ingredients, s.targetMarginPercent, time.Now()), nil
+}
+
+// GetBelowTarget returns the items whose gross margin is below the target
+func (s *CostingService) GetBelowTarget(ctx context.Context) ([]*costing.ItemCost, error) {
+	costs, err := s.GetItemCosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*costing.ItemCost{}
+	for _, c := range costs {
+		if c.BelowTarget {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+// RecalculateAll refreshes the cost snapshot stored on every menu item.
+// It is called whenever ingredient costs change.
+func (s *CostingService) RecalculateAll(ctx context.Context) error {
+	items, err := s.menuRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	ingredients, err := s.loadIngredients(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, item := range items {
+		if err := s.store(ctx, item, costing.Calculate(item, ingredients, s.targetMarginPercent, now)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecalculateItem refreshes the cost snapshot of one menu item after its price or recipe changed
+func (s *CostingService) RecalculateItem(ctx context.Context, item *menu.MenuItem) error {
+	ingredients, err := s.loadIngredients(ctx)
+	if err != nil {
+		return err
+	}
+	return s.store(ctx, item, costing.Calculate(item, ingredients, s.targetMarginPercent, time.Now()))
+}
+
+// GetMenuEngineeringReport classifies menu items into stars, plowhorses, puzzles and dogs
+// from the orders created within the given range and the current recipe costs
+func (s *CostingService) GetMenuEngineeringReport(ctx context.Context, from, to time.Time) (*MenuEngineeringReport, error) {
+	if !from.Before(to) {
+		return nil, errors.New("from must be before to")
+	}
+	if s.orderRepo == nil {
+		return nil, errors.New("order repository is not configured")
+	}
+
+	orders, err := s.orderRepo.FindByDateRange(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.menuRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ingredients, err := s.loadIngredients(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &MenuEngineeringReport{
+		From:        from,
+		To:          to,
+		Items:       costing.BuildEngineering(items, orders, ingredients),
+		GeneratedAt: time.Now(),
+	}
+	report.Categories = costing.Classify(report.Items)
+	sort.SliceStable(report.Items, func(i, j int) bool {
+		if report.Items[i].Category != report.Items[j].Category {
+			return report.Items[i].Category < report.Items[j].Category
+		}
+		return report.Items[i].TotalMargin > report.Items[j].TotalMargin
+	})
+	return report, nil
+}
+
+func (s *CostingService) store(ctx context.Context, item *menu.MenuItem, cost *costing.ItemCost) error {
+	snapshot := cost.Snapshot()
+	if old := item.Costing; old != nil && old.Cost == snapshot.Cost &&
+		old.GrossMarginPercent == snapshot.GrossMarginPercent &&
+		old.BelowTarget == snapshot.BelowTarget && old.Complete == snapshot.Complete {
+		return nil
+	}
+	item.Costing = snapshot
+	// Only the snapshot is written, so a concurrent edit of the item is not overwritten
+	return s.menuRepo.SetCosting(ctx, item.ID, snapshot)
+}
+
+func (s *CostingService) loadIngredients(ctx context.Context) (map[string]*ingredient.Ingredient, error) {
+	list, err := s.ingredientRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ingredients := make(map[string]*ingredient.Ingredient, len(list))
+	for _, ing := range list {
+		ingredients[menu.StockKey(ing.Name)] = ing
+	}
+	return ingredients, nil
+}
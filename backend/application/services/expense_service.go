@@ -9,14 +9,26 @@ import (
 )
 
 type ExpenseService struct {
-	repo *mongodb.ExpenseRepository
+	repo            *mongodb.ExpenseRepository
+	supplierService *SupplierService
 }
 
 func NewExpenseService(repo *mongodb.ExpenseRepository) *ExpenseService {
 	return &ExpenseService{repo: repo}
 }
 
+// SetSupplierService sets the SupplierService so expenses are linked to suppliers by ID or vendor name
+// This is called after service initialization to avoid circular dependencies
+func (s *ExpenseService) SetSupplierService(supplierService *SupplierService) {
+	s.supplierService = supplierService
+}
+
 func (s *ExpenseService) CreateExpense(ctx context.Context, e *expense.Expense) error {
+	if s.supplierService != nil {
+		if err := s.supplierService.linkExpenseSupplier(ctx, e); err != nil {
+			return err
+		}
+	}
 	return s.repo.CreateExpense(ctx, e)
 }
 
@@ -25,6 +37,11 @@ func (s *ExpenseService) GetExpenses(ctx context.Context, filter bson.M) ([]expe
 }
 
 func (s *ExpenseService) UpdateExpense(ctx context.Context, id primitive.ObjectID, e *expense.Expense) error {
+	if s.supplierService != nil {
+		if err := s.supplierService.linkExpenseSupplier(ctx, e); err != nil {
+			return err
+		}
+	}
 	return s.repo.UpdateExpense(ctx, id, e)
 }
 
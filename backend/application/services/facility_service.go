@@ -15,6 +15,7 @@ import (
 type FacilityService struct {
 	repo               *mongodb.FacilityRepository
 	autoExpenseService *AutoExpenseService
+	supplierService    *SupplierService
 }
 
 func NewFacilityService(repo *mongodb.FacilityRepository) *FacilityService {
@@ -27,6 +28,11 @@ func (s *FacilityService) SetAutoExpenseService(autoExpenseService *AutoExpenseS
 	s.autoExpenseService = autoExpenseService
 }
 
+// SetSupplierService sets the SupplierService so facilities and maintenance records reference suppliers
+func (s *FacilityService) SetSupplierService(supplierService *SupplierService) {
+	s.supplierService = supplierService
+}
+
 func (s *FacilityService) GetAllFacilities(ctx context.Context) ([]facility.Facility, error) {
 	return s.repo.GetAll(ctx)
 }
@@ -51,6 +57,13 @@ func (s *FacilityService) CreateFacility(ctx context.Context, f *facility.Facili
 	if f.PurchaseDate.IsZero() {
 		f.PurchaseDate = time.Now()
 	}
+
+	if s.supplierService != nil {
+		var err error
+		if f.SupplierID, f.Supplier, err = s.supplierService.Link(ctx, f.SupplierID, f.Supplier); err != nil {
+			return err
+		}
+	}
 	
 	err := s.repo.Create(ctx, f)
 	if err != nil {
@@ -88,6 +101,12 @@ func (s *FacilityService) UpdateFacility(ctx context.Context, id primitive.Objec
 	if err != nil {
 		return err
 	}
+
+	if s.supplierService != nil {
+		if f.SupplierID, f.Supplier, err = s.supplierService.Link(ctx, f.SupplierID, f.Supplier); err != nil {
+			return err
+		}
+	}
 	
 	err = s.repo.Update(ctx, id, f)
 	if err != nil {
@@ -168,6 +187,13 @@ func (s *FacilityService) CreateMaintenanceRecord(ctx context.Context, record *f
 	if record.Date.IsZero() {
 		record.Date = time.Now()
 	}
+
+	if s.supplierService != nil {
+		var err error
+		if record.VendorID, record.Vendor, err = s.supplierService.Link(ctx, record.VendorID, record.Vendor); err != nil {
+			return err
+		}
+	}
 	
 	err := s.repo.CreateMaintenanceRecord(ctx, record)
 	if err != nil {
@@ -193,7 +219,7 @@ func (s *FacilityService) CreateMaintenanceRecord(ctx context.Context, record *f
 		fac, err := s.repo.GetByID(ctx, record.FacilityID)
 		if err == nil {
 			facilityName := fac.Name
-			if err := s.autoExpenseService.TrackMaintenance(ctx, record.FacilityID, facilityName, record.Cost, record.Date, record.Description, record.Username); err != nil {
+			if err := s.autoExpenseService.TrackMaintenanceRecord(ctx, record, facilityName); err != nil {
 				// Log error but don't fail the operation
 				// The maintenance record was created successfully, expense tracking is secondary
 			}
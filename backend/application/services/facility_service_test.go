@@ -19,6 +19,12 @@ func setupTestDB(t *testing.T) (*mongo.Database, func()) {
 	if err != nil {
 		t.Fatalf("Failed to connect to MongoDB: %v", err)
 	}
+	pingCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	if err := client.Ping(pingCtx, nil); err != nil {
+		client.Disconnect(ctx)
+		t.Skipf("MongoDB not available: %v", err)
+	}
 
 	dbName := "cafe_pos_test_" + primitive.NewObjectID().Hex()
 	db := client.Database(dbName)
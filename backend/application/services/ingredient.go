@@ -2,6 +2,9 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 	"cafe-pos/backend/domain/ingredient"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -16,44 +19,130 @@ type IngredientRepository interface {
 	CreateCategory(ctx context.Context, cat *ingredient.IngredientCategory) error
 	GetCategories(ctx context.Context) ([]ingredient.IngredientCategory, error)
 	DeleteCategory(ctx context.Context, id primitive.ObjectID) error
+	IncrementStock(ctx context.Context, id primitive.ObjectID, delta float64) (*ingredient.Ingredient, error)
+	UpdateCostLayers(ctx context.Context, id primitive.ObjectID, layers []ingredient.CostLayer, costPerUnit float64) error
+	ChangeUnit(ctx context.Context, id primitive.ObjectID, from ingredient.UnitType, factor float64, item *ingredient.Ingredient) error
 }
 
 type StockHistoryRepository interface {
 	Create(ctx context.Context, history *ingredient.StockHistory) error
 	FindByIngredientID(ctx context.Context, ingredientID primitive.ObjectID) ([]*ingredient.StockHistory, error)
+	FindByPeriod(ctx context.Context, from, to time.Time) ([]*ingredient.StockHistory, error)
 }
 
 type IngredientService struct {
 	ingredientRepo   IngredientRepository
 	stockHistoryRepo StockHistoryRepository
 	autoExpenseService *AutoExpenseService
+	menuAvailabilityService *MenuAvailabilityService
+	costingService *CostingService
+	supplierService *SupplierService
+	batchRepo BatchRepository
+	productionRepo ProductionRepository
+	txRunner TransactionRunner
+	costingMethod ingredient.CostingMethod
 }
 
 func NewIngredientService(ingredientRepo IngredientRepository, stockHistoryRepo StockHistoryRepository) *IngredientService {
 	return &IngredientService{
 		ingredientRepo:   ingredientRepo,
 		stockHistoryRepo: stockHistoryRepo,
+		costingMethod:    ingredient.CostingWeightedAverage,
 	}
 }
 
+// SetCostingMethod sets how received stock is costed: weighted average (default) or FIFO
+func (s *IngredientService) SetCostingMethod(method ingredient.CostingMethod) {
+	s.costingMethod = method
+}
+
+// CostingMethod returns the configured costing method
+func (s *IngredientService) CostingMethod() ingredient.CostingMethod {
+	return s.costingMethod
+}
+
 // SetAutoExpenseService sets the AutoExpenseService for automatic expense tracking
 // This is called after service initialization to avoid circular dependencies
 func (s *IngredientService) SetAutoExpenseService(autoExpenseService *AutoExpenseService) {
 	s.autoExpenseService = autoExpenseService
 }
 
+// SetMenuAvailabilityService sets the MenuAvailabilityService so menu items are
+// disabled and re-enabled automatically when stock changes
+func (s *IngredientService) SetMenuAvailabilityService(menuAvailabilityService *MenuAvailabilityService) {
+	s.menuAvailabilityService = menuAvailabilityService
+}
+
+// SetSupplierService sets the SupplierService so ingredients reference supplier entities
+func (s *IngredientService) SetSupplierService(supplierService *SupplierService) {
+	s.supplierService = supplierService
+}
+
+// linkSupplier resolves the supplier of an ingredient from the request's supplier ID or name
+func (s *IngredientService) linkSupplier(ctx context.Context, item *ingredient.Ingredient, supplierID, name string) error {
+	id, err := optionalObjectID(supplierID)
+	if err != nil {
+		return err
+	}
+	item.SupplierID, item.Supplier = id, name
+	if s.supplierService != nil {
+		item.SupplierID, item.Supplier, err = s.supplierService.Link(ctx, id, name)
+	}
+	return err
+}
+
+// SetCostingService sets the CostingService so menu item costs are
+// recalculated when ingredient costs change
+func (s *IngredientService) SetCostingService(costingService *CostingService) {
+	s.costingService = costingService
+}
+
+// recalculateCosts refreshes menu item costs after an ingredient change.
+// Failures are ignored, costs are recalculated on the next change or on demand.
+func (s *IngredientService) recalculateCosts(ctx context.Context) {
+	if s.costingService != nil {
+		s.costingService.RecalculateAll(ctx)
+	}
+}
+
+// refreshMenuAvailability re-evaluates menu availability after a stock change.
+// Failures are ignored, the stock change itself already succeeded.
+func (s *IngredientService) refreshMenuAvailability(ctx context.Context) {
+	if s.menuAvailabilityService != nil {
+		s.menuAvailabilityService.RefreshAvailability(ctx)
+	}
+}
+
 func (s *IngredientService) CreateIngredient(ctx context.Context, req *ingredient.CreateIngredientRequest, username string) (*ingredient.Ingredient, error) {
+	if err := ingredient.ValidateUnit(string(req.Unit)); err != nil {
+		return nil, err
+	}
+	conversions, err := ingredient.NormalizeConversions(req.Conversions)
+	if err != nil {
+		return nil, err
+	}
+
 	item := &ingredient.Ingredient{
 		Name:        req.Name,
 		Category:    req.Category,
-		Unit:        req.Unit,
-		Quantity:    req.Quantity,
+		Unit:        ingredient.NormalizeUnit(string(req.Unit)),
 		MinStock:    req.MinStock,
 		CostPerUnit: req.CostPerUnit,
-		Supplier:    req.Supplier,
+		Conversions: conversions,
+		CostLayers:  []ingredient.CostLayer{},
+	}
+	// The initial stock is the first cost layer
+	item.AddStock(s.costingMethod, req.Quantity, req.CostPerUnit, time.Now(), nil)
+	if err := s.linkSupplier(ctx, item, req.SupplierID, req.Supplier); err != nil {
+		return nil, err
+	}
+	if req.Recipe != nil {
+		if err := s.applyRecipe(ctx, item, req.Recipe); err != nil {
+			return nil, err
+		}
 	}
 
-	err := s.ingredientRepo.Create(ctx, item)
+	err = s.ingredientRepo.Create(ctx, item)
 	if err != nil {
 		return nil, err
 	}
@@ -66,6 +155,9 @@ func (s *IngredientService) CreateIngredient(ctx context.Context, req *ingredien
 		}
 	}
 
+	s.refreshMenuAvailability(ctx)
+	s.recalculateCosts(ctx)
+
 	return item, nil
 }
 
@@ -82,39 +174,115 @@ func (s *IngredientService) UpdateIngredient(ctx context.Context, id primitive.O
 	if err != nil {
 		return nil, err
 	}
+	storedMinStock, storedCost := item.MinStock, item.CostPerUnit
 
+	if req.Unit != "" {
+		if err := ingredient.ValidateUnit(string(req.Unit)); err != nil {
+			return nil, err
+		}
+		// Changing the unit converts the stock, its cost and open batches to the new unit
+		if unit := ingredient.NormalizeUnit(string(req.Unit)); unit != item.Unit {
+			if item, err = s.changeUnit(ctx, id, unit); err != nil {
+				return nil, err
+			}
+		}
+	}
 	if req.Name != "" {
 		item.Name = req.Name
 	}
 	if req.Category != "" {
 		item.Category = req.Category
 	}
-	if req.Unit != "" {
-		item.Unit = req.Unit
-	}
-	if req.Quantity != nil {
-		item.Quantity = *req.Quantity
-	}
-	if req.MinStock != nil {
+	// The edit form sends the minimum stock and cost back unchanged, only new values are applied
+	if req.MinStock != nil && *req.MinStock != storedMinStock {
 		item.MinStock = *req.MinStock
 	}
-	if req.CostPerUnit != nil {
-		item.CostPerUnit = *req.CostPerUnit
+	if req.Supplier != "" || req.SupplierID != "" {
+		if err := s.linkSupplier(ctx, item, req.SupplierID, req.Supplier); err != nil {
+			return nil, err
+		}
 	}
-	if req.Supplier != "" {
-		item.Supplier = req.Supplier
+	if req.Conversions != nil {
+		conversions, err := ingredient.NormalizeConversions(req.Conversions)
+		if err != nil {
+			return nil, err
+		}
+		item.Conversions = conversions
+	}
+	if req.Recipe != nil {
+		if err := s.applyRecipe(ctx, item, req.Recipe); err != nil {
+			return nil, err
+		}
 	}
 
+	// Update leaves the stock alone: quantities change through AdjustStock and stocktakes, and
+	// a cost change is a revaluation below
 	err = s.ingredientRepo.Update(ctx, id, item)
 	if err != nil {
 		return nil, err
 	}
 
+	if req.CostPerUnit != nil && *req.CostPerUnit != storedCost {
+		// A manual cost revalues the stock on hand, later receipts are costed by the costing method.
+		// The edit form always sends the cost, so an unchanged cost keeps the cost layers.
+		err := s.inTransaction(ctx, func(ctx context.Context) error {
+			current, err := s.ingredientRepo.FindByID(ctx, id)
+			if err != nil {
+				return err
+			}
+			if current.CostPerUnit == *req.CostPerUnit {
+				return nil
+			}
+			current.Revalue(*req.CostPerUnit)
+			return s.ingredientRepo.UpdateCostLayers(ctx, id, current.CostLayers, current.CostPerUnit)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.refreshMenuAvailability(ctx)
+	s.recalculateCosts(ctx)
+
+	return s.ingredientRepo.FindByID(ctx, id)
+}
+
+// changeUnit restocks an ingredient in another unit, converting its stock, minimum stock, cost
+// layers and active batches. Units that cannot be converted fail with ingredient.ErrInvalidUnit.
+func (s *IngredientService) changeUnit(ctx context.Context, id primitive.ObjectID, unit ingredient.UnitType) (*ingredient.Ingredient, error) {
+	var item *ingredient.Ingredient
+	err := s.inTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if item, err = s.ingredientRepo.FindByID(ctx, id); err != nil {
+			return err
+		}
+		from := item.Unit
+		factor, err := item.ChangeUnit(unit)
+		if err != nil {
+			return err
+		}
+		if err := s.ingredientRepo.ChangeUnit(ctx, id, from, factor, item); err != nil {
+			return err
+		}
+		if s.batchRepo == nil {
+			return nil
+		}
+		return s.batchRepo.ConvertUnit(ctx, id, from, unit, factor)
+	})
+	if err != nil {
+		return nil, err
+	}
 	return item, nil
 }
 
 func (s *IngredientService) DeleteIngredient(ctx context.Context, id primitive.ObjectID) error {
-	return s.ingredientRepo.Delete(ctx, id)
+	if err := s.ingredientRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.refreshMenuAvailability(ctx)
+	s.recalculateCosts(ctx)
+	return nil
 }
 
 func (s *IngredientService) AdjustStock(ctx context.Context, id primitive.ObjectID, req *ingredient.StockAdjustmentRequest) (*ingredient.Ingredient, error) {
@@ -123,44 +291,205 @@ func (s *IngredientService) AdjustStock(ctx context.Context, id primitive.Object
 		return nil, err
 	}
 
-	beforeQty := item.Quantity
-	item.Quantity += req.Quantity
-	if item.Quantity < 0 {
-		item.Quantity = 0
+	// Quantities entered in another unit (e.g. boxes for an ingredient stocked in pieces)
+	// are converted to the stock unit; incompatible units are rejected
+	quantity, err := item.ToStockUnit(req.Quantity, req.Unit)
+	if err != nil {
+		return nil, err
+	}
+	if (req.LotNumber != "" || req.ExpiryDate != nil) && quantity <= 0 {
+		return nil, errors.New("lot number and expiry date can only be recorded when adding stock")
 	}
-	afterQty := item.Quantity
 
-	err = s.ingredientRepo.Update(ctx, id, item)
+	userID, _ := primitive.ObjectIDFromHex(req.UserID)
+	entry := ingredient.StockHistory{
+		Type:     ingredient.TransactionAdjustment,
+		Reason:   req.Reason,
+		UserID:   userID,
+		Username: req.Username,
+	}
+	now := time.Now()
+	var updated *ingredient.Ingredient
+	err = s.inTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		updated, err = s.moveStock(ctx, id, quantity, entry, func(ctx context.Context, item *ingredient.Ingredient, history *ingredient.StockHistory) error {
+			if quantity > 0 {
+				// The unit cost is given per request unit
+				unitCost := item.CostPerUnit
+				if req.UnitCost > 0 {
+					unitCost = req.UnitCost * req.Quantity / quantity
+				}
+				item.AddStock(s.costingMethod, quantity, unitCost, now, nil)
+				history.UnitCost = unitCost
+				history.Value = quantity * unitCost
+				if req.LotNumber != "" || req.ExpiryDate != nil {
+					batch, err := s.createBatch(ctx, item, quantity, unitCost, req.LotNumber, req.ExpiryDate, nil)
+					if err != nil {
+						return err
+					}
+					history.BatchID = &batch.ID
+				}
+			} else if removed, cost := item.RemoveStock(s.costingMethod, -quantity, now); removed > 0 {
+				history.UnitCost = cost / removed
+				history.Value = -cost
+				draws, err := s.consumeBatches(ctx, id, removed)
+				if err != nil {
+					return err
+				}
+				history.Batches = draws
+			}
+			return nil
+		})
+		return err
+	})
+	if errors.Is(err, ingredient.ErrInsufficientStock) {
+		return nil, fmt.Errorf("%w: cannot remove %g %s of %s", err, -quantity, item.Unit, item.Name)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	// Create stock history record
-	userID, _ := primitive.ObjectIDFromHex(req.UserID)
-	history := &ingredient.StockHistory{
-		IngredientID: id,
-		Type:         ingredient.TransactionAdjustment,
-		Quantity:     req.Quantity,
-		BeforeQty:    beforeQty,
-		AfterQty:     afterQty,
-		Reason:       req.Reason,
-		UserID:       userID,
-		Username:     req.Username,
-	}
-	s.stockHistoryRepo.Create(ctx, history)
-
-	// Track expense for stock IN (positive quantity adjustment)
-	// Only track if AutoExpenseService is configured and quantity is positive
-	if s.autoExpenseService != nil && req.Quantity > 0 {
-		if err := s.autoExpenseService.TrackIngredientPurchase(ctx, item, req.Quantity, req.Username); err != nil {
-			// Log error but don't fail the operation
-			// The stock adjustment was successful, expense tracking is secondary
-		}
+	// Purchases are expensed when goods are received against a purchase order,
+	// manual adjustments only correct the stock level
+
+	s.refreshMenuAvailability(ctx)
+	if updated.CostPerUnit != item.CostPerUnit {
+		s.recalculateCosts(ctx)
 	}
 
+	return updated, nil
+}
+
+// ReceiveStock adds purchased stock received against a purchase order as a new batch and cost
+// layer. The receipt quantity and unit price are converted to the stock unit; the ingredient
+// cost per unit follows from the costing method.
+func (s *IngredientService) ReceiveStock(ctx context.Context, id primitive.ObjectID, receipt *ingredient.StockReceipt) (*ingredient.Ingredient, error) {
+	var item *ingredient.Ingredient
+	err := s.inTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		item, err = s.AddReceivedStock(ctx, id, receipt)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.RefreshAfterStockChange(ctx)
 	return item, nil
 }
 
+// AddReceivedStock is ReceiveStock for a caller that runs it in its own transaction together
+// with other writes. The caller calls RefreshAfterStockChange once the transaction commits.
+func (s *IngredientService) AddReceivedStock(ctx context.Context, id primitive.ObjectID, receipt *ingredient.StockReceipt) (*ingredient.Ingredient, error) {
+	item, err := s.ingredientRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	stockQty, err := item.ToStockUnit(receipt.Quantity, receipt.Unit)
+	if err != nil {
+		return nil, err
+	}
+
+	receiptCost := 0.0
+	if stockQty > 0 {
+		receiptCost = receipt.UnitPrice * receipt.Quantity / stockQty
+	}
+	uid, _ := primitive.ObjectIDFromHex(receipt.UserID)
+	entry := ingredient.StockHistory{
+		Type:            ingredient.TransactionPurchase,
+		Reason:          receipt.Reason,
+		PurchaseOrderID: receipt.PurchaseOrderID,
+		UserID:          uid,
+		Username:        receipt.Username,
+	}
+	now := time.Now()
+	return s.moveStock(ctx, id, stockQty, entry, func(ctx context.Context, item *ingredient.Ingredient, history *ingredient.StockHistory) error {
+		item.AddStock(s.costingMethod, stockQty, receiptCost, now, receipt.PurchaseOrderID)
+		unitCost := receiptCost
+		if unitCost <= 0 {
+			unitCost = item.CostPerUnit
+		}
+		history.UnitCost = unitCost
+		history.Value = stockQty * unitCost
+		if s.batchRepo == nil {
+			return nil
+		}
+		batch, err := s.createBatch(ctx, item, stockQty, unitCost, receipt.LotNumber, receipt.ExpiryDate, receipt.PurchaseOrderID)
+		if err != nil {
+			return err
+		}
+		history.BatchID = &batch.ID
+		return nil
+	})
+}
+
+// RefreshAfterStockChange updates menu availability and recipe costs after stock was received
+func (s *IngredientService) RefreshAfterStockChange(ctx context.Context) {
+	s.refreshMenuAvailability(ctx)
+	s.recalculateCosts(ctx)
+}
+
+// PostStockCounts sets ingredient stock to physically counted quantities, recording each
+// difference in the stock history against the stocktake. Decreases are taken from batches
+// earliest expiry first. The caller runs it in InTransaction together with closing the
+// stocktake, and calls RefreshAfterStockChange once the transaction commits.
+func (s *IngredientService) PostStockCounts(ctx context.Context, counts []ingredient.StockCount, stocktakeID primitive.ObjectID, reason, userID, username string) error {
+	uid, _ := primitive.ObjectIDFromHex(userID)
+	entry := ingredient.StockHistory{
+		Type:        ingredient.TransactionStocktake,
+		Reason:      reason,
+		StocktakeID: &stocktakeID,
+		UserID:      uid,
+		Username:    username,
+	}
+	for _, count := range counts {
+		if err := s.setStockCount(ctx, count.IngredientID, count.Quantity, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setStockCount sets the stock of an ingredient to quantity as a stock movement recorded in
+// the stock history. Decreases are taken from batches earliest expiry first.
+func (s *IngredientService) setStockCount(ctx context.Context, id primitive.ObjectID, quantity float64, entry ingredient.StockHistory) error {
+	// The stock becomes the count: movements made after the ingredient was counted are
+	// overwritten, which is why stock is counted while no sales are made
+	item, err := s.ingredientRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if quantity == item.Quantity {
+		return nil
+	}
+	_, err = s.moveStock(ctx, item.ID, quantity-item.Quantity, entry, func(ctx context.Context, item *ingredient.Ingredient, history *ingredient.StockHistory) error {
+		change, value := item.SetCountedStock(s.costingMethod, quantity, time.Now())
+		history.Value = value
+		if change != 0 {
+			history.UnitCost = value / change
+		}
+		if change < 0 {
+			draws, err := s.consumeBatches(ctx, item.ID, -change)
+			if err != nil {
+				return err
+			}
+			history.Batches = draws
+		}
+		return nil
+	})
+	return err
+}
+
+// GetStockValuation values the stock on hand with the configured costing method
+func (s *IngredientService) GetStockValuation(ctx context.Context) (*ingredient.StockValuation, error) {
+	items, err := s.ingredientRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ingredient.ValueStock(items, s.costingMethod), nil
+}
+
 func (s *IngredientService) GetLowStockIngredients(ctx context.Context) ([]*ingredient.Ingredient, error) {
 	return s.ingredientRepo.FindLowStock(ctx)
 }
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"cafe-pos/backend/domain/ingredient"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type BatchRepository interface {
+	Create(ctx context.Context, b *ingredient.Batch) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*ingredient.Batch, error)
+	FindByIngredient(ctx context.Context, ingredientID primitive.ObjectID, activeOnly bool) ([]*ingredient.Batch, error)
+	FindActiveExpiringBefore(ctx context.Context, before time.Time) ([]*ingredient.Batch, error)
+	Update(ctx context.Context, id primitive.ObjectID, b *ingredient.Batch) error
+	WriteOff(ctx context.Context, id primitive.ObjectID, at time.Time) error
+	Draw(ctx context.Context, id primitive.ObjectID, quantity float64) error
+	ConvertUnit(ctx context.Context, ingredientID primitive.ObjectID, from, to ingredient.UnitType, factor float64) error
+}
+
+// SetBatchRepository enables batch and expiry tracking: received stock is recorded as batches
+// and consumed earliest-expiry first
+func (s *IngredientService) SetBatchRepository(batchRepo BatchRepository) {
+	s.batchRepo = batchRepo
+}
+
+// createBatch records stock added to an ingredient as a batch
+func (s *IngredientService) createBatch(ctx context.Context, item *ingredient.Ingredient, quantity, unitCost float64, lotNumber string, expiryDate *time.Time, poID *primitive.ObjectID) (*ingredient.Batch, error) {
+	if s.batchRepo == nil {
+		return nil, errors.New("batch tracking is not configured")
+	}
+	batch := &ingredient.Batch{
+		IngredientID:    item.ID,
+		IngredientName:  item.Name,
+		Unit:            item.Unit,
+		LotNumber:       lotNumber,
+		ExpiryDate:      expiryDate,
+		InitialQuantity: quantity,
+		Quantity:        quantity,
+		UnitCost:        unitCost,
+		PurchaseOrderID: poID,
+		Status:          ingredient.BatchActive,
+		ReceivedAt:      time.Now(),
+	}
+	if err := s.batchRepo.Create(ctx, batch); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// consumeBatches takes consumed stock from the ingredient's unexpired batches, earliest expiry
+// first. Stock recorded before batches were tracked is consumed without a batch. Each draw is
+// a guarded decrement of the batch, and it runs inside the stock movement, so an error (such
+// as a batch drawn from concurrently) rolls the movement back.
+func (s *IngredientService) consumeBatches(ctx context.Context, ingredientID primitive.ObjectID, quantity float64) ([]ingredient.BatchDraw, error) {
+	if s.batchRepo == nil {
+		return nil, nil
+	}
+	batches, err := s.batchRepo.FindByIngredient(ctx, ingredientID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	draws, _ := ingredient.ConsumeFEFO(batches, quantity, time.Now())
+	for _, d := range draws {
+		if err := s.batchRepo.Draw(ctx, d.BatchID, d.Quantity); err != nil {
+			return nil, fmt.Errorf("lot %s: %w", d.LotNumber, err)
+		}
+	}
+	return draws, nil
+}
+
+// GetBatches returns the batches of an ingredient, newest first
+func (s *IngredientService) GetBatches(ctx context.Context, ingredientID primitive.ObjectID, activeOnly bool) ([]*ingredient.Batch, error) {
+	if s.batchRepo == nil {
+		return []*ingredient.Batch{}, nil
+	}
+	return s.batchRepo.FindByIngredient(ctx, ingredientID, activeOnly)
+}
+
+// GetExpiringBatches returns active batches expiring within the given number of days,
+// including expired batches that have not been written off
+func (s *IngredientService) GetExpiringBatches(ctx context.Context, days int) ([]ingredient.ExpiringBatch, error) {
+	if s.batchRepo == nil {
+		return []ingredient.ExpiringBatch{}, nil
+	}
+	now := time.Now()
+	batches, err := s.batchRepo.FindActiveExpiringBefore(ctx, now.AddDate(0, 0, days+1))
+	if err != nil {
+		return nil, err
+	}
+	return ingredient.ExpiringWithin(batches, now, days), nil
+}
+
+// WriteOffBatch removes the remaining quantity of a batch from stock as waste
+func (s *IngredientService) WriteOffBatch(ctx context.Context, batchID primitive.ObjectID, req *ingredient.WriteOffRequest) (*ingredient.Batch, error) {
+	if s.batchRepo == nil {
+		return nil, errors.New("batch tracking is not configured")
+	}
+
+	now := time.Now()
+	userID, _ := primitive.ObjectIDFromHex(req.UserID)
+	var batch *ingredient.Batch
+	err := s.inTransaction(ctx, func(ctx context.Context) error {
+		// Read in the transaction so the quantity removed is the one being written off
+		var err error
+		if batch, err = s.batchRepo.FindByID(ctx, batchID); err != nil {
+			return errors.New("batch not found")
+		}
+		if batch.Status != ingredient.BatchActive {
+			return fmt.Errorf("cannot write off a batch with status %s", batch.Status)
+		}
+		remaining := batch.Quantity
+
+		// Claim the batch before touching stock: only one of two concurrent write-offs
+		// matches the active batch, the other stops here without removing stock again
+		if err := s.batchRepo.WriteOff(ctx, batch.ID, now); err != nil {
+			return err
+		}
+		batch.Quantity = 0
+		batch.Status = ingredient.BatchWrittenOff
+		batch.WrittenOffAt = &now
+
+		reason := req.Reason
+		if reason == "" {
+			reason = fmt.Sprintf("Hủy lô %s", batch.LotNumber)
+			if batch.IsExpired(now) {
+				reason = fmt.Sprintf("Hủy lô %s hết hạn ngày %s", batch.LotNumber, batch.ExpiryDate.Local().Format("02/01/2006"))
+			}
+		}
+		entry := ingredient.StockHistory{
+			Type:     ingredient.TransactionWaste,
+			Reason:   reason,
+			BatchID:  &batch.ID,
+			UserID:   userID,
+			Username: req.Username,
+		}
+
+		item, err := s.ingredientRepo.FindByID(ctx, batch.IngredientID)
+		if err != nil {
+			return err
+		}
+		// Stock may have been corrected below the batch quantity
+		quantity := math.Min(remaining, item.Quantity)
+		_, err = s.moveStock(ctx, item.ID, -quantity, entry, func(ctx context.Context, item *ingredient.Ingredient, history *ingredient.StockHistory) error {
+			removed, cost := item.RemoveStock(s.costingMethod, quantity, now)
+			history.Value = -cost
+			if removed > 0 {
+				history.UnitCost = cost / removed
+			}
+			history.Batches = []ingredient.BatchDraw{{BatchID: batch.ID, LotNumber: batch.LotNumber, Quantity: removed}}
+			return nil
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.refreshMenuAvailability(ctx)
+
+	return batch, nil
+}
+
+// WriteOffExpiredBatches writes off every active batch whose expiry day has passed. A batch
+// that fails is logged and skipped so it does not hold back the others.
+func (s *IngredientService) WriteOffExpiredBatches(ctx context.Context, at time.Time) ([]*ingredient.Batch, error) {
+	if s.batchRepo == nil {
+		return nil, nil
+	}
+	batches, err := s.batchRepo.FindActiveExpiringBefore(ctx, at)
+	if err != nil {
+		return nil, err
+	}
+
+	var written []*ingredient.Batch
+	for _, b := range batches {
+		if !b.IsExpired(at) {
+			continue
+		}
+		batch, err := s.WriteOffBatch(ctx, b.ID, &ingredient.WriteOffRequest{Username: "system"})
+		if err != nil {
+			log.Printf("[Batches] Failed to write off expired lot %s of %s (%s): %v", b.LotNumber, b.IngredientName, b.ID.Hex(), err)
+			continue
+		}
+		written = append(written, batch)
+	}
+	return written, nil
+}
+
+// RunExpiredBatchWriteOff writes off expired batches now and then at every interval until ctx is done
+func (s *IngredientService) RunExpiredBatchWriteOff(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		written, err := s.WriteOffExpiredBatches(ctx, time.Now())
+		if err != nil {
+			log.Printf("[Batches] Failed to write off expired batches: %v", err)
+		}
+		for _, b := range written {
+			log.Printf("[Batches] Wrote off expired lot %s of %s (%s)", b.LotNumber, b.IngredientName, b.ID.Hex())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cafe-pos/backend/domain/ingredient"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ProductionRepository interface {
+	Create(ctx context.Context, p *ingredient.Production) error
+	FindByIngredient(ctx context.Context, ingredientID primitive.ObjectID) ([]*ingredient.Production, error)
+}
+
+// SetProductionRepository enables producing batches of prepared items from their recipes
+func (s *IngredientService) SetProductionRepository(productionRepo ProductionRepository) {
+	s.productionRepo = productionRepo
+}
+
+// applyRecipe validates a prepared item recipe against the inventory and sets it on the item
+func (s *IngredientService) applyRecipe(ctx context.Context, item *ingredient.Ingredient, req *ingredient.RecipeRequest) error {
+	byID, err := s.ingredientsByID(ctx)
+	if err != nil {
+		return err
+	}
+	recipe, err := ingredient.NewPreparedRecipe(item, req, byID)
+	if err != nil {
+		return err
+	}
+	item.Recipe = recipe
+	return nil
+}
+
+func (s *IngredientService) ingredientsByID(ctx context.Context) (map[primitive.ObjectID]*ingredient.Ingredient, error) {
+	items, err := s.ingredientRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[primitive.ObjectID]*ingredient.Ingredient, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+	return byID, nil
+}
+
+// ProduceBatch makes a batch of a prepared item from its recipe. The recipe ingredients are
+// taken out of stock, earliest expiry first, and the prepared item is added to stock as a
+// new batch costed at what the ingredients cost.
+func (s *IngredientService) ProduceBatch(ctx context.Context, id primitive.ObjectID, req *ingredient.ProduceRequest) (*ingredient.Production, error) {
+	if s.productionRepo == nil {
+		return nil, errors.New("production is not configured")
+	}
+	byID, err := s.ingredientsByID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	item, ok := byID[id]
+	if !ok {
+		return nil, errors.New("ingredient not found")
+	}
+	if !item.IsPrepared() {
+		return nil, fmt.Errorf("%s has no recipe", item.Name)
+	}
+
+	quantity := item.Recipe.Yield
+	if req.Quantity > 0 {
+		if quantity, err = item.ToStockUnit(req.Quantity, req.Unit); err != nil {
+			return nil, err
+		}
+	}
+	inputs, err := item.PlanProduction(quantity, byID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	uid, _ := primitive.ObjectIDFromHex(req.UserID)
+	production := &ingredient.Production{
+		ID:             primitive.NewObjectID(),
+		IngredientID:   item.ID,
+		IngredientName: item.Name,
+		Unit:           item.Unit,
+		Quantity:       quantity,
+		Notes:          req.Notes,
+		UserID:         uid,
+		Username:       req.Username,
+	}
+	reason := fmt.Sprintf("Sản xuất %g %s %s", quantity, item.Unit, item.Name)
+	entry := ingredient.StockHistory{
+		Type:         ingredient.TransactionProduction,
+		Reason:       reason,
+		ProductionID: &production.ID,
+		UserID:       uid,
+		Username:     req.Username,
+	}
+	expiry := req.ExpiryDate
+	if expiry == nil && item.Recipe.ShelfLifeHours > 0 {
+		at := now.Add(time.Duration(item.Recipe.ShelfLifeHours) * time.Hour)
+		expiry = &at
+	}
+
+	// All ingredients are taken and the prepared item added in one transaction; an ingredient
+	// used up concurrently fails with ingredient.ErrInsufficientStock and rolls back the whole
+	// run. Without transaction support the inputs taken before the failure stay deducted.
+	beforeCost := item.CostPerUnit
+	err = s.inTransaction(ctx, func(ctx context.Context) error {
+		production.Inputs = make([]ingredient.ProductionInput, len(inputs))
+		copy(production.Inputs, inputs)
+		production.TotalCost = 0
+		production.BatchID = nil
+
+		for i := range production.Inputs {
+			input := &production.Inputs[i]
+			_, err := s.moveStock(ctx, input.IngredientID, -input.Quantity, entry, func(ctx context.Context, ing *ingredient.Ingredient, history *ingredient.StockHistory) error {
+				removed, cost := ing.RemoveStock(s.costingMethod, input.Quantity, now)
+				input.Cost = cost
+				history.Value = -cost
+				if removed > 0 {
+					history.UnitCost = cost / removed
+				}
+				draws, err := s.consumeBatches(ctx, ing.ID, removed)
+				if err != nil {
+					return err
+				}
+				history.Batches = draws
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("%s: %w", input.IngredientName, err)
+			}
+			production.TotalCost += input.Cost
+		}
+		production.UnitCost = production.TotalCost / quantity
+
+		var err error
+		item, err = s.moveStock(ctx, id, quantity, entry, func(ctx context.Context, prepared *ingredient.Ingredient, history *ingredient.StockHistory) error {
+			prepared.AddStock(s.costingMethod, quantity, production.UnitCost, now, nil)
+			history.UnitCost = production.UnitCost
+			history.Value = production.TotalCost
+			if s.batchRepo == nil {
+				return nil
+			}
+			batch, err := s.createBatch(ctx, prepared, quantity, production.UnitCost, req.LotNumber, expiry, nil)
+			if err != nil {
+				return err
+			}
+			history.BatchID = &batch.ID
+			production.BatchID = &batch.ID
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return s.productionRepo.Create(ctx, production)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.refreshMenuAvailability(ctx)
+	if item.CostPerUnit != beforeCost {
+		s.recalculateCosts(ctx)
+	}
+
+	return production, nil
+}
+
+// GetProductions returns the production runs of a prepared item, newest first
+func (s *IngredientService) GetProductions(ctx context.Context, id primitive.ObjectID) ([]*ingredient.Production, error) {
+	if s.productionRepo == nil {
+		return []*ingredient.Production{}, nil
+	}
+	return s.productionRepo.FindByIngredient(ctx, id)
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+
+	"cafe-pos/backend/domain/ingredient"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TransactionRunner runs a function in a database transaction; repository calls made with the
+// context it passes take part in the transaction
+type TransactionRunner interface {
+	Run(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// SetTransactionRunner makes stock changes and their stock history entries commit together
+// when the database supports transactions
+func (s *IngredientService) SetTransactionRunner(txRunner TransactionRunner) {
+	s.txRunner = txRunner
+}
+
+// inTransaction runs fn in a transaction if one is configured. fn may run more than once.
+// Without transaction support (a standalone MongoDB) the writes of fn are not rolled back
+// when a later one fails.
+func (s *IngredientService) inTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.txRunner == nil {
+		return fn(ctx)
+	}
+	return s.txRunner.Run(ctx, fn)
+}
+
+// InTransaction runs fn in the same kind of transaction as stock changes, for services that
+// write stock together with their own documents
+func (s *IngredientService) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.inTransaction(ctx, fn)
+}
+
+// moveStock atomically adds delta to the stock of an ingredient and records the change in the
+// stock history, with before and after quantities taken from the atomic update. apply is
+// called with the ingredient at its stock before the change to update its cost layers and
+// complete the history entry; an error from apply fails the movement. Decreases below zero
+// fail with ingredient.ErrInsufficientStock.
+// Call it inside inTransaction so the stock and history writes commit together where the
+// database supports transactions.
+func (s *IngredientService) moveStock(ctx context.Context, id primitive.ObjectID, delta float64, entry ingredient.StockHistory, apply func(ctx context.Context, item *ingredient.Ingredient, history *ingredient.StockHistory) error) (*ingredient.Ingredient, error) {
+	item, err := s.ingredientRepo.IncrementStock(ctx, id, delta)
+	if err != nil {
+		return nil, err
+	}
+
+	afterQty := item.Quantity
+	item.Quantity = afterQty - delta
+	// entry is a copy, a retried transaction starts from the caller's original
+	history := &entry
+	history.IngredientID = id
+	history.Quantity = delta
+	history.BeforeQty = item.Quantity
+	history.AfterQty = afterQty
+	if apply != nil {
+		if err := apply(ctx, item, history); err != nil {
+			return nil, err
+		}
+	}
+	item.Quantity = afterQty
+
+	if err := s.ingredientRepo.UpdateCostLayers(ctx, id, item.CostLayers, item.CostPerUnit); err != nil {
+		return nil, err
+	}
+	if err := s.stockHistoryRepo.Create(ctx, history); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
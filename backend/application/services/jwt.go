@@ -5,6 +5,7 @@ import (
 	"time"
 	"cafe-pos/backend/domain/user"
 	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type JWTService struct {
@@ -15,6 +16,8 @@ type Claims struct {
 	UserID   string    `json:"user_id"`
 	Username string    `json:"username"`
 	Role     user.Role `json:"role"`
+	// Purpose is set on single-purpose tokens such as price overrides, never on login tokens
+	Purpose string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -46,9 +49,59 @@ func (j *JWTService) ValidateToken(tokenString string) (*Claims, error) {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+	// Override tokens are signed with the same key but must not log anyone in
+	if claims, ok := token.Claims.(*Claims); ok && token.Valid && claims.Purpose == "" {
 		return claims, nil
 	}
 
 	return nil, errors.New("invalid token")
-}
\ No newline at end of file
+}
+
+// PriceOverrideTTL is how long a manager price override token stays valid
+const PriceOverrideTTL = 5 * time.Minute
+
+const purposePriceOverride = "price_override"
+
+// OverrideClaims identify the manager who authorised an order price override
+type OverrideClaims struct {
+	ManagerID   string `json:"manager_id"`
+	ManagerName string `json:"manager_name"`
+	Purpose     string `json:"purpose"`
+	jwt.RegisteredClaims
+}
+
+// GeneratePriceOverrideToken issues a short-lived token allowing staff to override item prices
+// of one order. The token ID is recorded on the order it is used for.
+func (j *JWTService) GeneratePriceOverrideToken(manager *user.User) (string, time.Time, error) {
+	expiresAt := time.Now().Add(PriceOverrideTTL)
+	claims := OverrideClaims{
+		ManagerID:   manager.ID.Hex(),
+		ManagerName: manager.Username,
+		Purpose:     purposePriceOverride,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        primitive.NewObjectID().Hex(),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString([]byte(j.secretKey))
+	return signed, expiresAt, err
+}
+
+func (j *JWTService) ValidatePriceOverrideToken(tokenString string) (*OverrideClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &OverrideClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return []byte(j.secretKey), nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if claims, ok := token.Claims.(*OverrideClaims); ok && token.Valid && claims.Purpose == purposePriceOverride && claims.ID != "" {
+		return claims, nil
+	}
+
+	return nil, errors.New("invalid override token")
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"cafe-pos/backend/domain/ingredient"
 	"cafe-pos/backend/domain/menu"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -11,25 +12,108 @@ type MenuRepository interface {
 	FindAll(ctx context.Context) ([]*menu.MenuItem, error)
 	FindByID(ctx context.Context, id primitive.ObjectID) (*menu.MenuItem, error)
 	Update(ctx context.Context, id primitive.ObjectID, item *menu.MenuItem) error
+	SetCosting(ctx context.Context, id primitive.ObjectID, snapshot *menu.CostSnapshot) error
+	SetAvailability(ctx context.Context, id primitive.ObjectID, available, autoDisabled bool) error
+	SetImage(ctx context.Context, id primitive.ObjectID, imageURL, thumbnailURL string, keys []string) error
 	Delete(ctx context.Context, id primitive.ObjectID) error
 }
 
 type MenuService struct {
-	menuRepo MenuRepository
+	menuRepo                MenuRepository
+	categoryRepo            MenuCategoryRepository
+	ingredientRepo          IngredientRepository
+	costingService          *CostingService
+	menuAvailabilityService *MenuAvailabilityService
 }
 
 func NewMenuService(menuRepo MenuRepository) *MenuService {
 	return &MenuService{menuRepo: menuRepo}
 }
 
+// SetIngredientRepository sets the ingredient repository used to check that recipe
+// units can be converted to the units ingredients are stocked in
+func (s *MenuService) SetIngredientRepository(ingredientRepo IngredientRepository) {
+	s.ingredientRepo = ingredientRepo
+}
+
+// recipeChecker loads the inventory once and returns a function rejecting recipe lines
+// whose unit is incompatible with the stock unit of the ingredient.
+// Lines for ingredients not tracked in inventory are accepted.
+func (s *MenuService) recipeChecker(ctx context.Context) (func(recipe []menu.Ingredient) error, error) {
+	stock := make(map[string]*ingredient.Ingredient)
+	if s.ingredientRepo != nil {
+		list, err := s.ingredientRepo.FindAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, ing := range list {
+			stock[menu.StockKey(ing.Name)] = ing
+		}
+	}
+
+	return func(recipe []menu.Ingredient) error {
+		for _, line := range recipe {
+			ing, ok := stock[menu.StockKey(line.Name)]
+			if !ok {
+				continue
+			}
+			if _, err := ing.ToStockUnit(line.Quantity, line.Unit); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
+
+func (s *MenuService) validateRecipe(ctx context.Context, recipe []menu.Ingredient) error {
+	check, err := s.recipeChecker(ctx)
+	if err != nil {
+		return err
+	}
+	return check(recipe)
+}
+
+// SetCostingService sets the CostingService so an item's cost and margin are
+// recalculated when its price or recipe changes
+func (s *MenuService) SetCostingService(costingService *CostingService) {
+	s.costingService = costingService
+}
+
+// recalculateCost refreshes the cost snapshot of an item. Failures are ignored,
+// the menu change itself already succeeded.
+func (s *MenuService) recalculateCost(ctx context.Context, item *menu.MenuItem) {
+	if s.costingService != nil {
+		s.costingService.RecalculateItem(ctx, item)
+	}
+}
+
+// SetMenuAvailabilityService sets the MenuAvailabilityService so an item that cannot be made
+// from current stock is switched off when it is created or its recipe changes
+func (s *MenuService) SetMenuAvailabilityService(menuAvailabilityService *MenuAvailabilityService) {
+	s.menuAvailabilityService = menuAvailabilityService
+}
+
+// refreshAvailability applies stock-based availability to an item. Failures are ignored,
+// the menu change itself already succeeded.
+func (s *MenuService) refreshAvailability(ctx context.Context, item *menu.MenuItem) {
+	if s.menuAvailabilityService != nil {
+		s.menuAvailabilityService.RefreshItem(ctx, item)
+	}
+}
+
 func (s *MenuService) CreateMenuItem(ctx context.Context, req *menu.CreateMenuItemRequest) (*menu.MenuItem, error) {
+	if err := s.validateRecipe(ctx, req.Ingredients); err != nil {
+		return nil, err
+	}
+
 	item := &menu.MenuItem{
-		Name:        req.Name,
-		Price:       req.Price,
-		Category:    req.Category,
-		Description: req.Description,
-		Ingredients: req.Ingredients,
-		Available:   true,
+		Name:         req.Name,
+		Price:        req.Price,
+		Category:     req.Category,
+		Description:  req.Description,
+		Ingredients:  req.Ingredients,
+		Available:    true,
+		Translations: normalizeTranslations(req.Translations),
 	}
 
 	err := s.menuRepo.Create(ctx, item)
@@ -37,6 +121,9 @@ func (s *MenuService) CreateMenuItem(ctx context.Context, req *menu.CreateMenuIt
 		return nil, err
 	}
 
+	s.refreshAvailability(ctx, item)
+	s.recalculateCost(ctx, item)
+
 	return item, nil
 }
 
@@ -67,10 +154,18 @@ func (s *MenuService) UpdateMenuItem(ctx context.Context, id primitive.ObjectID,
 		item.Description = req.Description
 	}
 	if len(req.Ingredients) > 0 {
+		if err := s.validateRecipe(ctx, req.Ingredients); err != nil {
+			return nil, err
+		}
 		item.Ingredients = req.Ingredients
 	}
+	if req.Translations != nil {
+		item.Translations = normalizeTranslations(req.Translations)
+	}
 	if req.Available != nil {
 		item.Available = *req.Available
+		// A manual toggle takes precedence over stock-based availability
+		item.AutoDisabled = false
 	}
 
 	err = s.menuRepo.Update(ctx, id, item)
@@ -78,6 +173,9 @@ func (s *MenuService) UpdateMenuItem(ctx context.Context, id primitive.ObjectID,
 		return nil, err
 	}
 
+	s.refreshAvailability(ctx, item)
+	s.recalculateCost(ctx, item)
+
 	return item, nil
 }
 
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"cafe-pos/backend/domain/ingredient"
+	"cafe-pos/backend/domain/menu"
+)
+
+// MenuAvailabilityService derives menu item availability from ingredient stock
+// using the recipe stored on each menu item
+type MenuAvailabilityService struct {
+	menuRepo        MenuRepository
+	ingredientRepo  IngredientRepository
+	scheduleService *MenuScheduleService
+}
+
+func NewMenuAvailabilityService(menuRepo MenuRepository, ingredientRepo IngredientRepository) *MenuAvailabilityService {
+	return &MenuAvailabilityService{
+		menuRepo:       menuRepo,
+		ingredientRepo: ingredientRepo,
+	}
+}
+
+// SetMenuScheduleService sets the MenuScheduleService so the waiter menu
+// only shows items in their scheduled window, at their current price
+func (s *MenuAvailabilityService) SetMenuScheduleService(scheduleService *MenuScheduleService) {
+	s.scheduleService = scheduleService
+}
+
+// RefreshAvailability marks items that can no longer be made as unavailable and
+// re-enables items that were auto-disabled once their ingredients are back in stock.
+// Items switched off manually by a manager are left untouched.
+func (s *MenuAvailabilityService) RefreshAvailability(ctx context.Context) error {
+	items, stock, err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if err := s.refresh(ctx, item, stock); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RefreshItem applies stock-based availability to one item after it was created or its
+// recipe changed
+func (s *MenuAvailabilityService) RefreshItem(ctx context.Context, item *menu.MenuItem) error {
+	ingredients, err := s.ingredientRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	return s.refresh(ctx, item, stockLevels(ingredients))
+}
+
+// refresh switches an item off when it can no longer be made and back on when it was
+// auto-disabled and can be made again. Only the availability fields are written, so a
+// concurrent edit of the item is not overwritten.
+func (s *MenuAvailabilityService) refresh(ctx context.Context, item *menu.MenuItem, stock map[string]menu.StockLevel) error {
+	portions, tracked := menu.PortionsFromStock(item.Ingredients, stock)
+	if !tracked {
+		return nil
+	}
+
+	switch {
+	case portions == 0 && item.Available:
+		item.Available = false
+		item.AutoDisabled = true
+	case portions > 0 && !item.Available && item.AutoDisabled:
+		item.Available = true
+		item.AutoDisabled = false
+	default:
+		return nil
+	}
+
+	return s.menuRepo.SetAvailability(ctx, item.ID, item.Available, item.AutoDisabled)
+}
+
+// GetWaiterMenu returns the menu items currently on the menu with the number of portions
+// that can still be made from current stock
+func (s *MenuAvailabilityService) GetWaiterMenu(ctx context.Context) ([]*menu.MenuItem, error) {
+	items, stock, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.scheduleService != nil {
+		items, err = s.scheduleService.ApplyToMenu(ctx, items, time.Now())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, item := range items {
+		if portions, tracked := menu.PortionsFromStock(item.Ingredients, stock); tracked {
+			item.RemainingPortions = &portions
+		}
+	}
+
+	return items, nil
+}
+
+func (s *MenuAvailabilityService) load(ctx context.Context) ([]*menu.MenuItem, map[string]menu.StockLevel, error) {
+	items, err := s.menuRepo.FindAll(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	ingredients, err := s.ingredientRepo.FindAll(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return items, stockLevels(ingredients), nil
+}
+
+func stockLevels(ingredients []*ingredient.Ingredient) map[string]menu.StockLevel {
+	stock := make(map[string]menu.StockLevel, len(ingredients))
+	for _, ing := range ingredients {
+		stock[menu.StockKey(ing.Name)] = menu.StockLevel{
+			Quantity:    ing.Quantity,
+			Unit:        string(ing.Unit),
+			Conversions: ing.Conversions,
+		}
+	}
+	return stock
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+
+	"cafe-pos/backend/domain/menu"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MenuCategoryRepository interface {
+	Create(ctx context.Context, cat *menu.Category) error
+	FindAll(ctx context.Context) ([]*menu.Category, error)
+	FindByID(ctx context.Context, id primitive.ObjectID) (*menu.Category, error)
+	Update(ctx context.Context, id primitive.ObjectID, cat *menu.Category) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+// SetCategoryRepository sets the repository of menu categories and their translations
+func (s *MenuService) SetCategoryRepository(categoryRepo MenuCategoryRepository) {
+	s.categoryRepo = categoryRepo
+}
+
+// LocalizeItems fills the display fields of the items for the preferred languages
+func (s *MenuService) LocalizeItems(ctx context.Context, items []*menu.MenuItem, langs []string) error {
+	categories := make(map[string]*menu.Category)
+	if s.categoryRepo != nil {
+		all, err := s.categoryRepo.FindAll(ctx)
+		if err != nil {
+			return err
+		}
+		for _, cat := range all {
+			categories[cat.Name] = cat
+		}
+	}
+
+	for _, item := range items {
+		item.Localize(langs, categories)
+	}
+	return nil
+}
+
+func (s *MenuService) CreateCategory(ctx context.Context, req *menu.CategoryRequest) (*menu.Category, error) {
+	cat := &menu.Category{
+		Name:         req.Name,
+		SortOrder:    req.SortOrder,
+		Translations: normalizeTranslations(req.Translations),
+	}
+
+	if err := s.categoryRepo.Create(ctx, cat); err != nil {
+		return nil, err
+	}
+	return cat, nil
+}
+
+func (s *MenuService) GetCategories(ctx context.Context, langs []string) ([]*menu.Category, error) {
+	categories, err := s.categoryRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, cat := range categories {
+		cat.Localize(langs)
+	}
+	return categories, nil
+}
+
+func (s *MenuService) UpdateCategory(ctx context.Context, id primitive.ObjectID, req *menu.CategoryRequest) (*menu.Category, error) {
+	cat, err := s.categoryRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	cat.Name = req.Name
+	cat.SortOrder = req.SortOrder
+	if req.Translations != nil {
+		cat.Translations = normalizeTranslations(req.Translations)
+	}
+
+	if err := s.categoryRepo.Update(ctx, id, cat); err != nil {
+		return nil, err
+	}
+	return cat, nil
+}
+
+func (s *MenuService) DeleteCategory(ctx context.Context, id primitive.ObjectID) error {
+	return s.categoryRepo.Delete(ctx, id)
+}
+
+// normalizeTranslations keys translations by primary language subtag and drops empty ones
+func normalizeTranslations(translations map[string]menu.Translation) map[string]menu.Translation {
+	result := make(map[string]menu.Translation, len(translations))
+	for lang, t := range translations {
+		lang = menu.NormalizeLanguage(lang)
+		if lang == "" || lang == menu.DefaultLanguage || t.Name == "" {
+			continue
+		}
+		result[lang] = t
+	}
+	return result
+}
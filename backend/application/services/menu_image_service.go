@@ -0,0 +1,151 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"time"
+
+	"cafe-pos/backend/domain/menu"
+	"cafe-pos/backend/infrastructure/imaging"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Image size limits for menu item photos
+const (
+	MaxMenuImageBytes   = 5 << 20
+	MaxMenuImagePixels  = 25_000_000 // a small file can still decode to a huge bitmap
+	MenuThumbnailSize   = 300
+	MenuDisplayMaxWidth = 1024
+	menuImageQuality    = 85
+)
+
+var allowedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// ImageStorage stores binary objects and returns their public URL
+type ImageStorage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) (string, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// MenuImageService handles photo upload for menu items
+type MenuImageService struct {
+	menuRepo MenuRepository
+	storage  ImageStorage
+}
+
+func NewMenuImageService(menuRepo MenuRepository, storage ImageStorage) *MenuImageService {
+	return &MenuImageService{
+		menuRepo: menuRepo,
+		storage:  storage,
+	}
+}
+
+// UploadImage validates the uploaded image, stores a thumbnail and a display size version
+// and replaces any previous image of the menu item
+func (s *MenuImageService) UploadImage(ctx context.Context, id primitive.ObjectID, data []byte) (*menu.MenuItem, error) {
+	item, err := s.menuRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("menu item not found")
+	}
+
+	if len(data) > MaxMenuImageBytes {
+		return nil, fmt.Errorf("image exceeds %d MB", MaxMenuImageBytes>>20)
+	}
+	contentType := http.DetectContentType(data)
+	if !allowedImageTypes[contentType] {
+		return nil, fmt.Errorf("unsupported image type %s, use JPEG, PNG or GIF", contentType)
+	}
+
+	// Check the dimensions from the header before decoding allocates the full bitmap
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("invalid image file")
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, errors.New("invalid image file")
+	}
+	if int64(config.Width)*int64(config.Height) > MaxMenuImagePixels {
+		return nil, fmt.Errorf("image is %dx%d pixels, the limit is %d megapixels", config.Width, config.Height, MaxMenuImagePixels/1_000_000)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("invalid image file")
+	}
+
+	thumb, err := imaging.EncodeJPEG(imaging.Thumbnail(img, MenuThumbnailSize), menuImageQuality)
+	if err != nil {
+		return nil, err
+	}
+	display, err := imaging.EncodeJPEG(imaging.Fit(img, MenuDisplayMaxWidth, MenuDisplayMaxWidth), menuImageQuality)
+	if err != nil {
+		return nil, err
+	}
+
+	// Timestamped keys so clients never see a cached old photo
+	prefix := fmt.Sprintf("menu/%s/%d", id.Hex(), time.Now().Unix())
+	thumbKey := prefix + "-thumb.jpg"
+	displayKey := prefix + "-display.jpg"
+
+	thumbURL, err := s.storage.Put(ctx, thumbKey, thumb, "image/jpeg")
+	if err != nil {
+		return nil, err
+	}
+	displayURL, err := s.storage.Put(ctx, displayKey, display, "image/jpeg")
+	if err != nil {
+		s.storage.Delete(ctx, thumbKey)
+		return nil, err
+	}
+
+	oldKeys := item.ImageKeys
+	item.ThumbnailURL = thumbURL
+	item.ImageURL = displayURL
+	item.ImageKeys = []string{thumbKey, displayKey}
+
+	if err := s.menuRepo.SetImage(ctx, id, item.ImageURL, item.ThumbnailURL, item.ImageKeys); err != nil {
+		s.storage.Delete(ctx, thumbKey)
+		s.storage.Delete(ctx, displayKey)
+		return nil, err
+	}
+
+	s.deleteKeys(ctx, oldKeys)
+	return item, nil
+}
+
+// DeleteImage removes the photo of a menu item
+func (s *MenuImageService) DeleteImage(ctx context.Context, id primitive.ObjectID) (*menu.MenuItem, error) {
+	item, err := s.menuRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("menu item not found")
+	}
+
+	oldKeys := item.ImageKeys
+	item.ImageURL = ""
+	item.ThumbnailURL = ""
+	item.ImageKeys = nil
+
+	if err := s.menuRepo.SetImage(ctx, id, "", "", nil); err != nil {
+		return nil, err
+	}
+
+	s.deleteKeys(ctx, oldKeys)
+	return item, nil
+}
+
+// deleteKeys removes replaced files. Failures only leave orphaned files, so they are ignored.
+func (s *MenuImageService) deleteKeys(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		s.storage.Delete(ctx, key)
+	}
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+
+	"cafe-pos/backend/domain/menu"
+)
+
+// ImportMenu creates or updates menu items from import records, matching existing items by name.
+// If any row is invalid nothing is written; with dryRun the changes are only reported.
+func (s *MenuService) ImportMenu(ctx context.Context, records [][]string, dryRun bool) (*menu.ImportResult, error) {
+	rows, rowErrors := menu.ParseImportRecords(records)
+
+	existing, err := s.menuRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*menu.MenuItem, len(existing))
+	for _, item := range existing {
+		byName[menu.StockKey(item.Name)] = item
+	}
+
+	result := &menu.ImportResult{
+		DryRun:    dryRun,
+		TotalRows: len(rows) + countRows(rowErrors),
+		Rows:      []menu.ImportRowResult{},
+		Errors:    []menu.RowError{},
+	}
+	if rowErrors != nil {
+		result.Errors = rowErrors
+	}
+
+	checkRecipe, err := s.recipeChecker(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if err := checkRecipe(row.Ingredients); err != nil {
+			result.Errors = append(result.Errors, menu.RowError{Row: row.Row, Column: "ingredients", Message: err.Error()})
+		}
+	}
+
+	for _, row := range rows {
+		action := menu.ImportCreate
+		if _, ok := byName[menu.StockKey(row.Name)]; ok {
+			action = menu.ImportUpdate
+			result.Updated++
+		} else {
+			result.Created++
+		}
+		result.Rows = append(result.Rows, menu.ImportRowResult{Row: row.Row, Name: row.Name, Action: action})
+	}
+
+	if dryRun || len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	for _, row := range rows {
+		if item, ok := byName[menu.StockKey(row.Name)]; ok {
+			item.Category = row.Category
+			item.Price = row.Price
+			if row.Has("description") {
+				item.Description = row.Description
+			}
+			if row.Has("ingredients") {
+				item.Ingredients = row.Ingredients
+			}
+			if row.Available != nil {
+				item.Available = *row.Available
+				item.AutoDisabled = false
+			}
+			if err := s.menuRepo.Update(ctx, item.ID, item); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		item := &menu.MenuItem{
+			Name:        row.Name,
+			Price:       row.Price,
+			Category:    row.Category,
+			Description: row.Description,
+			Ingredients: row.Ingredients,
+			Available:   row.Available == nil || *row.Available,
+		}
+		if err := s.menuRepo.Create(ctx, item); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.costingService != nil {
+		s.costingService.RecalculateAll(ctx)
+	}
+
+	result.Applied = true
+	return result, nil
+}
+
+// ExportMenu returns all menu items as import/export records including the header
+func (s *MenuService) ExportMenu(ctx context.Context) ([][]string, error) {
+	items, err := s.menuRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	records := [][]string{menu.ImportColumns}
+	for _, item := range items {
+		records = append(records, menu.ExportRecord(item))
+	}
+	return records, nil
+}
+
+// MenuImportTemplate returns the header and an example row of the import file
+func (s *MenuService) MenuImportTemplate() [][]string {
+	return [][]string{
+		menu.ImportColumns,
+		menu.ExportRecord(&menu.MenuItem{
+			Name:        "Cà phê sữa",
+			Category:    "Cà phê",
+			Price:       30000,
+			Description: "Cà phê sữa đá",
+			Available:   true,
+			Ingredients: []menu.Ingredient{
+				{Name: "Cà phê bột", Quantity: 20, Unit: "g"},
+				{Name: "Sữa đặc", Quantity: 30, Unit: "ml"},
+			},
+		}),
+	}
+}
+
+// countRows counts the distinct data rows that have errors
+func countRows(errs []menu.RowError) int {
+	rows := make(map[int]bool)
+	for _, e := range errs {
+		if e.Row > 1 {
+			rows[e.Row] = true
+		}
+	}
+	return len(rows)
+}
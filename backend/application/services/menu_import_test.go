@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cafe-pos/backend/domain/ingredient"
+	"cafe-pos/backend/domain/menu"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MockMenuRepository keeps menu items in memory
+type MockMenuRepository struct {
+	items map[primitive.ObjectID]*menu.MenuItem
+}
+
+func NewMockMenuRepository(items ...*menu.MenuItem) *MockMenuRepository {
+	m := &MockMenuRepository{items: make(map[primitive.ObjectID]*menu.MenuItem)}
+	for _, item := range items {
+		m.Create(context.Background(), item)
+	}
+	return m
+}
+
+func (m *MockMenuRepository) Create(ctx context.Context, item *menu.MenuItem) error {
+	item.ID = primitive.NewObjectID()
+	stored := *item
+	m.items[item.ID] = &stored
+	return nil
+}
+
+func (m *MockMenuRepository) FindAll(ctx context.Context) ([]*menu.MenuItem, error) {
+	items := make([]*menu.MenuItem, 0, len(m.items))
+	for _, item := range m.items {
+		read := *item
+		items = append(items, &read)
+	}
+	return items, nil
+}
+
+func (m *MockMenuRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*menu.MenuItem, error) {
+	item, ok := m.items[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	read := *item
+	return &read, nil
+}
+
+func (m *MockMenuRepository) Update(ctx context.Context, id primitive.ObjectID, item *menu.MenuItem) error {
+	stored := *item
+	m.items[id] = &stored
+	return nil
+}
+
+func (m *MockMenuRepository) SetCosting(ctx context.Context, id primitive.ObjectID, snapshot *menu.CostSnapshot) error {
+	if item, ok := m.items[id]; ok {
+		item.Costing = snapshot
+	}
+	return nil
+}
+
+func (m *MockMenuRepository) SetAvailability(ctx context.Context, id primitive.ObjectID, available, autoDisabled bool) error {
+	if item, ok := m.items[id]; ok {
+		item.Available = available
+		item.AutoDisabled = autoDisabled
+	}
+	return nil
+}
+
+func (m *MockMenuRepository) SetImage(ctx context.Context, id primitive.ObjectID, imageURL, thumbnailURL string, keys []string) error {
+	if item, ok := m.items[id]; ok {
+		item.ImageURL = imageURL
+		item.ThumbnailURL = thumbnailURL
+		item.ImageKeys = keys
+	}
+	return nil
+}
+
+func (m *MockMenuRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	delete(m.items, id)
+	return nil
+}
+
+// TestImportMenu_PriceOnly tests that a file without description and ingredients columns
+// updates prices and keeps the descriptions and recipes
+func TestImportMenu_PriceOnly(t *testing.T) {
+	latte := &menu.MenuItem{
+		Name:        "Latte",
+		Category:    "Cà phê",
+		Price:       40000,
+		Description: "Espresso và sữa nóng",
+		Available:   true,
+		Ingredients: []menu.Ingredient{{Name: "Sữa tươi", Quantity: 150, Unit: "ml"}},
+	}
+	repo := NewMockMenuRepository(latte)
+	service := NewMenuService(repo)
+
+	records := [][]string{
+		{"name", "category", "price"},
+		{"Latte", "Cà phê", "45000"},
+	}
+	result, err := service.ImportMenu(context.Background(), records, false)
+	if err != nil {
+		t.Fatalf("ImportMenu: %v", err)
+	}
+	if !result.Applied || result.Updated != 1 || len(result.Errors) != 0 {
+		t.Fatalf("Expected one applied update, got %+v", result)
+	}
+
+	got := repo.items[latte.ID]
+	if got.Price != 45000 {
+		t.Errorf("Expected price 45000, got %v", got.Price)
+	}
+	if got.Description != "Espresso và sữa nóng" {
+		t.Errorf("Expected the description kept, got %q", got.Description)
+	}
+	if len(got.Ingredients) != 1 || got.Ingredients[0].Name != "Sữa tươi" {
+		t.Errorf("Expected the recipe kept, got %+v", got.Ingredients)
+	}
+}
+
+// stubIngredientRepository returns a fixed inventory from FindAll
+type stubIngredientRepository struct {
+	IngredientRepository
+	items []*ingredient.Ingredient
+}
+
+func (r *stubIngredientRepository) FindAll(ctx context.Context) ([]*ingredient.Ingredient, error) {
+	return r.items, nil
+}
+
+// TestCreateMenuItem_OutOfStock tests that an item whose recipe cannot be made from
+// current stock is switched off as soon as it is created
+func TestCreateMenuItem_OutOfStock(t *testing.T) {
+	repo := NewMockMenuRepository()
+	ingredients := &stubIngredientRepository{items: []*ingredient.Ingredient{
+		{Name: "Sữa tươi", Quantity: 0, Unit: ingredient.UnitMilliliter},
+	}}
+	service := NewMenuService(repo)
+	service.SetMenuAvailabilityService(NewMenuAvailabilityService(repo, ingredients))
+
+	item, err := service.CreateMenuItem(context.Background(), &menu.CreateMenuItemRequest{
+		Name:        "Latte",
+		Category:    "Cà phê",
+		Price:       40000,
+		Ingredients: []menu.Ingredient{{Name: "Sữa tươi", Quantity: 150, Unit: "ml"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateMenuItem: %v", err)
+	}
+
+	got := repo.items[item.ID]
+	if got.Available || !got.AutoDisabled {
+		t.Errorf("Expected the item auto-disabled, got available=%v auto_disabled=%v", got.Available, got.AutoDisabled)
+	}
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cafe-pos/backend/domain/menu"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MenuScheduleRepository interface {
+	Create(ctx context.Context, s *menu.MenuSchedule) error
+	FindAll(ctx context.Context) ([]*menu.MenuSchedule, error)
+	FindByID(ctx context.Context, id primitive.ObjectID) (*menu.MenuSchedule, error)
+	Update(ctx context.Context, id primitive.ObjectID, s *menu.MenuSchedule) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+type MenuPriceRepository interface {
+	Create(ctx context.Context, e *menu.PriceEntry) error
+	FindByMenuItemID(ctx context.Context, menuItemID primitive.ObjectID) ([]*menu.PriceEntry, error)
+	FindEffectiveBefore(ctx context.Context, at time.Time) ([]*menu.PriceEntry, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+// MenuScheduleService manages time-based menus and scheduled price changes
+type MenuScheduleService struct {
+	scheduleRepo MenuScheduleRepository
+	priceRepo    MenuPriceRepository
+	menuRepo     MenuRepository
+}
+
+func NewMenuScheduleService(scheduleRepo MenuScheduleRepository, priceRepo MenuPriceRepository, menuRepo MenuRepository) *MenuScheduleService {
+	return &MenuScheduleService{
+		scheduleRepo: scheduleRepo,
+		priceRepo:    priceRepo,
+		menuRepo:     menuRepo,
+	}
+}
+
+func (s *MenuScheduleService) CreateSchedule(ctx context.Context, req *menu.MenuScheduleRequest) (*menu.MenuSchedule, error) {
+	schedule := &menu.MenuSchedule{Active: true}
+	if err := applyScheduleRequest(schedule, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.scheduleRepo.Create(ctx, schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (s *MenuScheduleService) GetSchedules(ctx context.Context) ([]*menu.MenuSchedule, error) {
+	return s.scheduleRepo.FindAll(ctx)
+}
+
+func (s *MenuScheduleService) UpdateSchedule(ctx context.Context, id primitive.ObjectID, req *menu.MenuScheduleRequest) (*menu.MenuSchedule, error) {
+	schedule, err := s.scheduleRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyScheduleRequest(schedule, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.scheduleRepo.Update(ctx, id, schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (s *MenuScheduleService) DeleteSchedule(ctx context.Context, id primitive.ObjectID) error {
+	return s.scheduleRepo.Delete(ctx, id)
+}
+
+func applyScheduleRequest(schedule *menu.MenuSchedule, req *menu.MenuScheduleRequest) error {
+	itemIDs := make([]primitive.ObjectID, 0, len(req.MenuItemIDs))
+	for _, hex := range req.MenuItemIDs {
+		id, err := primitive.ObjectIDFromHex(hex)
+		if err != nil {
+			return fmt.Errorf("invalid menu item id %q", hex)
+		}
+		itemIDs = append(itemIDs, id)
+	}
+
+	schedule.Name = req.Name
+	schedule.DaysOfWeek = req.DaysOfWeek
+	schedule.StartTime = req.StartTime
+	schedule.EndTime = req.EndTime
+	schedule.MenuItemIDs = itemIDs
+	if req.Active != nil {
+		schedule.Active = *req.Active
+	}
+
+	return schedule.Validate()
+}
+
+// CreatePriceEntry schedules a new price for a menu item
+func (s *MenuScheduleService) CreatePriceEntry(ctx context.Context, menuItemID primitive.ObjectID, req *menu.CreatePriceEntryRequest, username string) (*menu.PriceEntry, error) {
+	if _, err := s.menuRepo.FindByID(ctx, menuItemID); err != nil {
+		return nil, errors.New("menu item not found")
+	}
+
+	entry := &menu.PriceEntry{
+		MenuItemID:    menuItemID,
+		Price:         req.Price,
+		EffectiveFrom: req.EffectiveFrom,
+		Note:          req.Note,
+		CreatedBy:     username,
+	}
+	if err := s.priceRepo.Create(ctx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *MenuScheduleService) GetPriceEntries(ctx context.Context, menuItemID primitive.ObjectID) ([]*menu.PriceEntry, error) {
+	return s.priceRepo.FindByMenuItemID(ctx, menuItemID)
+}
+
+func (s *MenuScheduleService) DeletePriceEntry(ctx context.Context, id primitive.ObjectID) error {
+	return s.priceRepo.Delete(ctx, id)
+}
+
+// CurrentPrice returns the price of a menu item in effect at the given time
+func (s *MenuScheduleService) CurrentPrice(ctx context.Context, item *menu.MenuItem, at time.Time) (float64, error) {
+	entries, err := s.priceRepo.FindByMenuItemID(ctx, item.ID)
+	if err != nil {
+		return 0, err
+	}
+	return menu.ResolvePrice(item.Price, entries, at), nil
+}
+
+// IsOnMenu reports whether the item is served at the given time according to the menu schedules
+func (s *MenuScheduleService) IsOnMenu(ctx context.Context, menuItemID primitive.ObjectID, at time.Time) (bool, error) {
+	schedules, err := s.scheduleRepo.FindAll(ctx)
+	if err != nil {
+		return false, err
+	}
+	visible, scheduled := menu.ScheduledVisibility(schedules, at)[menuItemID]
+	return !scheduled || visible, nil
+}
+
+// ApplyToMenu drops items outside their scheduled window and sets each remaining item's price
+// to the one in effect at the given time
+func (s *MenuScheduleService) ApplyToMenu(ctx context.Context, items []*menu.MenuItem, at time.Time) ([]*menu.MenuItem, error) {
+	schedules, err := s.scheduleRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.priceRepo.FindEffectiveBefore(ctx, at)
+	if err != nil {
+		return nil, err
+	}
+
+	visibility := menu.ScheduledVisibility(schedules, at)
+	entriesByItem := make(map[primitive.ObjectID][]*menu.PriceEntry)
+	for _, e := range entries {
+		entriesByItem[e.MenuItemID] = append(entriesByItem[e.MenuItemID], e)
+	}
+
+	result := make([]*menu.MenuItem, 0, len(items))
+	for _, item := range items {
+		if visible, scheduled := visibility[item.ID]; scheduled && !visible {
+			continue
+		}
+		item.Price = menu.ResolvePrice(item.Price, entriesByItem[item.ID], at)
+		result = append(result, item)
+	}
+	return result, nil
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"cafe-pos/backend/domain"
 	"cafe-pos/backend/domain/order"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -16,7 +17,7 @@ func TestAcceptOrder_BaristaWithoutShift(t *testing.T) {
 		orders: make(map[string]*order.Order),
 	}
 	mockShiftRepo := NewMockShiftRepository()
-	service := NewOrderService(mockOrderRepo, mockShiftRepo)
+	service := NewOrderService(mockOrderRepo, mockShiftRepo, domain.NewStateMachineManager())
 
 	// Create a QUEUED order
 	orderID := primitive.NewObjectID()
@@ -60,7 +61,7 @@ func TestAcceptOrder_BaristaWithOpenShift(t *testing.T) {
 		orders: make(map[string]*order.Order),
 	}
 	mockShiftRepo := NewMockShiftRepository()
-	service := NewOrderService(mockOrderRepo, mockShiftRepo)
+	service := NewOrderService(mockOrderRepo, mockShiftRepo, domain.NewStateMachineManager())
 
 	// Create a QUEUED order
 	orderID := primitive.NewObjectID()
@@ -81,7 +82,7 @@ func TestAcceptOrder_BaristaWithOpenShift(t *testing.T) {
 		Type:      order.ShiftMorning,
 		StartCash: 0,
 	}
-	shiftService := NewShiftService(mockShiftRepo, mockOrderRepo)
+	shiftService := NewShiftService(mockShiftRepo, mockOrderRepo, domain.NewStateMachineManager())
 	_, err := shiftService.StartShift(context.Background(), shiftReq, baristaID.Hex(), "Barista 1", order.RoleBarista)
 	if err != nil {
 		t.Fatalf("Failed to start shift: %v", err)
@@ -117,7 +118,7 @@ func TestAcceptOrder_BaristaWithClosedShift(t *testing.T) {
 		orders: make(map[string]*order.Order),
 	}
 	mockShiftRepo := NewMockShiftRepository()
-	service := NewOrderService(mockOrderRepo, mockShiftRepo)
+	service := NewOrderService(mockOrderRepo, mockShiftRepo, domain.NewStateMachineManager())
 
 	// Create a QUEUED order
 	orderID := primitive.NewObjectID()
@@ -134,7 +135,7 @@ func TestAcceptOrder_BaristaWithClosedShift(t *testing.T) {
 	baristaID := primitive.NewObjectID()
 
 	// Open and close shift
-	shiftService := NewShiftService(mockShiftRepo, mockOrderRepo)
+	shiftService := NewShiftService(mockShiftRepo, mockOrderRepo, domain.NewStateMachineManager())
 	shift, _ := shiftService.StartShift(context.Background(), &order.StartShiftRequest{
 		Type:      order.ShiftMorning,
 		StartCash: 0,
@@ -159,14 +160,59 @@ func TestAcceptOrder_BaristaWithClosedShift(t *testing.T) {
 	}
 }
 
+// TestAcceptOrder_BaristaOnBreak tests rejection while the barista is on a break
+func TestAcceptOrder_BaristaOnBreak(t *testing.T) {
+	mockOrderRepo := &MockOrderRepositoryForBarista{
+		orders: make(map[string]*order.Order),
+	}
+	mockShiftRepo := NewMockShiftRepository()
+	service := NewOrderService(mockOrderRepo, mockShiftRepo, domain.NewStateMachineManager())
+
+	orderID := primitive.NewObjectID()
+	mockOrderRepo.orders[orderID.Hex()] = &order.Order{
+		ID:          orderID,
+		OrderNumber: "ORD-001",
+		Status:      order.StatusQueued,
+		Items:       []order.OrderItem{{Name: "Coffee", Quantity: 1, Price: 50000}},
+		Total:       50000,
+		CreatedAt:   time.Now(),
+	}
+
+	// Open barista shift and start an unpaid break
+	baristaID := primitive.NewObjectID()
+	shift := &order.Shift{
+		Type:      order.ShiftMorning,
+		Status:    order.ShiftOpen,
+		RoleType:  order.RoleBarista,
+		UserID:    baristaID,
+		StartedAt: time.Now().Add(-2 * time.Hour),
+	}
+	if err := shift.StartBreak(order.BreakUnpaid, "", time.Now()); err != nil {
+		t.Fatalf("Failed to start break: %v", err)
+	}
+	mockShiftRepo.Create(context.Background(), shift)
+
+	_, err := service.AcceptOrder(context.Background(), orderID, baristaID.Hex(), "Barista 1")
+	if err == nil || err.Error() != "barista is on a break and cannot accept orders" {
+		t.Errorf("Expected the barista on a break to be rejected, got: %v", err)
+	}
+
+	// After the break the order can be accepted
+	shift.EndBreak(time.Now())
+	mockShiftRepo.Update(context.Background(), shift.ID, shift)
+	if _, err := service.AcceptOrder(context.Background(), orderID, baristaID.Hex(), "Barista 1"); err != nil {
+		t.Errorf("Expected the order accepted after the break, got: %v", err)
+	}
+}
+
 // TestAcceptOrder_MultipleBaristasDifferentShifts tests multiple baristas with their own shifts
 func TestAcceptOrder_MultipleBaristasDifferentShifts(t *testing.T) {
 	mockOrderRepo := &MockOrderRepositoryForBarista{
 		orders: make(map[string]*order.Order),
 	}
 	mockShiftRepo := NewMockShiftRepository()
-	orderService := NewOrderService(mockOrderRepo, mockShiftRepo)
-	shiftService := NewShiftService(mockShiftRepo, mockOrderRepo)
+	orderService := NewOrderService(mockOrderRepo, mockShiftRepo, domain.NewStateMachineManager())
+	shiftService := NewShiftService(mockShiftRepo, mockOrderRepo, domain.NewStateMachineManager())
 
 	// Create two orders
 	order1ID := primitive.NewObjectID()
@@ -296,3 +342,27 @@ func (m *MockOrderRepositoryForBarista) FindAll(ctx context.Context) ([]*order.O
 	}
 	return orders, nil
 }
+
+func (m *MockOrderRepositoryForBarista) FindByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*order.Order, error) {
+	var orders []*order.Order
+	for _, o := range m.orders {
+		if !o.CreatedAt.Before(startDate) && !o.CreatedAt.After(endDate) {
+			orders = append(orders, o)
+		}
+	}
+	return orders, nil
+}
+
+func (m *MockOrderRepositoryForBarista) OverrideTokenUsed(ctx context.Context, tokenID string, exceptID primitive.ObjectID) (bool, error) {
+	for _, o := range m.orders {
+		if o.ID == exceptID {
+			continue
+		}
+		for _, id := range o.OverrideTokenIDs {
+			if id == tokenID {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
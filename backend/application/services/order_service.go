@@ -19,12 +19,17 @@ type OrderRepository interface {
 	FindByStatus(ctx context.Context, status order.OrderStatus) ([]*order.Order, error)
 	FindByOrderNumber(ctx context.Context, orderNumber string) (*order.Order, error)
 	FindAll(ctx context.Context) ([]*order.Order, error)
+	FindByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*order.Order, error)
+	OverrideTokenUsed(ctx context.Context, tokenID string, exceptID primitive.ObjectID) (bool, error)
 }
 
 type OrderService struct {
 	orderRepo           OrderRepository
 	shiftRepo           ShiftRepository
 	stateMachineManager *domain.StateMachineManager
+	menuRepo            MenuRepository
+	menuScheduleService *MenuScheduleService
+	jwtService          *JWTService
 }
 
 func NewOrderService(
@@ -39,6 +44,108 @@ func NewOrderService(
 	}
 }
 
+// SetMenuRepository sets the MenuRepository used to resolve order items server-side
+// When not set, item names and prices sent by the client are kept as-is
+func (s *OrderService) SetMenuRepository(menuRepo MenuRepository) {
+	s.menuRepo = menuRepo
+}
+
+// SetMenuScheduleService sets the MenuScheduleService used to apply menu schedules and scheduled prices
+// This is called after service initialization to avoid circular dependencies
+func (s *OrderService) SetMenuScheduleService(menuScheduleService *MenuScheduleService) {
+	s.menuScheduleService = menuScheduleService
+}
+
+// SetJWTService sets the JWTService used to validate manager price override tokens
+func (s *OrderService) SetJWTService(jwtService *JWTService) {
+	s.jwtService = jwtService
+}
+
+// resolveItems replaces client-sent names and prices with the canonical menu values.
+// Unknown, unavailable or off-schedule items are rejected. An item may keep a different
+// price only if the request carries a valid manager override token, which is recorded on o
+// and refused if another order already used it.
+func (s *OrderService) resolveItems(ctx context.Context, o *order.Order, items []order.OrderItem, overrideToken string, at time.Time) error {
+	if s.menuRepo == nil {
+		return nil
+	}
+
+	var override *OverrideClaims
+	for i := range items {
+		item := &items[i]
+		if item.Quantity <= 0 {
+			return fmt.Errorf("invalid quantity for item %s", item.MenuItemID.Hex())
+		}
+
+		m, err := s.menuRepo.FindByID(ctx, item.MenuItemID)
+		if err != nil {
+			return fmt.Errorf("menu item %s not found", item.MenuItemID.Hex())
+		}
+		if !m.Available {
+			return fmt.Errorf("%s is not available", m.Name)
+		}
+
+		price := m.Price
+		if s.menuScheduleService != nil {
+			onMenu, err := s.menuScheduleService.IsOnMenu(ctx, m.ID, at)
+			if err != nil {
+				return err
+			}
+			if !onMenu {
+				return fmt.Errorf("%s is not on the menu at this time", m.Name)
+			}
+			if price, err = s.menuScheduleService.CurrentPrice(ctx, m, at); err != nil {
+				return err
+			}
+		}
+
+		item.Name = m.Name
+		item.Price = price
+		item.MenuPrice = 0
+		item.PriceOverriddenBy = ""
+
+		if item.OverridePrice == nil || *item.OverridePrice == price {
+			continue
+		}
+		if *item.OverridePrice < 0 {
+			return fmt.Errorf("invalid override price for %s", m.Name)
+		}
+		if override == nil {
+			if s.jwtService == nil || overrideToken == "" {
+				return errors.New("price override requires a manager override token")
+			}
+			if override, err = s.jwtService.ValidatePriceOverrideToken(overrideToken); err != nil {
+				return errors.New("invalid or expired manager override token")
+			}
+			if err := s.useOverrideToken(ctx, o, override.ID); err != nil {
+				return err
+			}
+		}
+		item.MenuPrice = price
+		item.Price = *item.OverridePrice
+		item.PriceOverriddenBy = override.ManagerName
+	}
+	return nil
+}
+
+// useOverrideToken records the override token on the order, failing if another order used it
+func (s *OrderService) useOverrideToken(ctx context.Context, o *order.Order, tokenID string) error {
+	for _, id := range o.OverrideTokenIDs {
+		if id == tokenID {
+			return nil
+		}
+	}
+	used, err := s.orderRepo.OverrideTokenUsed(ctx, tokenID, o.ID)
+	if err != nil {
+		return err
+	}
+	if used {
+		return order.ErrOverrideTokenUsed
+	}
+	o.OverrideTokenIDs = append(o.OverrideTokenIDs, tokenID)
+	return nil
+}
+
 func (s *OrderService) CreateOrder(ctx context.Context, req *order.CreateOrderRequest, waiterID, waiterName string) (*order.Order, error) {
 	shiftID, _ := primitive.ObjectIDFromHex(req.ShiftID)
 	shift, err := s.shiftRepo.FindByID(ctx, shiftID)
@@ -48,6 +155,7 @@ func (s *OrderService) CreateOrder(ctx context.Context, req *order.CreateOrderRe
 
 	// Generate order number (format: YYYYMMDD-HHMMSS-XXX)
 	now := time.Now()
+
 	orderNumber := fmt.Sprintf("%s-%03d", now.Format("20060102-150405"), now.Nanosecond()/1000000%1000)
 
 	waiterOID, _ := primitive.ObjectIDFromHex(waiterID)
@@ -62,6 +170,9 @@ func (s *OrderService) CreateOrder(ctx context.Context, req *order.CreateOrderRe
 		Note:         req.Note,
 		AmountPaid:   0,
 	}
+	if err := s.resolveItems(ctx, o, o.Items, req.OverrideToken, now); err != nil {
+		return nil, err
+	}
 
 	o.CalculateTotal()
 	
@@ -118,6 +229,10 @@ func (s *OrderService) EditOrder(ctx context.Context, id primitive.ObjectID, req
 		return nil, fmt.Errorf("cannot modify order in state %s", o.Status)
 	}
 
+	if err := s.resolveItems(ctx, o, req.Items, req.OverrideToken, time.Now()); err != nil {
+		return nil, err
+	}
+
 	// Store old total for refund calculation
 	oldTotal := o.Total
 	oldAmountPaid := o.AmountPaid
@@ -214,7 +329,7 @@ func (s *OrderService) SendToBar(ctx context.Context, id primitive.ObjectID) (*o
 }
 
 // AcceptOrder - BR-06: Only Barista can move order to IN_PROGRESS
-// BR-13: Barista must have an open shift to accept orders
+// BR-13: Barista must have an open shift to accept orders, and not be on a break
 func (s *OrderService) AcceptOrder(ctx context.Context, id primitive.ObjectID, baristaID, baristaName string) (*order.Order, error) {
 	o, err := s.orderRepo.FindByID(ctx, id)
 	if err != nil {
@@ -232,6 +347,9 @@ func (s *OrderService) AcceptOrder(ctx context.Context, id primitive.ObjectID, b
 	if err != nil || shift == nil {
 		return nil, errors.New("barista must open a shift before accepting orders")
 	}
+	if shift.OnBreak() {
+		return nil, errors.New("barista is on a break and cannot accept orders")
+	}
 
 	now := time.Now()
 	o.Status = order.StatusInProgress
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"cafe-pos/backend/domain/order"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SLATargetRepository interface {
+	Upsert(ctx context.Context, t *order.SLATarget) error
+	FindAll(ctx context.Context) ([]*order.SLATarget, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+// OrderSLAService computes preparation timings of orders and checks them
+// against the SLA targets configured per menu category.
+type OrderSLAService struct {
+	orderRepo OrderRepository
+	menuRepo  MenuRepository
+	slaRepo   SLATargetRepository
+}
+
+func NewOrderSLAService(orderRepo OrderRepository, menuRepo MenuRepository, slaRepo SLATargetRepository) *OrderSLAService {
+	return &OrderSLAService{
+		orderRepo: orderRepo,
+		menuRepo:  menuRepo,
+		slaRepo:   slaRepo,
+	}
+}
+
+type OrderTimingsResponse struct {
+	OrderID     primitive.ObjectID `json:"order_id"`
+	OrderNumber string             `json:"order_number"`
+	Status      order.OrderStatus  `json:"status"`
+	Timings     order.OrderTimings `json:"timings"`
+}
+
+type BaristaPrepStats struct {
+	BaristaID   primitive.ObjectID  `json:"barista_id"`
+	BaristaName string              `json:"barista_name"`
+	Stats       order.DurationStats `json:"stats"`
+}
+
+type HourPrepStats struct {
+	Hour  int                 `json:"hour"`
+	Stats order.DurationStats `json:"stats"`
+}
+
+type PrepTimeReport struct {
+	From        time.Time           `json:"from"`
+	To          time.Time           `json:"to"`
+	Overall     order.DurationStats `json:"overall"`
+	ByBarista   []BaristaPrepStats  `json:"by_barista"`
+	ByHour      []HourPrepStats     `json:"by_hour"`
+	GeneratedAt time.Time           `json:"generated_at"`
+}
+
+func (s *OrderSLAService) GetTargets(ctx context.Context) ([]*order.SLATarget, error) {
+	return s.slaRepo.FindAll(ctx)
+}
+
+func (s *OrderSLAService) SetTarget(ctx context.Context, req *order.SLATargetRequest) (*order.SLATarget, error) {
+	target := &order.SLATarget{
+		Category:        req.Category,
+		MaxWaitMinutes:  req.MaxWaitMinutes,
+		MaxPrepMinutes:  req.MaxPrepMinutes,
+		MaxServeMinutes: req.MaxServeMinutes,
+	}
+	if err := s.slaRepo.Upsert(ctx, target); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+func (s *OrderSLAService) DeleteTarget(ctx context.Context, id primitive.ObjectID) error {
+	return s.slaRepo.Delete(ctx, id)
+}
+
+func (s *OrderSLAService) GetOrderTimings(ctx context.Context, id primitive.ObjectID) (*OrderTimingsResponse, error) {
+	o, err := s.orderRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &OrderTimingsResponse{
+		OrderID:     o.ID,
+		OrderNumber: o.OrderNumber,
+		Status:      o.Status,
+		Timings:     o.Timings(time.Now()),
+	}, nil
+}
+
+// GetLateOrders returns queued, in-progress and ready orders whose current stage
+// exceeds its SLA target, most overdue first.
+func (s *OrderSLAService) GetLateOrders(ctx context.Context) ([]*order.LateOrder, error) {
+	resolve, err := s.targetResolver(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	late := []*order.LateOrder{}
+	for _, status := range []order.OrderStatus{order.StatusQueued, order.StatusInProgress, order.StatusReady} {
+		orders, err := s.orderRepo.FindByStatus(ctx, status)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range orders {
+			if l := resolve(o).CheckLate(o, now); l != nil {
+				late = append(late, l)
+			}
+		}
+	}
+
+	sort.Slice(late, func(i, j int) bool {
+		return late[i].OverdueMinutes > late[j].OverdueMinutes
+	})
+	return late, nil
+}
+
+// GetPrepTimeReport computes average and p90 preparation time per barista and per hour of day
+// for orders created within the given range
+func (s *OrderSLAService) GetPrepTimeReport(ctx context.Context, from, to time.Time) (*PrepTimeReport, error) {
+	if !from.Before(to) {
+		return nil, errors.New("from must be before to")
+	}
+
+	orders, err := s.orderRepo.FindByDateRange(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var all []float64
+	byBarista := make(map[primitive.ObjectID][]float64)
+	baristaNames := make(map[primitive.ObjectID]string)
+	byHour := make(map[int][]float64)
+
+	for _, o := range orders {
+		d, ok := o.PrepDuration()
+		if !ok {
+			continue
+		}
+		minutes := d.Minutes()
+		all = append(all, minutes)
+		byBarista[o.BaristaID] = append(byBarista[o.BaristaID], minutes)
+		baristaNames[o.BaristaID] = o.BaristaName
+		hour := o.AcceptedAt.Local().Hour()
+		byHour[hour] = append(byHour[hour], minutes)
+	}
+
+	report := &PrepTimeReport{
+		From:        from,
+		To:          to,
+		Overall:     order.NewDurationStats(all),
+		ByBarista:   []BaristaPrepStats{},
+		ByHour:      []HourPrepStats{},
+		GeneratedAt: time.Now(),
+	}
+
+	for id, minutes := range byBarista {
+		report.ByBarista = append(report.ByBarista, BaristaPrepStats{
+			BaristaID:   id,
+			BaristaName: baristaNames[id],
+			Stats:       order.NewDurationStats(minutes),
+		})
+	}
+	sort.Slice(report.ByBarista, func(i, j int) bool {
+		return report.ByBarista[i].BaristaName < report.ByBarista[j].BaristaName
+	})
+
+	for hour, minutes := range byHour {
+		report.ByHour = append(report.ByHour, HourPrepStats{
+			Hour:  hour,
+			Stats: order.NewDurationStats(minutes),
+		})
+	}
+	sort.Slice(report.ByHour, func(i, j int) bool {
+		return report.ByHour[i].Hour < report.ByHour[j].Hour
+	})
+
+	return report, nil
+}
+
+// targetResolver loads menu categories and SLA targets once and returns a function
+// resolving the strictest target over the categories of an order's items
+func (s *OrderSLAService) targetResolver(ctx context.Context) (func(o *order.Order) *order.SLATarget, error) {
+	targets, err := s.slaRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.menuRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byCategory := make(map[string]*order.SLATarget)
+	for _, t := range targets {
+		byCategory[t.Category] = t
+	}
+	defaultTarget := byCategory[""]
+	if defaultTarget == nil {
+		defaultTarget = order.DefaultSLATarget()
+	}
+
+	categoryOf := make(map[primitive.ObjectID]string)
+	for _, item := range items {
+		categoryOf[item.ID] = item.Category
+	}
+
+	return func(o *order.Order) *order.SLATarget {
+		var resolved *order.SLATarget
+		for _, item := range o.Items {
+			t, ok := byCategory[categoryOf[item.MenuItemID]]
+			if !ok {
+				t = defaultTarget
+			}
+			if resolved == nil {
+				resolved = t
+			} else {
+				resolved = resolved.Strictest(t)
+			}
+		}
+		if resolved == nil {
+			return defaultTarget
+		}
+		return resolved
+	}, nil
+}
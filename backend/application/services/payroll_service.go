@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"cafe-pos/backend/domain/handover"
+	"cafe-pos/backend/domain/payroll"
+	"cafe-pos/backend/domain/user"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PayrollRepository interface {
+	Create(ctx context.Context, run *payroll.PayrollRun) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*payroll.PayrollRun, error)
+	Update(ctx context.Context, id primitive.ObjectID, run *payroll.PayrollRun) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	MarkPosted(ctx context.Context, id primitive.ObjectID, postedBy string, postedAt time.Time) error
+	ReopenDraft(ctx context.Context, id primitive.ObjectID) error
+	SetExpenseID(ctx context.Context, id, expenseID primitive.ObjectID) error
+	FindAll(ctx context.Context) ([]*payroll.PayrollRun, error)
+	FindPostedByUser(ctx context.Context, userID primitive.ObjectID) ([]*payroll.PayrollRun, error)
+}
+
+type PayrollDiscrepancyRepository interface {
+	FindByDateRange(ctx context.Context, start, end time.Time) ([]*handover.CashDiscrepancy, error)
+}
+
+// MyPayslip is a payslip of the current user with the period it belongs to
+type MyPayslip struct {
+	RunID    primitive.ObjectID `json:"run_id"`
+	From     time.Time          `json:"from"`
+	To       time.Time          `json:"to"`
+	PostedAt *time.Time         `json:"posted_at,omitempty"`
+	payroll.Payslip
+}
+
+// PayrollService computes pay from timesheets and posts it as salary expenses
+type PayrollService struct {
+	payrollRepo        PayrollRepository
+	timesheetService   *TimesheetService
+	userRepo           UserRepository
+	discrepancyRepo    PayrollDiscrepancyRepository
+	autoExpenseService *AutoExpenseService
+	rules              payroll.Rules
+}
+
+func NewPayrollService(
+	payrollRepo PayrollRepository,
+	timesheetService *TimesheetService,
+	userRepo UserRepository,
+	discrepancyRepo PayrollDiscrepancyRepository,
+	autoExpenseService *AutoExpenseService,
+	rules payroll.Rules,
+) *PayrollService {
+	return &PayrollService{
+		payrollRepo:        payrollRepo,
+		timesheetService:   timesheetService,
+		userRepo:           userRepo,
+		discrepancyRepo:    discrepancyRepo,
+		autoExpenseService: autoExpenseService,
+		rules:              rules,
+	}
+}
+
+// RunPayroll computes a draft payroll for the period: pay from the timesheets less the cash
+// shortages attributed to each employee in the period and the manual deductions requested
+func (s *PayrollService) RunPayroll(ctx context.Context, req *payroll.RunRequest, createdBy string) (*payroll.PayrollRun, error) {
+	from, to, err := req.Period(time.Local)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkNotPosted(ctx, from, to); err != nil {
+		return nil, err
+	}
+
+	ts, err := s.timesheetService.GetTimesheets(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	staff, err := s.userRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	users := make(map[primitive.ObjectID]*user.User, len(staff))
+	for _, u := range staff {
+		users[u.ID] = u
+	}
+
+	discrepancies, err := s.discrepancyRepo.FindByDateRange(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	deductions := payroll.ShortageDeductions(discrepancies)
+	now := time.Now()
+	for _, d := range req.Deductions {
+		userID, err := primitive.ObjectIDFromHex(d.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deduction user_id %q", d.UserID)
+		}
+		if _, ok := users[userID]; !ok {
+			return nil, fmt.Errorf("deduction user %s not found", d.UserID)
+		}
+		deductions[userID] = append(deductions[userID], payroll.Deduction{
+			Source: payroll.DeductionManual,
+			Reason: d.Reason,
+			Amount: d.Amount,
+			Date:   now,
+		})
+	}
+
+	run := payroll.NewRun(ts, users, deductions, s.rules)
+	run.CreatedBy = createdBy
+	if err := s.payrollRepo.Create(ctx, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// PostRun finalises a draft payroll run and records its net pay as a salary expense
+func (s *PayrollService) PostRun(ctx context.Context, id primitive.ObjectID, username string) (*payroll.PayrollRun, error) {
+	run, err := s.payrollRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("payroll run not found")
+	}
+	if run.Status != payroll.RunDraft {
+		return nil, payroll.ErrRunNotDraft
+	}
+	if missing := run.MissingRates(); len(missing) > 0 {
+		return nil, fmt.Errorf("set pay rates before posting: %s", strings.Join(missing, ", "))
+	}
+	if err := s.checkNotPosted(ctx, run.From, run.To); err != nil {
+		return nil, err
+	}
+
+	// Claim the draft first so two concurrent posts cannot both record an expense
+	now := time.Now()
+	if err := s.payrollRepo.MarkPosted(ctx, id, username, now); err != nil {
+		return nil, err
+	}
+	run.Status = payroll.RunPosted
+	run.PostedBy = username
+	run.PostedAt = &now
+
+	if s.autoExpenseService != nil {
+		expenseID, err := s.autoExpenseService.TrackPayroll(ctx, run, username)
+		if err != nil {
+			if reopenErr := s.payrollRepo.ReopenDraft(ctx, id); reopenErr != nil {
+				return nil, fmt.Errorf("%v (and the run could not be reopened: %v)", err, reopenErr)
+			}
+			return nil, err
+		}
+		if !expenseID.IsZero() {
+			run.ExpenseID = &expenseID
+			if err := s.payrollRepo.SetExpenseID(ctx, id, expenseID); err != nil {
+				return nil, fmt.Errorf("payroll run posted but not linked to expense %s: %w", expenseID.Hex(), err)
+			}
+		}
+	}
+	return run, nil
+}
+
+// DeleteRun removes a draft payroll run
+func (s *PayrollService) DeleteRun(ctx context.Context, id primitive.ObjectID) error {
+	run, err := s.payrollRepo.FindByID(ctx, id)
+	if err != nil {
+		return errors.New("payroll run not found")
+	}
+	if run.Status != payroll.RunDraft {
+		return errors.New("posted payroll runs cannot be deleted")
+	}
+	return s.payrollRepo.Delete(ctx, id)
+}
+
+func (s *PayrollService) GetRuns(ctx context.Context) ([]*payroll.PayrollRun, error) {
+	return s.payrollRepo.FindAll(ctx)
+}
+
+func (s *PayrollService) GetRun(ctx context.Context, id primitive.ObjectID) (*payroll.PayrollRun, error) {
+	return s.payrollRepo.FindByID(ctx, id)
+}
+
+// GetMyPayslips returns the posted payslips of a user, latest period first
+func (s *PayrollService) GetMyPayslips(ctx context.Context, userID primitive.ObjectID) ([]MyPayslip, error) {
+	runs, err := s.payrollRepo.FindPostedByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	slips := []MyPayslip{}
+	for _, run := range runs {
+		if slip, ok := run.Payslip(userID); ok {
+			slips = append(slips, MyPayslip{RunID: run.ID, From: run.From, To: run.To, PostedAt: run.PostedAt, Payslip: *slip})
+		}
+	}
+	return slips, nil
+}
+
+// checkNotPosted makes sure no day of the period has been paid by a posted run already
+func (s *PayrollService) checkNotPosted(ctx context.Context, from, to time.Time) error {
+	runs, err := s.payrollRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, run := range runs {
+		if run.Status == payroll.RunPosted && run.Overlaps(from, to) {
+			return fmt.Errorf("the period overlaps payroll %s - %s which has already been posted",
+				run.From.Format("2006-01-02"), run.To.Format("2006-01-02"))
+		}
+	}
+	return nil
+}
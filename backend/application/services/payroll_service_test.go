@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cafe-pos/backend/domain/payroll"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MockPayrollRepository keeps payroll runs in memory. FindByID returns a copy, like a read
+// from the database.
+type MockPayrollRepository struct {
+	runs map[primitive.ObjectID]*payroll.PayrollRun
+}
+
+func NewMockPayrollRepository() *MockPayrollRepository {
+	return &MockPayrollRepository{runs: make(map[primitive.ObjectID]*payroll.PayrollRun)}
+}
+
+func (m *MockPayrollRepository) Create(ctx context.Context, run *payroll.PayrollRun) error {
+	run.ID = primitive.NewObjectID()
+	stored := *run
+	m.runs[run.ID] = &stored
+	return nil
+}
+
+func (m *MockPayrollRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*payroll.PayrollRun, error) {
+	run, ok := m.runs[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	read := *run
+	return &read, nil
+}
+
+func (m *MockPayrollRepository) Update(ctx context.Context, id primitive.ObjectID, run *payroll.PayrollRun) error {
+	stored := *run
+	m.runs[id] = &stored
+	return nil
+}
+
+func (m *MockPayrollRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	delete(m.runs, id)
+	return nil
+}
+
+func (m *MockPayrollRepository) MarkPosted(ctx context.Context, id primitive.ObjectID, postedBy string, postedAt time.Time) error {
+	run, ok := m.runs[id]
+	if !ok || run.Status != payroll.RunDraft {
+		return payroll.ErrRunNotDraft
+	}
+	run.Status = payroll.RunPosted
+	run.PostedBy = postedBy
+	run.PostedAt = &postedAt
+	return nil
+}
+
+func (m *MockPayrollRepository) ReopenDraft(ctx context.Context, id primitive.ObjectID) error {
+	if run, ok := m.runs[id]; ok {
+		run.Status = payroll.RunDraft
+		run.PostedBy = ""
+		run.PostedAt = nil
+	}
+	return nil
+}
+
+func (m *MockPayrollRepository) SetExpenseID(ctx context.Context, id, expenseID primitive.ObjectID) error {
+	if run, ok := m.runs[id]; ok {
+		run.ExpenseID = &expenseID
+	}
+	return nil
+}
+
+func (m *MockPayrollRepository) FindAll(ctx context.Context) ([]*payroll.PayrollRun, error) {
+	return []*payroll.PayrollRun{}, nil
+}
+
+func (m *MockPayrollRepository) FindPostedByUser(ctx context.Context, userID primitive.ObjectID) ([]*payroll.PayrollRun, error) {
+	return []*payroll.PayrollRun{}, nil
+}
+
+// TestPostRun_OnlyOnce tests that a posted run cannot be posted again
+func TestPostRun_OnlyOnce(t *testing.T) {
+	repo := NewMockPayrollRepository()
+	service := NewPayrollService(repo, nil, nil, nil, nil, payroll.Rules{})
+
+	run := &payroll.PayrollRun{
+		From:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:     time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+		Status: payroll.RunDraft,
+	}
+	repo.Create(context.Background(), run)
+
+	posted, err := service.PostRun(context.Background(), run.ID, "manager")
+	if err != nil {
+		t.Fatalf("Expected the draft to be posted, got %v", err)
+	}
+	if posted.Status != payroll.RunPosted || posted.PostedBy != "manager" {
+		t.Errorf("Expected the run posted by manager, got %s by %q", posted.Status, posted.PostedBy)
+	}
+
+	if _, err := service.PostRun(context.Background(), run.ID, "other"); !errors.Is(err, payroll.ErrRunNotDraft) {
+		t.Errorf("Expected ErrRunNotDraft posting twice, got %v", err)
+	}
+	if got := repo.runs[run.ID].PostedBy; got != "manager" {
+		t.Errorf("Expected the run to stay posted by manager, got %q", got)
+	}
+}
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cafe-pos/backend/domain/ingredient"
+	"cafe-pos/backend/domain/purchasing"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PurchaseOrderRepository interface {
+	Create(ctx context.Context, po *purchasing.PurchaseOrder) error
+	FindAll(ctx context.Context, status purchasing.Status) ([]*purchasing.PurchaseOrder, error)
+	FindByID(ctx context.Context, id primitive.ObjectID) (*purchasing.PurchaseOrder, error)
+	Update(ctx context.Context, id primitive.ObjectID, po *purchasing.PurchaseOrder) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+// PurchaseOrderService manages purchase orders to suppliers and receiving of the goods
+type PurchaseOrderService struct {
+	poRepo             PurchaseOrderRepository
+	ingredientRepo     IngredientRepository
+	ingredientService  *IngredientService
+	autoExpenseService *AutoExpenseService
+	supplierService    *SupplierService
+}
+
+func NewPurchaseOrderService(poRepo PurchaseOrderRepository, ingredientRepo IngredientRepository, ingredientService *IngredientService) *PurchaseOrderService {
+	return &PurchaseOrderService{
+		poRepo:            poRepo,
+		ingredientRepo:    ingredientRepo,
+		ingredientService: ingredientService,
+	}
+}
+
+// SetAutoExpenseService sets the AutoExpenseService so received goods are recorded as expenses
+// This is called after service initialization to avoid circular dependencies
+func (s *PurchaseOrderService) SetAutoExpenseService(autoExpenseService *AutoExpenseService) {
+	s.autoExpenseService = autoExpenseService
+}
+
+// SetSupplierService sets the SupplierService so orders reference supplier entities and
+// expected prices default to the supplier's price list
+func (s *PurchaseOrderService) SetSupplierService(supplierService *SupplierService) {
+	s.supplierService = supplierService
+}
+
+func (s *PurchaseOrderService) CreatePurchaseOrder(ctx context.Context, req *purchasing.PurchaseOrderRequest, username string) (*purchasing.PurchaseOrder, error) {
+	now := time.Now()
+	po := &purchasing.PurchaseOrder{
+		PONumber:  fmt.Sprintf("PO-%s-%03d", now.Format("20060102-150405"), now.Nanosecond()/1000000%1000),
+		Status:    purchasing.StatusDraft,
+		Receipts:  []purchasing.Receipt{},
+		CreatedBy: username,
+	}
+	if err := s.applyRequest(ctx, po, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.poRepo.Create(ctx, po); err != nil {
+		return nil, err
+	}
+	return po, nil
+}
+
+func (s *PurchaseOrderService) GetPurchaseOrders(ctx context.Context, status purchasing.Status) ([]*purchasing.PurchaseOrder, error) {
+	return s.poRepo.FindAll(ctx, status)
+}
+
+func (s *PurchaseOrderService) GetPurchaseOrder(ctx context.Context, id primitive.ObjectID) (*purchasing.PurchaseOrder, error) {
+	return s.poRepo.FindByID(ctx, id)
+}
+
+// UpdatePurchaseOrder replaces the supplier and lines of a draft purchase order
+func (s *PurchaseOrderService) UpdatePurchaseOrder(ctx context.Context, id primitive.ObjectID, req *purchasing.PurchaseOrderRequest) (*purchasing.PurchaseOrder, error) {
+	po, err := s.poRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !po.CanEdit() {
+		return nil, errors.New("only draft purchase orders can be edited")
+	}
+	if err := s.applyRequest(ctx, po, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.poRepo.Update(ctx, id, po); err != nil {
+		return nil, err
+	}
+	return po, nil
+}
+
+// DeletePurchaseOrder deletes a draft purchase order
+func (s *PurchaseOrderService) DeletePurchaseOrder(ctx context.Context, id primitive.ObjectID) error {
+	po, err := s.poRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !po.CanEdit() {
+		return errors.New("only draft purchase orders can be deleted, cancel it instead")
+	}
+	return s.poRepo.Delete(ctx, id)
+}
+
+// SendPurchaseOrder marks the purchase order as sent to the supplier. Sending again is allowed
+// to record a re-send.
+func (s *PurchaseOrderService) SendPurchaseOrder(ctx context.Context, id primitive.ObjectID, username string) (*purchasing.PurchaseOrder, error) {
+	po, err := s.poRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !po.CanSend() {
+		return nil, fmt.Errorf("cannot send a purchase order with status %s", po.Status)
+	}
+
+	now := time.Now()
+	po.Status = purchasing.StatusSent
+	po.SentAt = &now
+	po.SentBy = username
+
+	if err := s.poRepo.Update(ctx, id, po); err != nil {
+		return nil, err
+	}
+	return po, nil
+}
+
+// CancelPurchaseOrder cancels a purchase order nothing has been received for
+func (s *PurchaseOrderService) CancelPurchaseOrder(ctx context.Context, id primitive.ObjectID) (*purchasing.PurchaseOrder, error) {
+	po, err := s.poRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !po.CanCancel() {
+		return nil, fmt.Errorf("cannot cancel a purchase order with status %s", po.Status)
+	}
+
+	now := time.Now()
+	po.Status = purchasing.StatusCancelled
+	po.CancelledAt = &now
+
+	if err := s.poRepo.Update(ctx, id, po); err != nil {
+		return nil, err
+	}
+	return po, nil
+}
+
+// ReceivePurchaseOrder records a full or partial delivery: stock is increased for each line with
+// purchase stock history, ingredient costs are updated to the actual prices and one expense is
+// created for the delivery. The receipt and the stock of all its lines are written in one
+// transaction where the database supports transactions.
+func (s *PurchaseOrderService) ReceivePurchaseOrder(ctx context.Context, id primitive.ObjectID, req *purchasing.ReceiveRequest, userID, username string) (*purchasing.PurchaseOrder, error) {
+	now := time.Now()
+	var po *purchasing.PurchaseOrder
+	var receipt *purchasing.Receipt
+	err := s.ingredientService.InTransaction(ctx, func(ctx context.Context) error {
+		// Read in the transaction so a retry starts from the order as saved
+		var err error
+		if po, err = s.poRepo.FindByID(ctx, id); err != nil {
+			return err
+		}
+		if receipt, err = po.NewReceipt(req, username, now); err != nil {
+			return err
+		}
+
+		// Check every unit converts before touching stock
+		for _, rl := range receipt.Lines {
+			ing, err := s.ingredientRepo.FindByID(ctx, rl.IngredientID)
+			if err != nil {
+				return fmt.Errorf("ingredient %s not found", rl.IngredientName)
+			}
+			if _, err := ing.ToStockUnit(rl.Quantity, string(rl.Unit)); err != nil {
+				return err
+			}
+		}
+
+		// The receipt is saved before the stock is added: the update only succeeds on the
+		// version read above, so a second submit of the same delivery fails here with
+		// purchasing.ErrConcurrentUpdate instead of adding the stock twice
+		po.ApplyReceipt(receipt, req.Close)
+		if err := s.poRepo.Update(ctx, id, po); err != nil {
+			return err
+		}
+
+		reason := fmt.Sprintf("Nhập hàng theo đơn %s", po.PONumber)
+		for _, rl := range receipt.Lines {
+			stock := &ingredient.StockReceipt{
+				Quantity:        rl.Quantity,
+				Unit:            string(rl.Unit),
+				UnitPrice:       rl.UnitPrice,
+				LotNumber:       rl.LotNumber,
+				ExpiryDate:      rl.ExpiryDate,
+				PurchaseOrderID: &po.ID,
+				Reason:          reason,
+				UserID:          userID,
+				Username:        username,
+			}
+			if _, err := s.ingredientService.AddReceivedStock(ctx, rl.IngredientID, stock); err != nil {
+				return fmt.Errorf("%s: %w", rl.IngredientName, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.ingredientService.RefreshAfterStockChange(ctx)
+
+	if s.autoExpenseService != nil {
+		// Expense tracking failures are logged by AutoExpenseService, the stock is already received
+		if expenseID, err := s.autoExpenseService.TrackPurchaseOrderReceipt(ctx, po, receipt, username); err == nil && !expenseID.IsZero() {
+			po.Receipts[len(po.Receipts)-1].ExpenseID = expenseID
+			if err := s.poRepo.Update(ctx, id, po); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return po, nil
+}
+
+// applyRequest validates the lines against inventory and copies the request onto the order
+func (s *PurchaseOrderService) applyRequest(ctx context.Context, po *purchasing.PurchaseOrder, req *purchasing.PurchaseOrderRequest) error {
+	supplierID, err := optionalObjectID(req.SupplierID)
+	if err != nil {
+		return err
+	}
+	supplierName := req.Supplier
+	if s.supplierService != nil {
+		if supplierID, supplierName, err = s.supplierService.Link(ctx, supplierID, supplierName); err != nil {
+			return err
+		}
+	}
+	if supplierName == "" {
+		return errors.New("supplier is required")
+	}
+
+	lines := make([]purchasing.Line, 0, len(req.Lines))
+	seen := make(map[primitive.ObjectID]bool)
+	for _, l := range req.Lines {
+		ingID, err := primitive.ObjectIDFromHex(l.IngredientID)
+		if err != nil {
+			return fmt.Errorf("invalid ingredient id %q", l.IngredientID)
+		}
+		if seen[ingID] {
+			return fmt.Errorf("ingredient %s appears twice", l.IngredientID)
+		}
+		seen[ingID] = true
+
+		ing, err := s.ingredientRepo.FindByID(ctx, ingID)
+		if err != nil {
+			return fmt.Errorf("ingredient %s not found", l.IngredientID)
+		}
+		unit := ing.Unit
+		if l.Unit != "" {
+			if _, err := ing.ToStockUnit(l.Quantity, string(l.Unit)); err != nil {
+				return err
+			}
+			unit = ingredient.NormalizeUnit(string(l.Unit))
+		}
+
+		price := l.ExpectedPrice
+		if price == 0 && supplierID != nil && s.supplierService != nil {
+			if listed, ok := s.supplierService.CurrentPrice(ctx, *supplierID, ing, unit); ok {
+				price = listed
+			}
+		}
+
+		lines = append(lines, purchasing.Line{
+			IngredientID:   ingID,
+			IngredientName: ing.Name,
+			Unit:           unit,
+			Quantity:       l.Quantity,
+			ExpectedPrice:  price,
+		})
+	}
+
+	po.Supplier = supplierName
+	po.SupplierID = supplierID
+	po.ExpectedDate = req.ExpectedDate
+	po.Notes = req.Notes
+	po.Lines = lines
+	po.CalculateTotals()
+	return nil
+}
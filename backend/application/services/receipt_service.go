@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+
+	"cafe-pos/backend/domain/menu"
+	"cafe-pos/backend/domain/order"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReceiptService renders order receipts in Vietnamese and the customer's language
+type ReceiptService struct {
+	orderRepo OrderRepository
+	menuRepo  MenuRepository
+}
+
+func NewReceiptService(orderRepo OrderRepository, menuRepo MenuRepository) *ReceiptService {
+	return &ReceiptService{
+		orderRepo: orderRepo,
+		menuRepo:  menuRepo,
+	}
+}
+
+// GetReceipt builds the receipt of an order. The first preferred language is printed
+// next to Vietnamese; if it is Vietnamese or empty the receipt is Vietnamese only.
+func (s *ReceiptService) GetReceipt(ctx context.Context, id primitive.ObjectID, langs []string) (*order.Receipt, error) {
+	o, err := s.orderRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	lang := menu.DefaultLanguage
+	if len(langs) > 0 {
+		lang = langs[0]
+	}
+
+	items := make(map[primitive.ObjectID]*menu.MenuItem)
+	translate := func(item order.OrderItem) string {
+		m, ok := items[item.MenuItemID]
+		if !ok {
+			m, _ = s.menuRepo.FindByID(ctx, item.MenuItemID)
+			items[item.MenuItemID] = m
+		}
+		if m == nil {
+			return ""
+		}
+		return m.LocalizedName(lang)
+	}
+
+	return order.NewReceipt(o, lang, translate), nil
+}
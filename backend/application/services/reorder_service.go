@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"cafe-pos/backend/domain/ingredient"
+	"cafe-pos/backend/domain/purchasing"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReorderSuggestions is the stock forecast of all ingredients and the purchase orders
+// suggested per supplier
+type ReorderSuggestions struct {
+	LookbackDays int                              `json:"lookback_days"`
+	SafetyDays   int                              `json:"safety_days"`
+	ReviewDays   int                              `json:"review_days"`
+	Suppliers    []*purchasing.SupplierSuggestion `json:"suppliers"`
+	// Ingredients lists every ingredient, fewest days of cover first
+	Ingredients    []*purchasing.ReorderLine `json:"ingredients"`
+	EstimatedTotal float64                   `json:"estimated_total"`
+	GeneratedAt    time.Time                 `json:"generated_at"`
+}
+
+// ReorderService forecasts ingredient consumption from stock history and suggests what to
+// order from each supplier before stock runs out
+type ReorderService struct {
+	ingredientRepo   IngredientRepository
+	stockHistoryRepo StockHistoryRepository
+	poRepo           PurchaseOrderRepository
+	supplierService  *SupplierService
+}
+
+func NewReorderService(ingredientRepo IngredientRepository, stockHistoryRepo StockHistoryRepository, poRepo PurchaseOrderRepository) *ReorderService {
+	return &ReorderService{
+		ingredientRepo:   ingredientRepo,
+		stockHistoryRepo: stockHistoryRepo,
+		poRepo:           poRepo,
+	}
+}
+
+// SetSupplierService sets the SupplierService so suggestions use supplier lead times and
+// price list units
+func (s *ReorderService) SetSupplierService(supplierService *SupplierService) {
+	s.supplierService = supplierService
+}
+
+// GetReorderSuggestions forecasts stock of every ingredient from consumption over the lookback
+// period by day of the week and groups the quantities to order by supplier
+func (s *ReorderService) GetReorderSuggestions(ctx context.Context, params purchasing.ReorderParams) (*ReorderSuggestions, error) {
+	params = params.WithDefaults()
+	now := time.Now()
+
+	ingredients, err := s.ingredientRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[primitive.ObjectID]*ingredient.Ingredient, len(ingredients))
+	for _, ing := range ingredients {
+		byID[ing.ID] = ing
+	}
+
+	from, to := purchasing.ConsumptionWindow(now, params.LookbackDays)
+	history, err := s.stockHistoryRepo.FindByPeriod(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	usage := purchasing.UsageByWeekday(history, from, to)
+
+	var open []*purchasing.PurchaseOrder
+	for _, status := range []purchasing.Status{purchasing.StatusSent, purchasing.StatusPartiallyReceived} {
+		orders, err := s.poRepo.FindAll(ctx, status)
+		if err != nil {
+			return nil, err
+		}
+		open = append(open, orders...)
+	}
+	onOrder := purchasing.OnOrder(open, byID)
+
+	leadTimes := make(map[primitive.ObjectID]int)
+	lines := make([]*purchasing.ReorderLine, 0, len(ingredients))
+	for _, ing := range ingredients {
+		leadTime := 0
+		if ing.SupplierID != nil && s.supplierService != nil {
+			leadTime = s.supplierLeadTime(ctx, *ing.SupplierID, leadTimes)
+		}
+
+		line := purchasing.PlanReorder(ing, usage[ing.ID], onOrder[ing.ID], leadTime, params, now)
+		if line.NeedsReorder && ing.SupplierID != nil && s.supplierService != nil {
+			s.applyPriceList(ctx, line, ing)
+		}
+		lines = append(lines, line)
+	}
+	purchasing.SortByCover(lines)
+
+	result := &ReorderSuggestions{
+		LookbackDays: params.LookbackDays,
+		SafetyDays:   params.SafetyDays,
+		ReviewDays:   params.ReviewDays,
+		Suppliers:    purchasing.GroupBySupplier(lines),
+		Ingredients:  lines,
+		GeneratedAt:  now,
+	}
+	for _, g := range result.Suppliers {
+		result.EstimatedTotal += g.EstimatedTotal
+	}
+	return result, nil
+}
+
+// supplierLeadTime returns the lead time of a supplier, caching lookups
+func (s *ReorderService) supplierLeadTime(ctx context.Context, supplierID primitive.ObjectID, cache map[primitive.ObjectID]int) int {
+	if days, ok := cache[supplierID]; ok {
+		return days
+	}
+	days := 0
+	if sup, err := s.supplierService.GetSupplier(ctx, supplierID); err == nil {
+		days = sup.LeadTimeDays
+	}
+	cache[supplierID] = days
+	return days
+}
+
+// applyPriceList expresses the order in the unit and price of the supplier's current price
+// list. Without a valid price the order stays in the stock unit at the current cost.
+func (s *ReorderService) applyPriceList(ctx context.Context, line *purchasing.ReorderLine, ing *ingredient.Ingredient) {
+	entry, ok := s.supplierService.CurrentPriceEntry(ctx, *ing.SupplierID, ing.ID)
+	if !ok {
+		return
+	}
+	stockPerUnit, err := ing.ToStockUnit(1, string(entry.Unit))
+	if err != nil {
+		return
+	}
+	line.SetOrderUnit(entry.Unit, stockPerUnit, entry.Price, entry.MinOrderQty)
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cafe-pos/backend/domain/order"
+	"cafe-pos/backend/domain/roster"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PlannedShiftRepository interface {
+	Create(ctx context.Context, p *roster.PlannedShift) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*roster.PlannedShift, error)
+	Update(ctx context.Context, id primitive.ObjectID, p *roster.PlannedShift) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	FindByDateRange(ctx context.Context, from, to time.Time) ([]*roster.PlannedShift, error)
+	FindByUser(ctx context.Context, userID primitive.ObjectID, from, to time.Time) ([]*roster.PlannedShift, error)
+}
+
+// RosterService plans shifts for staff and compares them with the shifts actually worked
+type RosterService struct {
+	plannedRepo      PlannedShiftRepository
+	shiftRepo        ShiftRepository
+	userRepo         UserRepository
+	shiftTypeService *ShiftTypeService
+}
+
+func NewRosterService(plannedRepo PlannedShiftRepository, shiftRepo ShiftRepository, userRepo UserRepository) *RosterService {
+	return &RosterService{
+		plannedRepo: plannedRepo,
+		shiftRepo:   shiftRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// SetShiftTypeService checks and derives the shift types of planned shifts with the store's
+// configured shift types
+func (s *RosterService) SetShiftTypeService(shiftTypeService *ShiftTypeService) {
+	s.shiftTypeService = shiftTypeService
+}
+
+// PlanShifts adds a shift to the roster, repeated weekly if requested. Nothing is planned if
+// any of the weeks overlaps a shift already planned for the staff member.
+func (s *RosterService) PlanShifts(ctx context.Context, req *roster.PlannedShiftRequest, createdBy string) ([]*roster.PlannedShift, error) {
+	first := &roster.PlannedShift{Status: roster.PlannedScheduled, CreatedBy: createdBy}
+	if err := s.applyPlannedShiftRequest(ctx, first, req); err != nil {
+		return nil, err
+	}
+
+	planned := []*roster.PlannedShift{first}
+	for week := 1; week <= req.RepeatWeeks; week++ {
+		p := *first
+		p.StartsAt = first.StartsAt.AddDate(0, 0, 7*week)
+		p.EndsAt = first.EndsAt.AddDate(0, 0, 7*week)
+		planned = append(planned, &p)
+	}
+	for _, p := range planned {
+		if err := s.checkOverlap(ctx, p); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, p := range planned {
+		if err := s.plannedRepo.Create(ctx, p); err != nil {
+			return nil, err
+		}
+	}
+	return planned, nil
+}
+
+// UpdatePlannedShift changes a planned shift that has not been started yet
+func (s *RosterService) UpdatePlannedShift(ctx context.Context, id primitive.ObjectID, req *roster.PlannedShiftRequest) (*roster.PlannedShift, error) {
+	p, err := s.plannedRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("planned shift not found")
+	}
+	if err := p.CanChange(); err != nil {
+		return nil, err
+	}
+	if err := s.applyPlannedShiftRequest(ctx, p, req); err != nil {
+		return nil, err
+	}
+	if err := s.checkOverlap(ctx, p); err != nil {
+		return nil, err
+	}
+
+	if err := s.plannedRepo.Update(ctx, id, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// DeletePlannedShift removes a planned shift that has not been started yet
+func (s *RosterService) DeletePlannedShift(ctx context.Context, id primitive.ObjectID) error {
+	p, err := s.plannedRepo.FindByID(ctx, id)
+	if err != nil {
+		return errors.New("planned shift not found")
+	}
+	if err := p.CanChange(); err != nil {
+		return err
+	}
+	return s.plannedRepo.Delete(ctx, id)
+}
+
+// GetRoster returns the planned shifts between from and to, optionally for one staff member
+func (s *RosterService) GetRoster(ctx context.Context, from, to time.Time, userID *primitive.ObjectID) ([]*roster.PlannedShift, error) {
+	if userID != nil {
+		return s.plannedRepo.FindByUser(ctx, *userID, from, to)
+	}
+	return s.plannedRepo.FindByDateRange(ctx, from, to)
+}
+
+// GetAttendanceReport compares the shifts planned between from and to with the shifts
+// started for them, reporting no-shows, late starts and early leaves beyond grace
+func (s *RosterService) GetAttendanceReport(ctx context.Context, from, to time.Time, grace time.Duration) (*roster.AttendanceReport, error) {
+	planned, err := s.plannedRepo.FindByDateRange(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	// Planned shifts may be started up to EarlyStartWindow before they begin
+	worked, err := s.shiftRepo.FindByDateRange(ctx, from.Add(-roster.EarlyStartWindow), to)
+	if err != nil {
+		return nil, err
+	}
+	shifts := make(map[primitive.ObjectID]*order.Shift, len(worked))
+	for _, shift := range worked {
+		shifts[shift.ID] = shift
+	}
+	return roster.BuildAttendanceReport(planned, shifts, grace, from, to, time.Now()), nil
+}
+
+func (s *RosterService) applyPlannedShiftRequest(ctx context.Context, p *roster.PlannedShift, req *roster.PlannedShiftRequest) error {
+	userID, err := primitive.ObjectIDFromHex(req.UserID)
+	if err != nil {
+		return errors.New("invalid user_id")
+	}
+	staff, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if !staff.Active {
+		return fmt.Errorf("user %s is not active", staff.Username)
+	}
+
+	role := req.RoleType
+	if role == "" {
+		role = order.RoleType(staff.Role)
+	}
+	if !role.IsValid() {
+		return errors.New("invalid role type: must be waiter or barista")
+	}
+	startsAt, endsAt, err := req.Schedule(time.Local)
+	if err != nil {
+		return err
+	}
+	shiftType, err := resolveShiftType(ctx, s.shiftTypeService, req.Type, startsAt)
+	if err != nil {
+		return err
+	}
+
+	userName := staff.Name
+	if userName == "" {
+		userName = staff.Username
+	}
+	p.UserID = userID
+	p.UserName = userName
+	p.RoleType = role
+	p.Type = shiftType
+	p.StartsAt = startsAt
+	p.EndsAt = endsAt
+	p.Notes = req.Notes
+	return nil
+}
+
+func (s *RosterService) checkOverlap(ctx context.Context, p *roster.PlannedShift) error {
+	existing, err := s.plannedRepo.FindByUser(ctx, p.UserID, p.StartsAt, p.EndsAt)
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if other.ID != p.ID && p.Overlaps(other) {
+			return fmt.Errorf("%s already has a shift planned from %s to %s",
+				p.UserName, other.StartsAt.Format("2006-01-02 15:04"), other.EndsAt.Format("15:04"))
+		}
+	}
+	return nil
+}
@@ -3,9 +3,11 @@ package services
 import (
 	"context"
 	"errors"
+	"log"
 	"time"
 	"cafe-pos/backend/domain"
 	"cafe-pos/backend/domain/order"
+	"cafe-pos/backend/domain/roster"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -27,6 +29,8 @@ type ShiftService struct {
 	shiftRepo           ShiftRepository
 	orderRepo           OrderRepository
 	stateMachineManager *domain.StateMachineManager
+	plannedRepo         PlannedShiftRepository
+	shiftTypeService    *ShiftTypeService
 }
 
 func NewShiftService(
@@ -41,6 +45,16 @@ func NewShiftService(
 	}
 }
 
+// SetPlannedShiftRepository links started shifts to the matching shift on the roster
+func (s *ShiftService) SetPlannedShiftRepository(plannedRepo PlannedShiftRepository) {
+	s.plannedRepo = plannedRepo
+}
+
+// SetShiftTypeService checks and derives shift types with the store's configured shift types
+func (s *ShiftService) SetShiftTypeService(shiftTypeService *ShiftTypeService) {
+	s.shiftTypeService = shiftTypeService
+}
+
 func (s *ShiftService) StartShift(ctx context.Context, req *order.StartShiftRequest, userID, userName string, roleType order.RoleType) (*order.Shift, error) {
 	// Reject cashier role - cashier shifts are handled separately
 	if roleType == "cashier" {
@@ -72,12 +86,49 @@ func (s *ShiftService) StartShift(ctx context.Context, req *order.StartShiftRequ
 		StartedAt:  time.Now(),
 	}
 
+	planned := s.findPlannedShift(ctx, shift)
+	if planned != nil {
+		shift.PlannedShiftID = &planned.ID
+		if shift.Type == "" {
+			shift.Type = planned.Type
+		}
+	}
+
+	shiftType, err := resolveShiftType(ctx, s.shiftTypeService, shift.Type, shift.StartedAt)
+	if err != nil {
+		return nil, err
+	}
+	shift.Type = shiftType
+
 	if err := s.shiftRepo.Create(ctx, shift); err != nil {
 		return nil, err
 	}
+
+	if planned != nil {
+		planned.Status = roster.PlannedStarted
+		planned.ShiftID = &shift.ID
+		// The shift is already open and carries PlannedShiftID, so a failed roster update
+		// only leaves the planned shift showing as not started
+		if err := s.plannedRepo.Update(ctx, planned.ID, planned); err != nil {
+			log.Printf("[Shifts] Failed to link planned shift %s to shift %s: %v", planned.ID.Hex(), shift.ID.Hex(), err)
+		}
+	}
 	return shift, nil
 }
 
+// findPlannedShift returns the roster shift the user is starting, if one is planned
+func (s *ShiftService) findPlannedShift(ctx context.Context, shift *order.Shift) *roster.PlannedShift {
+	if s.plannedRepo == nil {
+		return nil
+	}
+	at := shift.StartedAt
+	planned, err := s.plannedRepo.FindByUser(ctx, shift.UserID, at, at.Add(roster.EarlyStartWindow))
+	if err != nil {
+		return nil
+	}
+	return roster.MatchPlannedShift(planned, shift.RoleType, at)
+}
+
 func (s *ShiftService) EndShift(ctx context.Context, shiftID primitive.ObjectID, req *order.EndShiftRequest) (*order.Shift, error) {
 	shift, err := s.shiftRepo.FindByID(ctx, shiftID)
 	if err != nil {
@@ -89,10 +140,18 @@ func (s *ShiftService) EndShift(ctx context.Context, shiftID primitive.ObjectID,
 		return nil, err
 	}
 
-	orders, err := s.orderRepo.FindByShiftID(ctx, shiftID)
-	if err != nil {
+	if err := s.closeShift(ctx, shift, req.EndCash, time.Now()); err != nil {
 		return nil, err
 	}
+	return shift, nil
+}
+
+// closeShift totals the orders of the shift and closes it at endedAt
+func (s *ShiftService) closeShift(ctx context.Context, shift *order.Shift, endCash float64, endedAt time.Time) error {
+	orders, err := s.orderRepo.FindByShiftID(ctx, shift.ID)
+	if err != nil {
+		return err
+	}
 
 	totalRevenue := 0.0
 	for _, o := range orders {
@@ -101,13 +160,92 @@ func (s *ShiftService) EndShift(ctx context.Context, shiftID primitive.ObjectID,
 		}
 	}
 
-	now := time.Now()
+	// A break still running ends with the shift
+	if shift.OnBreak() {
+		shift.EndBreak(endedAt)
+	}
 	shift.Status = order.ShiftClosed
-	shift.EndCash = req.EndCash
+	shift.EndCash = endCash
 	shift.TotalRevenue = totalRevenue
 	shift.TotalOrders = len(orders)
-	shift.EndedAt = &now
+	shift.EndedAt = &endedAt
+
+	return s.shiftRepo.Update(ctx, shift.ID, shift)
+}
+
+// StartBreak starts a paid or unpaid break on the user's open shift
+func (s *ShiftService) StartBreak(ctx context.Context, shiftID, userID primitive.ObjectID, req *order.StartBreakRequest) (*order.Shift, error) {
+	shift, err := s.findOwnShift(ctx, shiftID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := shift.StartBreak(req.Type, req.Notes, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.shiftRepo.Update(ctx, shiftID, shift); err != nil {
+		return nil, err
+	}
+	return shift, nil
+}
+
+// EndBreak ends the break in progress on the user's shift
+func (s *ShiftService) EndBreak(ctx context.Context, shiftID, userID primitive.ObjectID) (*order.Shift, error) {
+	shift, err := s.findOwnShift(ctx, shiftID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := shift.EndBreak(time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.shiftRepo.Update(ctx, shiftID, shift); err != nil {
+		return nil, err
+	}
+	return shift, nil
+}
+
+func (s *ShiftService) findOwnShift(ctx context.Context, shiftID, userID primitive.ObjectID) (*order.Shift, error) {
+	shift, err := s.shiftRepo.FindByID(ctx, shiftID)
+	if err != nil {
+		return nil, errors.New("shift not found")
+	}
+	if shift.UserID != userID {
+		return nil, errors.New("shift belongs to another user")
+	}
+	return shift, nil
+}
+
+// ForceEndShift closes a shift the staff member forgot to end, as of endedAt. The end cash
+// is unknown, so the shift is marked for reconciliation.
+func (s *ShiftService) ForceEndShift(ctx context.Context, shiftID primitive.ObjectID, endedAt time.Time) (*order.Shift, error) {
+	shift, err := s.shiftRepo.FindByID(ctx, shiftID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.stateMachineManager.ValidateWaiterShiftTransition(shift, order.EventEndShift); err != nil {
+		return nil, err
+	}
+
+	shift.ForceEnded = true
+	shift.ReconciliationRequired = true
+	if err := s.closeShift(ctx, shift, 0, endedAt); err != nil {
+		return nil, err
+	}
+	return shift, nil
+}
+
+// ReconcileShift sets the actual end time and cash of a force-ended shift
+func (s *ShiftService) ReconcileShift(ctx context.Context, shiftID primitive.ObjectID, endedAt time.Time, endCash float64) (*order.Shift, error) {
+	shift, err := s.shiftRepo.FindByID(ctx, shiftID)
+	if err != nil {
+		return nil, err
+	}
+	if !shift.ReconciliationRequired {
+		return nil, errors.New("shift does not need reconciliation")
+	}
 
+	shift.ReconciliationRequired = false
+	shift.EndCash = endCash
+	shift.EndedAt = &endedAt
 	if err := s.shiftRepo.Update(ctx, shiftID, shift); err != nil {
 		return nil, err
 	}
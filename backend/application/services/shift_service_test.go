@@ -3,9 +3,11 @@ package services
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
+	"cafe-pos/backend/domain"
 	"cafe-pos/backend/domain/order"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -64,7 +66,7 @@ func (m *MockShiftRepository) FindOpenShiftByWaiter(ctx context.Context, waiterI
 		return nil, m.findError
 	}
 	for _, shift := range m.shifts {
-		if shift.WaiterID == waiterID && shift.Status == order.ShiftOpen {
+		if shift.UserID == waiterID && shift.RoleType == order.RoleWaiter && shift.Status == order.ShiftOpen {
 			return shift, nil
 		}
 	}
@@ -101,7 +103,7 @@ func (m *MockShiftRepository) FindByWaiterID(ctx context.Context, waiterID primi
 	}
 	var shifts []*order.Shift
 	for _, shift := range m.shifts {
-		if shift.WaiterID == waiterID {
+		if shift.UserID == waiterID && shift.RoleType == order.RoleWaiter {
 			shifts = append(shifts, shift)
 		}
 	}
@@ -197,11 +199,15 @@ func (m *MockOrderRepository) FindByDateRange(ctx context.Context, startDate, en
 	return []*order.Order{}, nil
 }
 
+func (m *MockOrderRepository) OverrideTokenUsed(ctx context.Context, tokenID string, exceptID primitive.ObjectID) (bool, error) {
+	return false, nil
+}
+
 // Tests
 func TestStartShift_WaiterRole(t *testing.T) {
 	mockShiftRepo := NewMockShiftRepository()
 	mockOrderRepo := &MockOrderRepository{}
-	service := NewShiftService(mockShiftRepo, mockOrderRepo)
+	service := NewShiftService(mockShiftRepo, mockOrderRepo, domain.NewStateMachineManager())
 
 	userID := primitive.NewObjectID()
 	req := &order.StartShiftRequest{
@@ -235,20 +241,12 @@ func TestStartShift_WaiterRole(t *testing.T) {
 		t.Errorf("Expected start_cash to be 1000000, got %f", shift.StartCash)
 	}
 
-	// Check legacy fields
-	if shift.WaiterID != userID {
-		t.Errorf("Expected waiter_id to be set for backward compatibility")
-	}
-
-	if shift.WaiterName != "Waiter 1" {
-		t.Errorf("Expected waiter_name to be set for backward compatibility")
-	}
 }
 
 func TestStartShift_BaristaRole(t *testing.T) {
 	mockShiftRepo := NewMockShiftRepository()
 	mockOrderRepo := &MockOrderRepository{}
-	service := NewShiftService(mockShiftRepo, mockOrderRepo)
+	service := NewShiftService(mockShiftRepo, mockOrderRepo, domain.NewStateMachineManager())
 
 	userID := primitive.NewObjectID()
 	req := &order.StartShiftRequest{
@@ -274,47 +272,12 @@ func TestStartShift_BaristaRole(t *testing.T) {
 		t.Errorf("Expected user_name to be 'Barista 1', got %s", shift.UserName)
 	}
 
-	// Barista should not have waiter_id set
-	if shift.WaiterID != primitive.NilObjectID {
-		t.Errorf("Expected waiter_id to be nil for barista role")
-	}
-}
-
-func TestStartShift_CashierRole(t *testing.T) {
-	mockShiftRepo := NewMockShiftRepository()
-	mockOrderRepo := &MockOrderRepository{}
-	service := NewShiftService(mockShiftRepo, mockOrderRepo)
-
-	userID := primitive.NewObjectID()
-	req := &order.StartShiftRequest{
-		Type:      order.ShiftAfternoon,
-		StartCash: 2000000,
-	}
-
-	shift, err := service.StartShift(context.Background(), req, userID.Hex(), "Cashier 1", order.RoleCashier)
-
-	if err != nil {
-		t.Fatalf("Expected no error, got %v", err)
-	}
-
-	if shift.RoleType != order.RoleCashier {
-		t.Errorf("Expected role_type to be 'cashier', got %s", shift.RoleType)
-	}
-
-	// Check legacy fields for cashier
-	if shift.CashierID != userID {
-		t.Errorf("Expected cashier_id to be set for backward compatibility")
-	}
-
-	if shift.CashierName != "Cashier 1" {
-		t.Errorf("Expected cashier_name to be set for backward compatibility")
-	}
 }
 
 func TestStartShift_DuplicateShiftSameRole(t *testing.T) {
 	mockShiftRepo := NewMockShiftRepository()
 	mockOrderRepo := &MockOrderRepository{}
-	service := NewShiftService(mockShiftRepo, mockOrderRepo)
+	service := NewShiftService(mockShiftRepo, mockOrderRepo, domain.NewStateMachineManager())
 
 	userID := primitive.NewObjectID()
 	req := &order.StartShiftRequest{
@@ -331,10 +294,10 @@ func TestStartShift_DuplicateShiftSameRole(t *testing.T) {
 	// Try to start second shift with same role
 	_, err = service.StartShift(context.Background(), req, userID.Hex(), "Waiter 1", order.RoleWaiter)
 	if err == nil {
-		t.Error("Expected error when starting duplicate shift for same role, got nil")
+		t.Fatal("Expected error when starting duplicate shift for same role, got nil")
 	}
 
-	if err.Error() != "user already has an open shift for this role" {
+	if !strings.Contains(err.Error(), "already has an open shift") {
 		t.Errorf("Expected specific error message, got %v", err)
 	}
 }
@@ -342,7 +305,7 @@ func TestStartShift_DuplicateShiftSameRole(t *testing.T) {
 func TestStartShift_MultipleRolesSameUser(t *testing.T) {
 	mockShiftRepo := NewMockShiftRepository()
 	mockOrderRepo := &MockOrderRepository{}
-	service := NewShiftService(mockShiftRepo, mockOrderRepo)
+	service := NewShiftService(mockShiftRepo, mockOrderRepo, domain.NewStateMachineManager())
 
 	userID := primitive.NewObjectID()
 
@@ -388,7 +351,7 @@ func TestStartShift_MultipleRolesSameUser(t *testing.T) {
 func TestGetCurrentShift_ByRole(t *testing.T) {
 	mockShiftRepo := NewMockShiftRepository()
 	mockOrderRepo := &MockOrderRepository{}
-	service := NewShiftService(mockShiftRepo, mockOrderRepo)
+	service := NewShiftService(mockShiftRepo, mockOrderRepo, domain.NewStateMachineManager())
 
 	userID := primitive.NewObjectID()
 
@@ -432,17 +395,12 @@ func TestGetCurrentShift_ByRole(t *testing.T) {
 		t.Errorf("Expected barista role, got %s", baristaShift.RoleType)
 	}
 
-	// Try to get cashier shift (should not exist)
-	_, err = service.GetCurrentShift(context.Background(), userID, order.RoleCashier)
-	if err == nil {
-		t.Error("Expected error when getting non-existent cashier shift")
-	}
 }
 
 func TestGetShiftsByUser_FilteredByRole(t *testing.T) {
 	mockShiftRepo := NewMockShiftRepository()
 	mockOrderRepo := &MockOrderRepository{}
-	service := NewShiftService(mockShiftRepo, mockOrderRepo)
+	service := NewShiftService(mockShiftRepo, mockOrderRepo, domain.NewStateMachineManager())
 
 	userID := primitive.NewObjectID()
 
@@ -485,7 +443,7 @@ func TestGetShiftsByUser_FilteredByRole(t *testing.T) {
 func TestGetShiftsByRole(t *testing.T) {
 	mockShiftRepo := NewMockShiftRepository()
 	mockOrderRepo := &MockOrderRepository{}
-	service := NewShiftService(mockShiftRepo, mockOrderRepo)
+	service := NewShiftService(mockShiftRepo, mockOrderRepo, domain.NewStateMachineManager())
 
 	user1ID := primitive.NewObjectID()
 	user2ID := primitive.NewObjectID()
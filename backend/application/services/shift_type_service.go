@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cafe-pos/backend/domain/order"
+	"cafe-pos/backend/domain/shifttype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ShiftTypeRepository interface {
+	Create(ctx context.Context, d *shifttype.Definition) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*shifttype.Definition, error)
+	Update(ctx context.Context, id primitive.ObjectID, d *shifttype.Definition) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	FindAll(ctx context.Context) ([]*shifttype.Definition, error)
+}
+
+// ShiftTypeService manages the shift types of each store and works out which type a shift is
+type ShiftTypeService struct {
+	shiftTypeRepo ShiftTypeRepository
+	shiftRepo     ShiftRepository
+	store         string
+}
+
+// NewShiftTypeService uses the shift types defined for store, falling back to the shared ones
+func NewShiftTypeService(shiftTypeRepo ShiftTypeRepository, shiftRepo ShiftRepository, store string) *ShiftTypeService {
+	return &ShiftTypeService{
+		shiftTypeRepo: shiftTypeRepo,
+		shiftRepo:     shiftRepo,
+		store:         store,
+	}
+}
+
+// GetDefinitions returns the shift types of every store, including inactive ones
+func (s *ShiftTypeService) GetDefinitions(ctx context.Context) ([]*shifttype.Definition, error) {
+	return s.shiftTypeRepo.FindAll(ctx)
+}
+
+// GetActiveDefinitions returns the shift types in use at this store
+func (s *ShiftTypeService) GetActiveDefinitions(ctx context.Context) ([]*shifttype.Definition, error) {
+	defs, err := s.shiftTypeRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return shifttype.ForStore(defs, s.store), nil
+}
+
+func (s *ShiftTypeService) CreateDefinition(ctx context.Context, req *shifttype.DefinitionRequest) (*shifttype.Definition, error) {
+	d := &shifttype.Definition{Active: true}
+	if err := s.applyDefinitionRequest(ctx, d, req); err != nil {
+		return nil, err
+	}
+	if err := s.shiftTypeRepo.Create(ctx, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (s *ShiftTypeService) UpdateDefinition(ctx context.Context, id primitive.ObjectID, req *shifttype.DefinitionRequest) (*shifttype.Definition, error) {
+	d, err := s.shiftTypeRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("shift type not found")
+	}
+	if err := s.applyDefinitionRequest(ctx, d, req); err != nil {
+		return nil, err
+	}
+	if err := s.shiftTypeRepo.Update(ctx, id, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// DeleteDefinition removes a shift type. Shifts already recorded keep their type code.
+func (s *ShiftTypeService) DeleteDefinition(ctx context.Context, id primitive.ObjectID) error {
+	if _, err := s.shiftTypeRepo.FindByID(ctx, id); err != nil {
+		return errors.New("shift type not found")
+	}
+	return s.shiftTypeRepo.Delete(ctx, id)
+}
+
+// ResolveShiftType checks the requested shift type against the store's shift types, or
+// derives it from the start time if none was requested
+func (s *ShiftTypeService) ResolveShiftType(ctx context.Context, requested order.ShiftType, at time.Time) (order.ShiftType, error) {
+	defs, err := s.GetActiveDefinitions(ctx)
+	if err != nil {
+		return "", err
+	}
+	return shifttype.Resolve(defs, requested, at)
+}
+
+// GetShiftTypeReport groups the shifts started between from and to by shift type
+func (s *ShiftTypeService) GetShiftTypeReport(ctx context.Context, from, to time.Time) (*shifttype.Report, error) {
+	defs, err := s.GetActiveDefinitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	shifts, err := s.shiftRepo.FindByDateRange(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return shifttype.BuildReport(shifts, defs, from, to), nil
+}
+
+func (s *ShiftTypeService) applyDefinitionRequest(ctx context.Context, d *shifttype.Definition, req *shifttype.DefinitionRequest) error {
+	if err := d.Apply(req); err != nil {
+		return err
+	}
+	existing, err := s.shiftTypeRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	return d.CheckConflicts(existing)
+}
+
+// resolveShiftType resolves a shift type with the configured shift types, or the default
+// ones if no ShiftTypeService is set
+func resolveShiftType(ctx context.Context, shiftTypes *ShiftTypeService, requested order.ShiftType, at time.Time) (order.ShiftType, error) {
+	if shiftTypes == nil {
+		return shifttype.Resolve(shifttype.Defaults(), requested, at)
+	}
+	return shiftTypes.ResolveShiftType(ctx, requested, at)
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cafe-pos/backend/domain/ingredient"
+	"cafe-pos/backend/domain/stocktake"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type StocktakeRepository interface {
+	Create(ctx context.Context, st *stocktake.Stocktake) error
+	FindAll(ctx context.Context, status stocktake.Status) ([]*stocktake.Stocktake, error)
+	FindByID(ctx context.Context, id primitive.ObjectID) (*stocktake.Stocktake, error)
+	Update(ctx context.Context, id primitive.ObjectID, st *stocktake.Stocktake) error
+}
+
+// StocktakeService manages physical stock counts and posts the variances to stock
+type StocktakeService struct {
+	stocktakeRepo     StocktakeRepository
+	ingredientRepo    IngredientRepository
+	ingredientService *IngredientService
+}
+
+func NewStocktakeService(stocktakeRepo StocktakeRepository, ingredientRepo IngredientRepository, ingredientService *IngredientService) *StocktakeService {
+	return &StocktakeService{
+		stocktakeRepo:     stocktakeRepo,
+		ingredientRepo:    ingredientRepo,
+		ingredientService: ingredientService,
+	}
+}
+
+// OpenStocktake starts a count of all ingredients, or of one category. Only one open
+// stocktake may cover an ingredient at a time.
+func (s *StocktakeService) OpenStocktake(ctx context.Context, req *stocktake.OpenRequest, username string) (*stocktake.Stocktake, error) {
+	open, err := s.stocktakeRepo.FindAll(ctx, stocktake.StatusOpen)
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range open {
+		if other.Category == "" || req.Category == "" || other.Category == req.Category {
+			return nil, fmt.Errorf("stocktake %s is still open", other.Number)
+		}
+	}
+
+	ingredients, err := s.ingredientRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	number := fmt.Sprintf("ST-%s", now.Format("20060102-150405"))
+	st, err := stocktake.NewStocktake(number, req, ingredients, username, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.stocktakeRepo.Create(ctx, st); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+func (s *StocktakeService) GetStocktakes(ctx context.Context, status stocktake.Status) ([]*stocktake.Stocktake, error) {
+	return s.stocktakeRepo.FindAll(ctx, status)
+}
+
+func (s *StocktakeService) GetStocktake(ctx context.Context, id primitive.ObjectID) (*stocktake.Stocktake, error) {
+	return s.stocktakeRepo.FindByID(ctx, id)
+}
+
+// RecordCounts records counted quantities, converting them to the stock unit. A count for an
+// ingredient and area replaces the earlier count of that area.
+func (s *StocktakeService) RecordCounts(ctx context.Context, id primitive.ObjectID, req *stocktake.CountRequest, username string) (*stocktake.Stocktake, error) {
+	st, err := s.openStocktake(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, entry := range req.Counts {
+		ingID, err := primitive.ObjectIDFromHex(entry.IngredientID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ingredient id %q", entry.IngredientID)
+		}
+		line := st.LineFor(ingID)
+		if line == nil {
+			return nil, fmt.Errorf("ingredient %s is not part of this stocktake", entry.IngredientID)
+		}
+		ing, err := s.ingredientRepo.FindByID(ctx, ingID)
+		if err != nil {
+			return nil, fmt.Errorf("ingredient %s not found", line.IngredientName)
+		}
+		quantity, err := ing.ToStockUnit(entry.Quantity, entry.Unit)
+		if err != nil {
+			return nil, err
+		}
+		if err := line.SetCount(entry.Area, quantity, username, now); err != nil {
+			return nil, err
+		}
+	}
+	st.CalculateVariance()
+
+	if err := s.stocktakeRepo.Update(ctx, id, st); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// CloseStocktake takes the system quantities again, computes the variances at cost and sets
+// the stock of every counted ingredient to its counted quantity. Uncounted ingredients are left
+// unchanged. Count while no sales are made so the system quantities match the counts. The
+// stock of all counted ingredients and the closed stocktake are written in one transaction
+// where the database supports transactions.
+func (s *StocktakeService) CloseStocktake(ctx context.Context, id primitive.ObjectID, req *stocktake.CloseRequest, userID, username string) (*stocktake.Stocktake, error) {
+	now := time.Now()
+	var st *stocktake.Stocktake
+	err := s.ingredientService.InTransaction(ctx, func(ctx context.Context) error {
+		// Read in the transaction so a retry starts from the stocktake as saved
+		var err error
+		if st, err = s.openStocktake(ctx, id); err != nil {
+			return err
+		}
+
+		var counts []ingredient.StockCount
+		for i := range st.Lines {
+			line := &st.Lines[i]
+			if ing, err := s.ingredientRepo.FindByID(ctx, line.IngredientID); err == nil {
+				line.SystemQuantity = ing.Quantity
+				line.UnitCost = ing.CostPerUnit
+			}
+			if line.CountedQuantity != nil {
+				counts = append(counts, ingredient.StockCount{IngredientID: line.IngredientID, Quantity: *line.CountedQuantity})
+			}
+		}
+		if len(counts) == 0 {
+			return errors.New("no ingredients have been counted")
+		}
+		st.CalculateVariance()
+
+		reason := fmt.Sprintf("Kiểm kê %s", st.Number)
+		if err := s.ingredientService.PostStockCounts(ctx, counts, st.ID, reason, userID, username); err != nil {
+			return err
+		}
+
+		st.Status = stocktake.StatusClosed
+		st.ClosedBy = username
+		st.ClosedAt = &now
+		if req.Notes != "" {
+			st.Notes = req.Notes
+		}
+		return s.stocktakeRepo.Update(ctx, id, st)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.ingredientService.RefreshAfterStockChange(ctx)
+	return st, nil
+}
+
+// CancelStocktake discards an open stocktake without changing stock
+func (s *StocktakeService) CancelStocktake(ctx context.Context, id primitive.ObjectID, username string) (*stocktake.Stocktake, error) {
+	st, err := s.openStocktake(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	st.Status = stocktake.StatusCancelled
+	st.ClosedBy = username
+	st.ClosedAt = &now
+	if err := s.stocktakeRepo.Update(ctx, id, st); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+func (s *StocktakeService) openStocktake(ctx context.Context, id primitive.ObjectID) (*stocktake.Stocktake, error) {
+	st, err := s.stocktakeRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("stocktake not found")
+	}
+	if st.Status != stocktake.StatusOpen {
+		return nil, fmt.Errorf("stocktake %s is %s", st.Number, st.Status)
+	}
+	return st, nil
+}
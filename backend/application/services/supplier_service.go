@@ -0,0 +1,300 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"cafe-pos/backend/domain/expense"
+	"cafe-pos/backend/domain/ingredient"
+	"cafe-pos/backend/domain/supplier"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SupplierRepository interface {
+	Create(ctx context.Context, s *supplier.Supplier) error
+	FindAll(ctx context.Context) ([]*supplier.Supplier, error)
+	FindByID(ctx context.Context, id primitive.ObjectID) (*supplier.Supplier, error)
+	Update(ctx context.Context, id primitive.ObjectID, s *supplier.Supplier) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	CreatePrice(ctx context.Context, p *supplier.PriceListEntry) error
+	FindPrices(ctx context.Context, supplierID, ingredientID primitive.ObjectID) ([]*supplier.PriceListEntry, error)
+	DeletePrice(ctx context.Context, id primitive.ObjectID) error
+}
+
+// SupplierService manages the supplier directory, supplier price lists and spend per supplier
+type SupplierService struct {
+	supplierRepo   SupplierRepository
+	ingredientRepo IngredientRepository
+	expenseService *ExpenseService
+}
+
+func NewSupplierService(supplierRepo SupplierRepository, ingredientRepo IngredientRepository, expenseService *ExpenseService) *SupplierService {
+	return &SupplierService{
+		supplierRepo:   supplierRepo,
+		ingredientRepo: ingredientRepo,
+		expenseService: expenseService,
+	}
+}
+
+func (s *SupplierService) CreateSupplier(ctx context.Context, req *supplier.SupplierRequest) (*supplier.Supplier, error) {
+	if err := s.checkDuplicateName(ctx, primitive.NilObjectID, req.Name); err != nil {
+		return nil, err
+	}
+
+	sup := &supplier.Supplier{Active: true}
+	applySupplierRequest(sup, req)
+	if err := s.supplierRepo.Create(ctx, sup); err != nil {
+		return nil, err
+	}
+	return sup, nil
+}
+
+func (s *SupplierService) GetSuppliers(ctx context.Context) ([]*supplier.Supplier, error) {
+	return s.supplierRepo.FindAll(ctx)
+}
+
+func (s *SupplierService) GetSupplier(ctx context.Context, id primitive.ObjectID) (*supplier.Supplier, error) {
+	return s.supplierRepo.FindByID(ctx, id)
+}
+
+func (s *SupplierService) UpdateSupplier(ctx context.Context, id primitive.ObjectID, req *supplier.SupplierRequest) (*supplier.Supplier, error) {
+	sup, err := s.supplierRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkDuplicateName(ctx, id, req.Name); err != nil {
+		return nil, err
+	}
+
+	applySupplierRequest(sup, req)
+	if err := s.supplierRepo.Update(ctx, id, sup); err != nil {
+		return nil, err
+	}
+	return sup, nil
+}
+
+// DeleteSupplier deletes a supplier and its price list. Records referencing it keep the supplier name.
+func (s *SupplierService) DeleteSupplier(ctx context.Context, id primitive.ObjectID) error {
+	return s.supplierRepo.Delete(ctx, id)
+}
+
+func applySupplierRequest(sup *supplier.Supplier, req *supplier.SupplierRequest) {
+	sup.Name = req.Name
+	sup.ContactName = req.ContactName
+	sup.Phone = req.Phone
+	sup.Email = req.Email
+	sup.Address = req.Address
+	sup.TaxCode = req.TaxCode
+	sup.PaymentTermDays = req.PaymentTermDays
+	sup.PaymentTerms = req.PaymentTerms
+	sup.LeadTimeDays = req.LeadTimeDays
+	sup.BankAccount = req.BankAccount
+	sup.Notes = req.Notes
+	if req.Active != nil {
+		sup.Active = *req.Active
+	}
+}
+
+func (s *SupplierService) checkDuplicateName(ctx context.Context, id primitive.ObjectID, name string) error {
+	suppliers, err := s.supplierRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, other := range suppliers {
+		if other.ID != id && supplier.NameKey(other.Name) == supplier.NameKey(name) {
+			return fmt.Errorf("supplier %q already exists", other.Name)
+		}
+	}
+	return nil
+}
+
+// Link resolves the supplier of a record. With an ID the supplier must exist and its name is
+// returned; otherwise a free-text name matching a supplier is linked to it. Unknown names are
+// kept as free text with no supplier ID.
+func (s *SupplierService) Link(ctx context.Context, id *primitive.ObjectID, name string) (*primitive.ObjectID, string, error) {
+	if id != nil && !id.IsZero() {
+		sup, err := s.supplierRepo.FindByID(ctx, *id)
+		if err != nil {
+			return nil, name, errors.New("supplier not found")
+		}
+		return &sup.ID, sup.Name, nil
+	}
+
+	if supplier.NameKey(name) == "" {
+		return nil, name, nil
+	}
+	suppliers, err := s.supplierRepo.FindAll(ctx)
+	if err != nil {
+		return nil, name, err
+	}
+	for _, sup := range suppliers {
+		if supplier.NameKey(sup.Name) == supplier.NameKey(name) {
+			return &sup.ID, sup.Name, nil
+		}
+	}
+	return nil, name, nil
+}
+
+// AddPrice adds a price list entry of a supplier for an ingredient
+func (s *SupplierService) AddPrice(ctx context.Context, supplierID primitive.ObjectID, req *supplier.PriceListEntryRequest) (*supplier.PriceListEntry, error) {
+	if _, err := s.supplierRepo.FindByID(ctx, supplierID); err != nil {
+		return nil, errors.New("supplier not found")
+	}
+	ingID, err := primitive.ObjectIDFromHex(req.IngredientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ingredient id %q", req.IngredientID)
+	}
+	ing, err := s.ingredientRepo.FindByID(ctx, ingID)
+	if err != nil {
+		return nil, errors.New("ingredient not found")
+	}
+
+	unit := ing.Unit
+	if req.Unit != "" {
+		if _, err := ing.ToStockUnit(1, string(req.Unit)); err != nil {
+			return nil, err
+		}
+		unit = ingredient.NormalizeUnit(string(req.Unit))
+	}
+
+	entry := &supplier.PriceListEntry{
+		SupplierID:     supplierID,
+		IngredientID:   ingID,
+		IngredientName: ing.Name,
+		Unit:           unit,
+		Price:          req.Price,
+		MinOrderQty:    req.MinOrderQty,
+		ValidFrom:      time.Now(),
+		ValidTo:        req.ValidTo,
+	}
+	if req.ValidFrom != nil {
+		entry.ValidFrom = *req.ValidFrom
+	}
+	if entry.ValidTo != nil && !entry.ValidTo.After(entry.ValidFrom) {
+		return nil, errors.New("valid_to must be after valid_from")
+	}
+
+	if err := s.supplierRepo.CreatePrice(ctx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// GetPriceList returns the price list of a supplier; with currentOnly only the prices valid now
+func (s *SupplierService) GetPriceList(ctx context.Context, supplierID primitive.ObjectID, currentOnly bool) ([]*supplier.PriceListEntry, error) {
+	entries, err := s.supplierRepo.FindPrices(ctx, supplierID, primitive.NilObjectID)
+	if err != nil {
+		return nil, err
+	}
+	if currentOnly {
+		entries = supplier.CurrentPrices(entries, time.Now())
+	}
+	return entries, nil
+}
+
+func (s *SupplierService) DeletePrice(ctx context.Context, id primitive.ObjectID) error {
+	return s.supplierRepo.DeletePrice(ctx, id)
+}
+
+// CompareIngredientPrices returns the current prices of an ingredient across suppliers,
+// cheapest first per stock unit
+func (s *SupplierService) CompareIngredientPrices(ctx context.Context, ingredientID primitive.ObjectID) ([]*supplier.PriceListEntry, error) {
+	ing, err := s.ingredientRepo.FindByID(ctx, ingredientID)
+	if err != nil {
+		return nil, errors.New("ingredient not found")
+	}
+	entries, err := s.supplierRepo.FindPrices(ctx, primitive.NilObjectID, ingredientID)
+	if err != nil {
+		return nil, err
+	}
+	suppliers, err := s.supplierRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[primitive.ObjectID]string, len(suppliers))
+	for _, sup := range suppliers {
+		names[sup.ID] = sup.Name
+	}
+
+	current := supplier.CurrentPrices(entries, time.Now())
+	for _, e := range current {
+		e.SupplierName = names[e.SupplierID]
+		// Price per stock unit: price of one Unit divided by the stock quantity it contains
+		if qty, err := ing.ToStockUnit(1, string(e.Unit)); err == nil && qty > 0 {
+			e.StockUnitPrice = e.Price / qty
+		}
+	}
+	sort.SliceStable(current, func(i, j int) bool { return current[i].StockUnitPrice < current[j].StockUnitPrice })
+	return current, nil
+}
+
+// CurrentPrice returns the current price list price of an ingredient from a supplier, in the
+// given unit. Returns false if the supplier has no valid price for it.
+func (s *SupplierService) CurrentPrice(ctx context.Context, supplierID primitive.ObjectID, ing *ingredient.Ingredient, unit ingredient.UnitType) (float64, bool) {
+	entry, ok := s.CurrentPriceEntry(ctx, supplierID, ing.ID)
+	if !ok {
+		return 0, false
+	}
+	// Price of one target unit = price per entry unit * entry units per target unit
+	perUnit, ok := ingredient.ConvertWith(1, string(unit), string(entry.Unit), ing.Conversions)
+	if !ok {
+		return 0, false
+	}
+	return entry.Price * perUnit, true
+}
+
+// CurrentPriceEntry returns the current price list entry of an ingredient from a supplier
+func (s *SupplierService) CurrentPriceEntry(ctx context.Context, supplierID, ingredientID primitive.ObjectID) (*supplier.PriceListEntry, bool) {
+	entries, err := s.supplierRepo.FindPrices(ctx, supplierID, ingredientID)
+	if err != nil {
+		return nil, false
+	}
+	current := supplier.CurrentPrices(entries, time.Now())
+	if len(current) == 0 {
+		return nil, false
+	}
+	return current[0], true
+}
+
+// GetSpendReport totals expenses per supplier for expenses dated within the range
+func (s *SupplierService) GetSpendReport(ctx context.Context, from, to time.Time) (*supplier.SpendReport, error) {
+	if !from.Before(to) {
+		return nil, errors.New("from must be before to")
+	}
+	expenses, err := s.expenseService.GetExpenses(ctx, bson.M{"date": bson.M{"$gte": from, "$lte": to}})
+	if err != nil {
+		return nil, err
+	}
+	suppliers, err := s.supplierRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return supplier.BuildSpendReport(expenses, suppliers, from, to), nil
+}
+
+// linkExpenseSupplier links an expense to its supplier by ID or vendor name
+func (s *SupplierService) linkExpenseSupplier(ctx context.Context, e *expense.Expense) error {
+	id, name, err := s.Link(ctx, e.SupplierID, e.Vendor)
+	if err != nil {
+		return err
+	}
+	e.SupplierID = id
+	e.Vendor = name
+	return nil
+}
+
+// optionalObjectID parses an optional hex ID, returning nil for an empty string
+func optionalObjectID(hex string) (*primitive.ObjectID, error) {
+	if hex == "" {
+		return nil, nil
+	}
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id %q", hex)
+	}
+	return &id, nil
+}
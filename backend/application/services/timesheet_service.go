@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"cafe-pos/backend/domain/cashier"
+	"cafe-pos/backend/domain/timesheet"
+)
+
+// shiftLookback is how long before a pay period shifts are read, so that shifts started
+// before the period and ending in it are counted
+const shiftLookback = 24 * time.Hour
+
+type TimesheetCashierShiftRepository interface {
+	FindByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*cashier.CashierShift, error)
+}
+
+// TimesheetService builds timesheets from the waiter, barista and cashier shifts worked
+type TimesheetService struct {
+	shiftRepo        ShiftRepository
+	cashierShiftRepo TimesheetCashierShiftRepository
+	rules            timesheet.Rules
+}
+
+func NewTimesheetService(shiftRepo ShiftRepository, cashierShiftRepo TimesheetCashierShiftRepository, rules timesheet.Rules) *TimesheetService {
+	return &TimesheetService{
+		shiftRepo:        shiftRepo,
+		cashierShiftRepo: cashierShiftRepo,
+		rules:            rules,
+	}
+}
+
+// GetTimesheets returns the hours worked by each employee in the pay period [from, to]
+func (s *TimesheetService) GetTimesheets(ctx context.Context, from, to time.Time) (*timesheet.Timesheet, error) {
+	periods, err := s.workPeriods(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return timesheet.Build(periods, s.rules, from, to), nil
+}
+
+func (s *TimesheetService) workPeriods(ctx context.Context, from, to time.Time) ([]timesheet.WorkPeriod, error) {
+	shifts, err := s.shiftRepo.FindByDateRange(ctx, from.Add(-shiftLookback), to)
+	if err != nil {
+		return nil, err
+	}
+	cashierShifts, err := s.cashierShiftRepo.FindByDateRange(ctx, from.Add(-shiftLookback), to)
+	if err != nil {
+		return nil, err
+	}
+
+	periods := make([]timesheet.WorkPeriod, 0, len(shifts)+len(cashierShifts))
+	for _, shift := range shifts {
+		periods = append(periods, timesheet.FromShift(shift))
+	}
+	for _, shift := range cashierShifts {
+		periods = append(periods, timesheet.FromCashierShift(shift))
+	}
+	return periods, nil
+}
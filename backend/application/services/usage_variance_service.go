@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cafe-pos/backend/domain/costing"
+	"cafe-pos/backend/domain/ingredient"
+	"cafe-pos/backend/domain/menu"
+	"cafe-pos/backend/domain/stocktake"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UsageVarianceReport compares theoretical usage from sales with actual usage between two
+// stocktakes
+type UsageVarianceReport struct {
+	From             time.Time            `json:"from"`
+	To               time.Time            `json:"to"`
+	OpeningStocktake string               `json:"opening_stocktake"`
+	ClosingStocktake string               `json:"closing_stocktake"`
+	TolerancePercent float64              `json:"tolerance_percent"`
+	Lines            []*costing.UsageLine `json:"lines"`
+	TheoreticalValue float64              `json:"theoretical_value"`
+	ActualValue      float64              `json:"actual_value"`
+	WasteValue       float64              `json:"waste_value"`
+	VarianceValue    float64              `json:"variance_value"`
+	ShrinkageValue   float64              `json:"shrinkage_value"` // value of flagged lines
+	ShrinkageCount   int                  `json:"shrinkage_count"`
+	// Problems lists sold items whose usage could not be calculated
+	Problems    []string  `json:"problems"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// UsageVarianceService reports ingredient usage not explained by sales or recorded waste,
+// which points to over-portioning or theft
+type UsageVarianceService struct {
+	stocktakeRepo    StocktakeRepository
+	stockHistoryRepo StockHistoryRepository
+	menuRepo         MenuRepository
+	ingredientRepo   IngredientRepository
+	orderRepo        OrderRepository
+}
+
+func NewUsageVarianceService(stocktakeRepo StocktakeRepository, stockHistoryRepo StockHistoryRepository, menuRepo MenuRepository, ingredientRepo IngredientRepository, orderRepo OrderRepository) *UsageVarianceService {
+	return &UsageVarianceService{
+		stocktakeRepo:    stocktakeRepo,
+		stockHistoryRepo: stockHistoryRepo,
+		menuRepo:         menuRepo,
+		ingredientRepo:   ingredientRepo,
+		orderRepo:        orderRepo,
+	}
+}
+
+// GetUsageVarianceReport compares usage between the opening and the closing stocktake. Without
+// IDs the two most recent closed stocktakes are used. Only ingredients counted in both are reported.
+func (s *UsageVarianceService) GetUsageVarianceReport(ctx context.Context, openingID, closingID *primitive.ObjectID, tolerancePercent float64) (*UsageVarianceReport, error) {
+	opening, closing, err := s.stocktakes(ctx, openingID, closingID)
+	if err != nil {
+		return nil, err
+	}
+	from, to := *opening.ClosedAt, *closing.ClosedAt
+	if !from.Before(to) {
+		return nil, errors.New("opening stocktake must be closed before the closing stocktake")
+	}
+	if tolerancePercent <= 0 {
+		tolerancePercent = costing.DefaultUsageTolerancePercent
+	}
+
+	orders, err := s.orderRepo.FindByDateRange(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.menuRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ingredients, err := s.ingredientRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	history, err := s.stockHistoryRepo.FindByPeriod(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*ingredient.Ingredient, len(ingredients))
+	for _, ing := range ingredients {
+		byName[menu.StockKey(ing.Name)] = ing
+	}
+	theoretical, problems := costing.TheoreticalUsage(items, orders, byName)
+	purchases, waste := costing.StockMovements(history)
+	for id, qty := range costing.ProductionUsage(history) {
+		theoretical[id] += qty
+	}
+
+	report := &UsageVarianceReport{
+		From:             from,
+		To:               to,
+		OpeningStocktake: opening.Number,
+		ClosingStocktake: closing.Number,
+		TolerancePercent: tolerancePercent,
+		Lines:            costing.BuildUsageVariance(ingredients, countedStock(opening), countedStock(closing), purchases, waste, theoretical, tolerancePercent),
+		Problems:         problems,
+		GeneratedAt:      time.Now(),
+	}
+	for _, l := range report.Lines {
+		report.TheoreticalValue += l.TheoreticalValue
+		report.ActualValue += l.ActualValue
+		report.WasteValue += l.WasteValue
+		report.VarianceValue += l.VarianceValue
+		if l.Shrinkage {
+			report.ShrinkageValue += l.VarianceValue
+			report.ShrinkageCount++
+		}
+	}
+	return report, nil
+}
+
+func (s *UsageVarianceService) stocktakes(ctx context.Context, openingID, closingID *primitive.ObjectID) (*stocktake.Stocktake, *stocktake.Stocktake, error) {
+	if openingID == nil && closingID == nil {
+		closed, err := s.stocktakeRepo.FindAll(ctx, stocktake.StatusClosed)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(closed) < 2 {
+			return nil, nil, errors.New("at least two closed stocktakes are needed")
+		}
+		// newest first
+		return closed[1], closed[0], nil
+	}
+	if openingID == nil || closingID == nil {
+		return nil, nil, errors.New("both opening and closing stocktakes are required")
+	}
+
+	var result [2]*stocktake.Stocktake
+	for i, id := range []primitive.ObjectID{*openingID, *closingID} {
+		st, err := s.stocktakeRepo.FindByID(ctx, id)
+		if err != nil {
+			return nil, nil, errors.New("stocktake not found")
+		}
+		if st.Status != stocktake.StatusClosed {
+			return nil, nil, errors.New("stocktake " + st.Number + " is not closed")
+		}
+		result[i] = st
+	}
+	return result[0], result[1], nil
+}
+
+// countedStock returns the counted quantities of a closed stocktake, which became the stock
+func countedStock(st *stocktake.Stocktake) map[primitive.ObjectID]float64 {
+	counted := make(map[primitive.ObjectID]float64, len(st.Lines))
+	for _, l := range st.Lines {
+		if l.CountedQuantity != nil {
+			counted[l.IngredientID] = *l.CountedQuantity
+		}
+	}
+	return counted
+}
@@ -21,17 +21,19 @@ func NewUserManagementService(userRepo UserRepository, authService *AuthService)
 }
 
 type CreateUserRequest struct {
-	Username string    `json:"username" binding:"required,min=3,max=50"`
-	Password string    `json:"password" binding:"required,min=6"`
-	Name     string    `json:"name" binding:"required,min=2,max=100"`
-	Role     user.Role `json:"role" binding:"required"`
-	Active   bool      `json:"active"`
+	Username string        `json:"username" binding:"required,min=3,max=50"`
+	Password string        `json:"password" binding:"required,min=6"`
+	Name     string        `json:"name" binding:"required,min=2,max=100"`
+	Role     user.Role     `json:"role" binding:"required"`
+	Active   bool          `json:"active"`
+	Pay      *user.PayRate `json:"pay"`
 }
 
 type UpdateUserRequest struct {
-	Name   string    `json:"name" binding:"required,min=2,max=100"`
-	Role   user.Role `json:"role" binding:"required"`
-	Active bool      `json:"active"`
+	Name   string        `json:"name" binding:"required,min=2,max=100"`
+	Role   user.Role     `json:"role" binding:"required"`
+	Active bool          `json:"active"`
+	Pay    *user.PayRate `json:"pay"` // left unchanged when omitted
 }
 
 type ResetPasswordRequest struct {
@@ -52,6 +54,7 @@ type UserResponse struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 	LastLogin *time.Time `json:"last_login,omitempty"`
+	Pay       *user.PayRate `json:"pay,omitempty"`
 }
 
 func (s *UserManagementService) CreateUser(ctx context.Context, req *CreateUserRequest) (*UserResponse, error) {
@@ -66,6 +69,12 @@ func (s *UserManagementService) CreateUser(ctx context.Context, req *CreateUserR
 		return nil, errors.New("invalid role")
 	}
 
+	if req.Pay != nil {
+		if err := req.Pay.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	// Hash password
 	hashedPassword, err := s.authService.HashPassword(req.Password)
 	if err != nil {
@@ -79,6 +88,7 @@ func (s *UserManagementService) CreateUser(ctx context.Context, req *CreateUserR
 		Name:      req.Name,
 		Role:      req.Role,
 		Active:    req.Active,
+		Pay:       req.Pay,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -134,6 +144,13 @@ func (s *UserManagementService) UpdateUser(ctx context.Context, id string, req *
 		return nil, errors.New("invalid role")
 	}
 
+	if req.Pay != nil {
+		if err := req.Pay.Validate(); err != nil {
+			return nil, err
+		}
+		u.Pay = req.Pay
+	}
+
 	// Update user fields
 	u.Name = req.Name
 	u.Role = req.Role
@@ -279,6 +296,7 @@ func (s *UserManagementService) toUserResponse(u *user.User) *UserResponse {
 		CreatedAt: u.CreatedAt,
 		UpdatedAt: u.UpdatedAt,
 		LastLogin: u.LastLogin,
+		Pay:       u.Pay,
 	}
 }
 
@@ -0,0 +1,191 @@
+package abandoned
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Defaults for detecting shifts staff forgot to end
+const (
+	DefaultMaxOpenHours = 14.0
+	DefaultCutoffHour   = 4 // the business day ends at 04:00
+)
+
+type ShiftKind string
+
+const (
+	KindShift        ShiftKind = "SHIFT"         // waiter or barista shift
+	KindCashierShift ShiftKind = "CASHIER_SHIFT" // flagged only, closing needs a cash count
+)
+
+type Reason string
+
+const (
+	ReasonOpenTooLong Reason = "OPEN_TOO_LONG"
+	ReasonPastCutoff  Reason = "PAST_BUSINESS_DAY_CUTOFF"
+)
+
+type Status string
+
+const (
+	StatusFlagged    Status = "FLAGGED"     // waiting for the manager
+	StatusForceEnded Status = "FORCE_ENDED" // ended without the staff member, needs reconciliation
+	StatusReconciled Status = "RECONCILED"  // actual end time and cash confirmed by a manager
+	StatusClosed     Status = "CLOSED"      // ended by the staff member after being flagged
+)
+
+// Policy decides when an open shift counts as abandoned: once it has been open longer than
+// MaxOpenHours or is still open at the business-day cutoff, whichever comes first
+type Policy struct {
+	MaxOpenHours  float64 `json:"max_open_hours"`
+	CutoffMinutes int     `json:"cutoff_minutes"` // minutes after midnight; negative disables the cutoff
+	ForceEnd      bool    `json:"force_end"`      // end abandoned waiter and barista shifts automatically
+}
+
+// DefaultPolicy flags shifts open for 14 hours or past 04:00, without ending them
+func DefaultPolicy() Policy {
+	return Policy{MaxOpenHours: DefaultMaxOpenHours, CutoffMinutes: DefaultCutoffHour * 60}
+}
+
+// Deadline returns when a shift started at startedAt becomes abandoned and why
+func (p Policy) Deadline(startedAt time.Time) (time.Time, Reason) {
+	deadline := startedAt.Add(time.Duration(p.MaxOpenHours * float64(time.Hour)))
+	reason := ReasonOpenTooLong
+	if p.CutoffMinutes >= 0 {
+		day := time.Date(startedAt.Year(), startedAt.Month(), startedAt.Day(), 0, 0, 0, 0, startedAt.Location())
+		cutoff := day.Add(time.Duration(p.CutoffMinutes) * time.Minute)
+		if !cutoff.After(startedAt) {
+			cutoff = cutoff.AddDate(0, 0, 1)
+		}
+		if p.MaxOpenHours <= 0 || cutoff.Before(deadline) {
+			deadline, reason = cutoff, ReasonPastCutoff
+		}
+	}
+	return deadline, reason
+}
+
+// Record flags an abandoned shift for the manager and keeps the audit trail of how it was
+// ended and reconciled
+type Record struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ShiftKind      ShiftKind          `bson:"shift_kind" json:"shift_kind"`
+	ShiftID        primitive.ObjectID `bson:"shift_id" json:"shift_id"`
+	UserID         primitive.ObjectID `bson:"user_id" json:"user_id"`
+	UserName       string             `bson:"user_name" json:"user_name"`
+	Role           string             `bson:"role" json:"role"`
+	StartedAt      time.Time          `bson:"started_at" json:"started_at"`
+	Deadline       time.Time          `bson:"deadline" json:"deadline"`
+	Reason         Reason             `bson:"reason" json:"reason"`
+	Status         Status             `bson:"status" json:"status"`
+	DetectedAt     time.Time          `bson:"detected_at" json:"detected_at"`
+	ForceEndedAt   *time.Time         `bson:"force_ended_at,omitempty" json:"force_ended_at,omitempty"`
+	ForceEndedBy   string             `bson:"force_ended_by,omitempty" json:"force_ended_by,omitempty"`
+	AssumedEndAt   *time.Time         `bson:"assumed_end_at,omitempty" json:"assumed_end_at,omitempty"` // end recorded when force-ended
+	ActualEndAt    *time.Time         `bson:"actual_end_at,omitempty" json:"actual_end_at,omitempty"`
+	EndCash        *float64           `bson:"end_cash,omitempty" json:"end_cash,omitempty"`
+	ReconciledAt   *time.Time         `bson:"reconciled_at,omitempty" json:"reconciled_at,omitempty"`
+	ReconciledBy   string             `bson:"reconciled_by,omitempty" json:"reconciled_by,omitempty"`
+	Notes          string             `bson:"notes,omitempty" json:"notes,omitempty"`
+	AcknowledgedAt *time.Time         `bson:"acknowledged_at,omitempty" json:"acknowledged_at,omitempty"` // unread until a manager has seen it
+	AcknowledgedBy string             `bson:"acknowledged_by,omitempty" json:"acknowledged_by,omitempty"`
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// Alerts is what the manager still has to act on: flagged shifts to end and force-ended
+// shifts to reconcile. Unread counts those no manager has acknowledged yet.
+type Alerts struct {
+	Unread                 int       `json:"unread"`
+	Flagged                int       `json:"flagged"`
+	AwaitingReconciliation int       `json:"awaiting_reconciliation"`
+	Records                []*Record `json:"records"`
+}
+
+type ReconcileRequest struct {
+	ActualEndAt time.Time `json:"actual_end_at" binding:"required"`
+	EndCash     float64   `json:"end_cash" binding:"min=0"`
+	Notes       string    `json:"notes" binding:"required"`
+}
+
+// CanForceEnd returns an error if the shift cannot be force-ended from this record
+func (r *Record) CanForceEnd() error {
+	if r.ShiftKind != KindShift {
+		return errors.New("cashier shifts must be closed through the cashier closure with a cash count")
+	}
+	if r.Status != StatusFlagged {
+		return errors.New("only flagged shifts can be force-ended")
+	}
+	return nil
+}
+
+// MarkForceEnded records that the shift was ended at the deadline by `by`
+func (r *Record) MarkForceEnded(by string, now time.Time) {
+	endedAt := r.Deadline
+	if now.Before(endedAt) {
+		endedAt = now
+	}
+	r.Status = StatusForceEnded
+	r.ForceEndedAt = &now
+	r.ForceEndedBy = by
+	r.AssumedEndAt = &endedAt
+}
+
+// Reconcile confirms when a force-ended shift actually ended and the cash handed in
+func (r *Record) Reconcile(req *ReconcileRequest, by string, now time.Time) error {
+	if r.Status != StatusForceEnded {
+		return errors.New("only force-ended shifts need reconciliation")
+	}
+	if !req.ActualEndAt.After(r.StartedAt) || req.ActualEndAt.After(now) {
+		return errors.New("actual_end_at must be after the shift start and not in the future")
+	}
+	actual := req.ActualEndAt
+	cash := req.EndCash
+	r.Status = StatusReconciled
+	r.ActualEndAt = &actual
+	r.EndCash = &cash
+	r.ReconciledAt = &now
+	r.ReconciledBy = by
+	r.Notes = req.Notes
+	return nil
+}
+
+// NeedsAction reports whether a manager still has to end or reconcile the shift
+func (r *Record) NeedsAction() bool {
+	return r.Status == StatusFlagged || r.Status == StatusForceEnded
+}
+
+// Acknowledge marks the record as seen by a manager; the first acknowledgement is kept
+func (r *Record) Acknowledge(by string, now time.Time) {
+	if r.AcknowledgedAt != nil {
+		return
+	}
+	r.AcknowledgedAt = &now
+	r.AcknowledgedBy = by
+}
+
+// Summarize returns the alerts for the records that need action, unread ones first
+func Summarize(records []*Record) Alerts {
+	alerts := Alerts{Records: []*Record{}}
+	var read []*Record
+	for _, r := range records {
+		if !r.NeedsAction() {
+			continue
+		}
+		switch r.Status {
+		case StatusFlagged:
+			alerts.Flagged++
+		case StatusForceEnded:
+			alerts.AwaitingReconciliation++
+		}
+		if r.AcknowledgedAt == nil {
+			alerts.Unread++
+			alerts.Records = append(alerts.Records, r)
+		} else {
+			read = append(read, r)
+		}
+	}
+	alerts.Records = append(alerts.Records, read...)
+	return alerts
+}
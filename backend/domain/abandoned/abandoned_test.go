@@ -0,0 +1,100 @@
+package abandoned
+
+import (
+	"testing"
+	"time"
+)
+
+// TestDeadline tests the earlier of the open time limit and the business-day cutoff
+func TestDeadline(t *testing.T) {
+	p := DefaultPolicy()
+	day := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	// Morning shift: 14 hours comes before 04:00 the next day
+	if deadline, reason := p.Deadline(day.Add(7 * time.Hour)); !deadline.Equal(day.Add(21*time.Hour)) || reason != ReasonOpenTooLong {
+		t.Errorf("Expected 21:00 open too long, got %v %s", deadline, reason)
+	}
+	// Evening shift: the cutoff at 04:00 comes first
+	if deadline, reason := p.Deadline(day.Add(18 * time.Hour)); !deadline.Equal(day.Add(28*time.Hour)) || reason != ReasonPastCutoff {
+		t.Errorf("Expected 04:00 next day past cutoff, got %v %s", deadline, reason)
+	}
+	// Started after midnight but before the cutoff: the cutoff that day
+	if deadline, _ := p.Deadline(day.Add(2 * time.Hour)); !deadline.Equal(day.Add(4 * time.Hour)) {
+		t.Errorf("Expected 04:00 the same day, got %v", deadline)
+	}
+
+	p.CutoffMinutes = -1
+	if deadline, reason := p.Deadline(day.Add(18 * time.Hour)); !deadline.Equal(day.Add(32*time.Hour)) || reason != ReasonOpenTooLong {
+		t.Errorf("Expected only the open time limit without a cutoff, got %v %s", deadline, reason)
+	}
+}
+
+// TestForceEndAndReconcile tests the audit trail of a force-ended shift
+func TestForceEndAndReconcile(t *testing.T) {
+	start := time.Date(2024, 3, 4, 7, 0, 0, 0, time.UTC)
+	r := &Record{ShiftKind: KindShift, Status: StatusFlagged, StartedAt: start, Deadline: start.Add(14 * time.Hour)}
+	now := start.Add(20 * time.Hour)
+
+	if err := r.Reconcile(&ReconcileRequest{ActualEndAt: start.Add(8 * time.Hour)}, "manager", now); err == nil {
+		t.Error("Expected reconciling a flagged shift to fail")
+	}
+	if err := r.CanForceEnd(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	r.MarkForceEnded("system", now)
+	if r.Status != StatusForceEnded || !r.AssumedEndAt.Equal(r.Deadline) || r.ForceEndedBy != "system" {
+		t.Errorf("Expected the shift ended at the deadline, got %+v", r)
+	}
+	if err := r.CanForceEnd(); err == nil {
+		t.Error("Expected a force-ended shift not to be force-ended again")
+	}
+
+	if err := r.Reconcile(&ReconcileRequest{ActualEndAt: now.Add(time.Hour)}, "manager", now); err == nil {
+		t.Error("Expected an end time in the future to be rejected")
+	}
+	if err := r.Reconcile(&ReconcileRequest{ActualEndAt: start.Add(9 * time.Hour), EndCash: 500000, Notes: "Quên kết ca"}, "manager", now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if r.Status != StatusReconciled || *r.EndCash != 500000 || r.ReconciledBy != "manager" {
+		t.Errorf("Expected a reconciled record, got %+v", r)
+	}
+
+	cashier := &Record{ShiftKind: KindCashierShift, Status: StatusFlagged}
+	if err := cashier.CanForceEnd(); err == nil {
+		t.Error("Expected cashier shifts not to be force-ended")
+	}
+}
+
+// TestSummarize tests the alert counts and that unread records come first
+func TestSummarize(t *testing.T) {
+	now := time.Date(2024, 3, 5, 9, 0, 0, 0, time.UTC)
+	seen := &Record{Status: StatusFlagged, UserName: "seen"}
+	seen.Acknowledge("manager", now)
+	unread := &Record{Status: StatusFlagged, UserName: "unread"}
+	forceEnded := &Record{Status: StatusForceEnded, UserName: "force-ended"}
+	records := []*Record{
+		seen,
+		unread,
+		forceEnded,
+		{Status: StatusReconciled},
+		{Status: StatusClosed},
+	}
+
+	alerts := Summarize(records)
+	if alerts.Unread != 2 || alerts.Flagged != 2 || alerts.AwaitingReconciliation != 1 {
+		t.Errorf("Expected 2 unread, 2 flagged and 1 to reconcile, got %+v", alerts)
+	}
+	if len(alerts.Records) != 3 || alerts.Records[0] != unread || alerts.Records[1] != forceEnded || alerts.Records[2] != seen {
+		t.Errorf("Expected the unread records first, got %+v", alerts.Records)
+	}
+
+	// The first acknowledgement is kept
+	seen.Acknowledge("other", now.Add(time.Hour))
+	if seen.AcknowledgedBy != "manager" || !seen.AcknowledgedAt.Equal(now) {
+		t.Errorf("Expected the first acknowledgement kept, got %s %v", seen.AcknowledgedBy, seen.AcknowledgedAt)
+	}
+
+	if empty := Summarize(nil); empty.Records == nil || empty.Unread != 0 {
+		t.Errorf("Expected no alerts, got %+v", empty)
+	}
+}
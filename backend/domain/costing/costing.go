@@ -0,0 +1,130 @@
+package costing
+
+import (
+	"math"
+	"time"
+
+	"cafe-pos/backend/domain/ingredient"
+	"cafe-pos/backend/domain/menu"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultTargetMarginPercent is the gross margin below which items are flagged
+const DefaultTargetMarginPercent = 70.0
+
+// LineCost is the cost of one recipe line
+type LineCost struct {
+	Ingredient     string              `json:"ingredient"`
+	Quantity       float64             `json:"quantity"`
+	Unit           string              `json:"unit"`
+	IngredientUnit ingredient.UnitType `json:"ingredient_unit,omitempty"`
+	CostPerUnit    float64             `json:"cost_per_unit"`
+	Cost           float64             `json:"cost"`
+	// Prepared is set when the ingredient is made in-house and costed from its own recipe
+	Prepared bool `json:"prepared,omitempty"`
+	// Problem explains why the line could not be costed (unknown ingredient, unit mismatch)
+	Problem string `json:"problem,omitempty"`
+}
+
+// ItemCost is the theoretical cost and margin of a menu item
+type ItemCost struct {
+	MenuItemID          primitive.ObjectID `json:"menu_item_id"`
+	Name                string             `json:"name"`
+	Category            string             `json:"category"`
+	Price               float64            `json:"price"`
+	Cost                float64            `json:"cost"`
+	FoodCostPercent     float64            `json:"food_cost_percent"`
+	GrossMargin         float64            `json:"gross_margin"`
+	GrossMarginPercent  float64            `json:"gross_margin_percent"`
+	TargetMarginPercent float64            `json:"target_margin_percent"`
+	BelowTarget         bool               `json:"below_target"`
+	// Complete is false when the item has no recipe or some lines could not be costed,
+	// in which case Cost is understated
+	Complete     bool       `json:"complete"`
+	Lines        []LineCost `json:"lines"`
+	CalculatedAt time.Time  `json:"calculated_at"`
+}
+
+// Calculate computes the theoretical cost of a menu item from its recipe.
+// ingredients maps menu.StockKey of ingredient names to inventory ingredients.
+// Prepared items are costed by rolling up the current costs of their recipe ingredients.
+func Calculate(item *menu.MenuItem, ingredients map[string]*ingredient.Ingredient, targetMarginPercent float64, now time.Time) *ItemCost {
+	c := &ItemCost{
+		MenuItemID:          item.ID,
+		Name:                item.Name,
+		Category:            item.Category,
+		Price:               item.Price,
+		TargetMarginPercent: targetMarginPercent,
+		Complete:            len(item.Ingredients) > 0,
+		Lines:               []LineCost{},
+		CalculatedAt:        now,
+	}
+
+	var byID map[primitive.ObjectID]*ingredient.Ingredient
+	for _, line := range item.Ingredients {
+		lc := LineCost{
+			Ingredient: line.Name,
+			Quantity:   line.Quantity,
+			Unit:       line.Unit,
+		}
+
+		ing, ok := ingredients[menu.StockKey(line.Name)]
+		if !ok {
+			lc.Problem = "ingredient not found in inventory"
+		} else {
+			lc.IngredientUnit = ing.Unit
+			lc.CostPerUnit = ing.CostPerUnit
+			if ing.IsPrepared() {
+				if byID == nil {
+					byID = make(map[primitive.ObjectID]*ingredient.Ingredient, len(ingredients))
+					for _, i := range ingredients {
+						byID[i.ID] = i
+					}
+				}
+				// Falls back to the cost of produced stock if the recipe cannot be costed
+				if cost, ok := ing.RecipeUnitCost(byID); ok {
+					lc.CostPerUnit = round2(cost)
+					lc.Prepared = true
+				}
+			}
+			qty, ok := ingredient.ConvertWith(line.Quantity, line.Unit, string(ing.Unit), ing.Conversions)
+			if ok {
+				lc.Cost = round2(qty * lc.CostPerUnit)
+			} else {
+				lc.Problem = "cannot convert " + line.Unit + " to " + string(ing.Unit)
+			}
+		}
+
+		if lc.Problem != "" {
+			c.Complete = false
+		}
+		c.Cost += lc.Cost
+		c.Lines = append(c.Lines, lc)
+	}
+
+	c.Cost = round2(c.Cost)
+	c.GrossMargin = round2(item.Price - c.Cost)
+	if item.Price > 0 {
+		c.FoodCostPercent = round2(c.Cost / item.Price * 100)
+		c.GrossMarginPercent = round2(100 - c.Cost/item.Price*100)
+	}
+	c.BelowTarget = item.Price <= 0 || c.GrossMarginPercent < targetMarginPercent
+
+	return c
+}
+
+// Snapshot returns the summary stored on the menu item
+func (c *ItemCost) Snapshot() *menu.CostSnapshot {
+	return &menu.CostSnapshot{
+		Cost:               c.Cost,
+		FoodCostPercent:    c.FoodCostPercent,
+		GrossMarginPercent: c.GrossMarginPercent,
+		BelowTarget:        c.BelowTarget,
+		Complete:           c.Complete,
+		CalculatedAt:       c.CalculatedAt,
+	}
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
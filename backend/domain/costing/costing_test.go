@@ -0,0 +1,127 @@
+package costing
+
+import (
+	"testing"
+	"time"
+
+	"cafe-pos/backend/domain/ingredient"
+	"cafe-pos/backend/domain/menu"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func inventory() map[string]*ingredient.Ingredient {
+	return map[string]*ingredient.Ingredient{
+		menu.StockKey("Cà phê bột"): {Name: "Cà phê bột", Unit: ingredient.UnitKilogram, CostPerUnit: 300000},
+		menu.StockKey("Sữa tươi"):   {Name: "Sữa tươi", Unit: ingredient.UnitLiter, CostPerUnit: 40000},
+	}
+}
+
+// TestCalculate tests recipe cost with unit conversion and margin against the target
+func TestCalculate(t *testing.T) {
+	item := &menu.MenuItem{
+		Name:  "Cà phê sữa",
+		Price: 30000,
+		Ingredients: []menu.Ingredient{
+			{Name: "Cà phê bột", Quantity: 20, Unit: "gram"},
+			{Name: "Sữa tươi", Quantity: 100, Unit: "ml"},
+		},
+	}
+
+	c := Calculate(item, inventory(), 70, time.Now())
+
+	// 0.02kg * 300000 + 0.1L * 40000
+	if c.Cost != 10000 {
+		t.Errorf("Expected cost 10000, got %v", c.Cost)
+	}
+	if c.FoodCostPercent != 33.33 {
+		t.Errorf("Expected food cost 33.33%%, got %v", c.FoodCostPercent)
+	}
+	if c.GrossMargin != 20000 || c.GrossMarginPercent != 66.67 {
+		t.Errorf("Expected margin 20000 (66.67%%), got %v (%v%%)", c.GrossMargin, c.GrossMarginPercent)
+	}
+	if !c.BelowTarget {
+		t.Error("Expected item to be below a 70% target")
+	}
+	if !c.Complete {
+		t.Error("Expected costing to be complete")
+	}
+
+	if c := Calculate(item, inventory(), 60, time.Now()); c.BelowTarget {
+		t.Error("Expected item to meet a 60% target")
+	}
+}
+
+// TestCalculateIncomplete tests recipes that cannot be fully costed
+func TestCalculateIncomplete(t *testing.T) {
+	tests := []struct {
+		name        string
+		ingredients []menu.Ingredient
+		problems    int
+	}{
+		{
+			name:        "Unknown ingredient",
+			ingredients: []menu.Ingredient{{Name: "Đường", Quantity: 10, Unit: "g"}},
+			problems:    1,
+		},
+		{
+			name:        "Incompatible units",
+			ingredients: []menu.Ingredient{{Name: "Sữa tươi", Quantity: 1, Unit: "piece"}},
+			problems:    1,
+		},
+		{
+			name:        "No recipe",
+			ingredients: nil,
+			problems:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := &menu.MenuItem{Name: "Test", Price: 20000, Ingredients: tt.ingredients}
+			c := Calculate(item, inventory(), 70, time.Now())
+			if c.Complete {
+				t.Error("Expected costing to be incomplete")
+			}
+			problems := 0
+			for _, line := range c.Lines {
+				if line.Problem != "" {
+					problems++
+				}
+			}
+			if problems != tt.problems {
+				t.Errorf("Expected %d problem lines, got %d", tt.problems, problems)
+			}
+		})
+	}
+}
+
+// TestCalculatePreparedItem tests that prepared items are costed from their own recipe
+func TestCalculatePreparedItem(t *testing.T) {
+	ingredients := inventory()
+	coffee := ingredients[menu.StockKey("Cà phê bột")]
+	coffee.ID = primitive.NewObjectID()
+	water := &ingredient.Ingredient{ID: primitive.NewObjectID(), Name: "Nước lọc", Unit: ingredient.UnitLiter, CostPerUnit: 1000}
+	coldBrew := &ingredient.Ingredient{
+		ID: primitive.NewObjectID(), Name: "Cold brew", Unit: ingredient.UnitLiter, CostPerUnit: 50000,
+		Recipe: &ingredient.PreparedRecipe{Yield: 5, Lines: []ingredient.RecipeLine{
+			{IngredientID: coffee.ID, Quantity: 500, Unit: "g"},
+			{IngredientID: water.ID, Quantity: 5, Unit: "L"},
+		}},
+	}
+	ingredients[menu.StockKey(water.Name)] = water
+	ingredients[menu.StockKey(coldBrew.Name)] = coldBrew
+
+	item := &menu.MenuItem{Name: "Cold brew sữa", Price: 45000, Ingredients: []menu.Ingredient{
+		{Name: "Cold brew", Quantity: 200, Unit: "ml"},
+		{Name: "Sữa tươi", Quantity: 50, Unit: "ml"},
+	}}
+	c := Calculate(item, ingredients, 70, time.Now())
+
+	// Cold brew: (0.5kg * 300000 + 5L * 1000) / 5 = 31000 per L, not the 50000 of produced stock
+	if !c.Lines[0].Prepared || c.Lines[0].CostPerUnit != 31000 || c.Lines[0].Cost != 6200 {
+		t.Errorf("Expected cold brew rolled up to 31000 per L, got %+v", c.Lines[0])
+	}
+	if c.Cost != 8200 || !c.Complete {
+		t.Errorf("Expected complete cost 8200, got %v (%v)", c.Cost, c.Complete)
+	}
+}
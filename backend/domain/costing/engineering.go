@@ -0,0 +1,173 @@
+package costing
+
+import (
+	"sort"
+	"time"
+
+	"cafe-pos/backend/domain/ingredient"
+	"cafe-pos/backend/domain/menu"
+	"cafe-pos/backend/domain/order"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Classification is the menu engineering class of an item (Kasavana & Smith)
+type Classification string
+
+const (
+	ClassStar      Classification = "STAR"      // popular and profitable: keep
+	ClassPlowhorse Classification = "PLOWHORSE" // popular, low margin: reprice or re-cost
+	ClassPuzzle    Classification = "PUZZLE"    // profitable, rarely ordered: promote
+	ClassDog       Classification = "DOG"       // neither: candidate to drop
+)
+
+// PopularityFactor is the share of an even menu mix an item needs to count as popular.
+// With N items in a category an item is popular when its mix is at least 70% of 1/N.
+const PopularityFactor = 0.7
+
+// EngineeringItem is the sales and margin of one menu item over a period
+type EngineeringItem struct {
+	MenuItemID   primitive.ObjectID `json:"menu_item_id"`
+	Name         string             `json:"name"`
+	Category     string             `json:"category"`
+	QuantitySold int                `json:"quantity_sold"`
+	Revenue      float64            `json:"revenue"`
+	// AveragePrice is revenue per portion sold, or the menu price when nothing was sold
+	AveragePrice float64 `json:"average_price"`
+	UnitCost     float64 `json:"unit_cost"`
+	// UnitMargin is the contribution margin per portion (average price - unit cost)
+	UnitMargin        float64        `json:"unit_margin"`
+	TotalMargin       float64        `json:"total_margin"`
+	MenuMixPercent    float64        `json:"menu_mix_percent"`
+	HighPopularity    bool           `json:"high_popularity"`
+	HighProfitability bool           `json:"high_profitability"`
+	Classification    Classification `json:"classification"`
+	// CostComplete is false when the recipe could not be fully costed or the item is no longer on the menu
+	CostComplete bool `json:"cost_complete"`
+}
+
+// CategoryAverages are the per-category figures the classification is based on
+type CategoryAverages struct {
+	Category     string  `json:"category"`
+	ItemCount    int     `json:"item_count"`
+	QuantitySold int     `json:"quantity_sold"`
+	Revenue      float64 `json:"revenue"`
+	TotalMargin  float64 `json:"total_margin"`
+	// AverageUnitMargin is the sales-weighted contribution margin per portion
+	AverageUnitMargin float64 `json:"average_unit_margin"`
+	// PopularityThreshold is the minimum menu mix percentage of a popular item
+	PopularityThreshold float64 `json:"popularity_threshold"`
+	Stars               int     `json:"stars"`
+	Plowhorses          int     `json:"plowhorses"`
+	Puzzles             int     `json:"puzzles"`
+	Dogs                int     `json:"dogs"`
+}
+
+// CountsAsSale reports whether the items of an order were sold. Unpaid, cancelled
+// and refunded orders are left out.
+func CountsAsSale(o *order.Order) bool {
+	switch o.Status {
+	case order.StatusCreated, order.StatusCancelled, order.StatusRefunded:
+		return false
+	}
+	return true
+}
+
+// BuildEngineering aggregates order lines per menu item and costs each item from its recipe.
+// Every current menu item is included, unsold items with zero quantity. Items sold but since
+// removed from the menu are included with an unknown cost.
+func BuildEngineering(items []*menu.MenuItem, orders []*order.Order, ingredients map[string]*ingredient.Ingredient) []*EngineeringItem {
+	byID := make(map[primitive.ObjectID]*EngineeringItem)
+	var result []*EngineeringItem
+
+	for _, item := range items {
+		cost := Calculate(item, ingredients, 0, time.Time{})
+		e := &EngineeringItem{
+			MenuItemID:   item.ID,
+			Name:         item.Name,
+			Category:     item.Category,
+			AveragePrice: item.Price,
+			UnitCost:     cost.Cost,
+			CostComplete: cost.Complete,
+		}
+		byID[item.ID] = e
+		result = append(result, e)
+	}
+
+	for _, o := range orders {
+		if !CountsAsSale(o) {
+			continue
+		}
+		for _, line := range o.Items {
+			e, ok := byID[line.MenuItemID]
+			if !ok {
+				e = &EngineeringItem{MenuItemID: line.MenuItemID, Name: line.Name}
+				byID[line.MenuItemID] = e
+				result = append(result, e)
+			}
+			e.QuantitySold += line.Quantity
+			e.Revenue += line.Subtotal
+		}
+	}
+
+	for _, e := range result {
+		if e.QuantitySold > 0 {
+			e.AveragePrice = round2(e.Revenue / float64(e.QuantitySold))
+		}
+		e.Revenue = round2(e.Revenue)
+		e.UnitMargin = round2(e.AveragePrice - e.UnitCost)
+		e.TotalMargin = round2(e.UnitMargin * float64(e.QuantitySold))
+	}
+	return result
+}
+
+// Classify sets the menu mix and classification of each item, comparing items with the
+// others in the same category, and returns the category averages sorted by category name
+func Classify(items []*EngineeringItem) []CategoryAverages {
+	groups := make(map[string][]*EngineeringItem)
+	for _, e := range items {
+		groups[e.Category] = append(groups[e.Category], e)
+	}
+
+	averages := make([]CategoryAverages, 0, len(groups))
+	for category, group := range groups {
+		avg := CategoryAverages{Category: category, ItemCount: len(group)}
+		for _, e := range group {
+			avg.QuantitySold += e.QuantitySold
+			avg.Revenue += e.Revenue
+			avg.TotalMargin += e.TotalMargin
+		}
+		avg.Revenue = round2(avg.Revenue)
+		avg.TotalMargin = round2(avg.TotalMargin)
+		if avg.QuantitySold > 0 {
+			avg.AverageUnitMargin = round2(avg.TotalMargin / float64(avg.QuantitySold))
+		}
+		avg.PopularityThreshold = round2(100 / float64(len(group)) * PopularityFactor)
+
+		for _, e := range group {
+			if avg.QuantitySold > 0 {
+				e.MenuMixPercent = round2(float64(e.QuantitySold) / float64(avg.QuantitySold) * 100)
+			}
+			e.HighPopularity = avg.QuantitySold > 0 && e.MenuMixPercent >= avg.PopularityThreshold
+			e.HighProfitability = e.UnitMargin >= avg.AverageUnitMargin
+
+			switch {
+			case e.HighPopularity && e.HighProfitability:
+				e.Classification = ClassStar
+				avg.Stars++
+			case e.HighPopularity:
+				e.Classification = ClassPlowhorse
+				avg.Plowhorses++
+			case e.HighProfitability:
+				e.Classification = ClassPuzzle
+				avg.Puzzles++
+			default:
+				e.Classification = ClassDog
+				avg.Dogs++
+			}
+		}
+		averages = append(averages, avg)
+	}
+
+	sort.Slice(averages, func(i, j int) bool { return averages[i].Category < averages[j].Category })
+	return averages
+}
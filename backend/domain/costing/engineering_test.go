@@ -0,0 +1,84 @@
+package costing
+
+import (
+	"testing"
+
+	"cafe-pos/backend/domain/menu"
+	"cafe-pos/backend/domain/order"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestClassify tests the menu engineering quadrants within a category
+func TestClassify(t *testing.T) {
+	// Average unit margin: (100*20000 + 60*10000 + 10*25000 + 30*5000) / 200 = 15000
+	// Popularity threshold: 100 / 4 * 0.7 = 17.5%
+	items := []*EngineeringItem{
+		{Name: "Star", Category: "Cà phê", QuantitySold: 100, UnitMargin: 20000, TotalMargin: 2000000},
+		{Name: "Plowhorse", Category: "Cà phê", QuantitySold: 60, UnitMargin: 10000, TotalMargin: 600000},
+		{Name: "Puzzle", Category: "Cà phê", QuantitySold: 10, UnitMargin: 25000, TotalMargin: 250000},
+		{Name: "Dog", Category: "Cà phê", QuantitySold: 30, UnitMargin: 5000, TotalMargin: 150000},
+		{Name: "Only tea", Category: "Trà", QuantitySold: 5, UnitMargin: 1000, TotalMargin: 5000},
+	}
+
+	averages := Classify(items)
+
+	if len(averages) != 2 || averages[0].Category != "Cà phê" {
+		t.Fatalf("Expected 2 categories sorted by name, got %+v", averages)
+	}
+	if averages[0].AverageUnitMargin != 15000 {
+		t.Errorf("Expected average unit margin 15000, got %v", averages[0].AverageUnitMargin)
+	}
+	if averages[0].PopularityThreshold != 17.5 {
+		t.Errorf("Expected popularity threshold 17.5, got %v", averages[0].PopularityThreshold)
+	}
+
+	expected := []Classification{ClassStar, ClassPlowhorse, ClassPuzzle, ClassDog, ClassStar}
+	for i, e := range items {
+		if e.Classification != expected[i] {
+			t.Errorf("%s: expected %s, got %s (mix %v%%)", e.Name, expected[i], e.Classification, e.MenuMixPercent)
+		}
+	}
+}
+
+// TestBuildEngineering tests aggregation of order lines with recipe cost
+func TestBuildEngineering(t *testing.T) {
+	latte := &menu.MenuItem{
+		ID:       primitive.NewObjectID(),
+		Name:     "Cà phê sữa",
+		Category: "Cà phê",
+		Price:    30000,
+		Ingredients: []menu.Ingredient{
+			{Name: "Cà phê bột", Quantity: 20, Unit: "g"},
+		},
+	}
+	unsold := &menu.MenuItem{ID: primitive.NewObjectID(), Name: "Bạc xỉu", Category: "Cà phê", Price: 35000}
+	removedID := primitive.NewObjectID()
+
+	orders := []*order.Order{
+		{Status: order.StatusServed, Items: []order.OrderItem{
+			{MenuItemID: latte.ID, Quantity: 2, Subtotal: 60000},
+			{MenuItemID: removedID, Name: "Cacao", Quantity: 1, Subtotal: 25000},
+		}},
+		{Status: order.StatusPaid, Items: []order.OrderItem{{MenuItemID: latte.ID, Quantity: 1, Subtotal: 27000}}},
+		{Status: order.StatusCancelled, Items: []order.OrderItem{{MenuItemID: latte.ID, Quantity: 5, Subtotal: 150000}}},
+	}
+
+	result := BuildEngineering([]*menu.MenuItem{latte, unsold}, orders, inventory())
+	if len(result) != 3 {
+		t.Fatalf("Expected 3 items, got %d", len(result))
+	}
+
+	e := result[0]
+	if e.QuantitySold != 3 || e.Revenue != 87000 || e.AveragePrice != 29000 {
+		t.Errorf("Expected 3 sold for 87000 (29000 each), got %d for %v (%v)", e.QuantitySold, e.Revenue, e.AveragePrice)
+	}
+	if e.UnitCost != 6000 || e.UnitMargin != 23000 || e.TotalMargin != 69000 {
+		t.Errorf("Expected cost 6000 and margin 23000 (69000 total), got %v, %v, %v", e.UnitCost, e.UnitMargin, e.TotalMargin)
+	}
+	if result[1].QuantitySold != 0 || result[1].AveragePrice != 35000 {
+		t.Errorf("Expected unsold item at menu price, got %+v", result[1])
+	}
+	if result[2].Name != "Cacao" || result[2].CostComplete {
+		t.Errorf("Expected removed item with incomplete cost, got %+v", result[2])
+	}
+}
@@ -0,0 +1,161 @@
+package costing
+
+import (
+	"math"
+	"sort"
+
+	"cafe-pos/backend/domain/ingredient"
+	"cafe-pos/backend/domain/menu"
+	"cafe-pos/backend/domain/order"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultUsageTolerancePercent is the unexplained usage, as a percentage of theoretical usage,
+// above which an ingredient is flagged as shrinkage
+const DefaultUsageTolerancePercent = 5.0
+
+// UsageLine compares the theoretical and actual usage of an ingredient over a period.
+// Quantities are in the ingredient stock unit.
+type UsageLine struct {
+	IngredientID primitive.ObjectID  `json:"ingredient_id"`
+	Name         string              `json:"name"`
+	Category     string              `json:"category"`
+	Unit         ingredient.UnitType `json:"unit"`
+	OpeningStock float64             `json:"opening_stock"`
+	Purchases    float64             `json:"purchases"`
+	ClosingStock float64             `json:"closing_stock"`
+	// ActualUsage is opening stock + purchases - closing stock
+	ActualUsage float64 `json:"actual_usage"`
+	// TheoreticalUsage is what the recipes of the items sold should have used
+	TheoreticalUsage float64 `json:"theoretical_usage"`
+	RecordedWaste    float64 `json:"recorded_waste"`
+	// Variance is the usage not explained by sales or recorded waste, positive when stock is missing
+	Variance         float64 `json:"variance"`
+	VariancePercent  float64 `json:"variance_percent"` // of theoretical usage
+	UnitCost         float64 `json:"unit_cost"`
+	ActualValue      float64 `json:"actual_value"`
+	TheoreticalValue float64 `json:"theoretical_value"`
+	WasteValue       float64 `json:"waste_value"`
+	VarianceValue    float64 `json:"variance_value"`
+	Shrinkage        bool    `json:"shrinkage"`
+}
+
+// TheoreticalUsage sums the recipe quantities of the items sold in the orders per ingredient,
+// in the ingredient stock unit. Recipe lines that cannot be matched to an ingredient or
+// converted to its unit are returned as problems, as are sold items no longer on the menu.
+func TheoreticalUsage(items []*menu.MenuItem, orders []*order.Order, ingredients map[string]*ingredient.Ingredient) (map[primitive.ObjectID]float64, []string) {
+	sold := make(map[primitive.ObjectID]int)
+	names := make(map[primitive.ObjectID]string)
+	for _, o := range orders {
+		if !CountsAsSale(o) {
+			continue
+		}
+		for _, line := range o.Items {
+			sold[line.MenuItemID] += line.Quantity
+			names[line.MenuItemID] = line.Name
+		}
+	}
+
+	usage := make(map[primitive.ObjectID]float64)
+	problems := []string{}
+	byID := make(map[primitive.ObjectID]*menu.MenuItem, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+
+	for id, quantity := range sold {
+		item, ok := byID[id]
+		if !ok {
+			problems = append(problems, names[id]+": no longer on the menu")
+			continue
+		}
+		for _, line := range item.Ingredients {
+			ing, ok := ingredients[menu.StockKey(line.Name)]
+			if !ok {
+				problems = append(problems, item.Name+": ingredient "+line.Name+" not found in inventory")
+				continue
+			}
+			qty, ok := ingredient.ConvertWith(line.Quantity, line.Unit, string(ing.Unit), ing.Conversions)
+			if !ok {
+				problems = append(problems, item.Name+": cannot convert "+line.Unit+" to "+string(ing.Unit)+" for "+ing.Name)
+				continue
+			}
+			usage[ing.ID] += qty * float64(quantity)
+		}
+	}
+	sort.Strings(problems)
+	return usage, problems
+}
+
+// StockMovements sums the stock added by purchases, manual additions and production of
+// prepared items, and the recorded waste, per ingredient from the stock history of the period
+func StockMovements(history []*ingredient.StockHistory) (purchases, waste map[primitive.ObjectID]float64) {
+	purchases = make(map[primitive.ObjectID]float64)
+	waste = make(map[primitive.ObjectID]float64)
+	for _, h := range history {
+		switch {
+		case h.Type == ingredient.TransactionPurchase,
+			h.Type == ingredient.TransactionAdjustment && h.Quantity > 0,
+			h.Type == ingredient.TransactionProduction && h.Quantity > 0:
+			purchases[h.IngredientID] += h.Quantity
+		case h.Type == ingredient.TransactionWaste:
+			waste[h.IngredientID] -= h.Quantity
+		}
+	}
+	return purchases, waste
+}
+
+// ProductionUsage sums the ingredients consumed to make prepared items per ingredient. It is
+// part of theoretical usage: the recipe of the prepared item accounts for it.
+func ProductionUsage(history []*ingredient.StockHistory) map[primitive.ObjectID]float64 {
+	usage := make(map[primitive.ObjectID]float64)
+	for _, h := range history {
+		if h.Type == ingredient.TransactionProduction && h.Quantity < 0 {
+			usage[h.IngredientID] -= h.Quantity
+		}
+	}
+	return usage
+}
+
+// BuildUsageVariance compares actual and theoretical usage for every ingredient counted in
+// both the opening and the closing stock count, biggest loss in money first
+func BuildUsageVariance(ingredients []*ingredient.Ingredient, opening, closing, purchases, waste, theoretical map[primitive.ObjectID]float64, tolerancePercent float64) []*UsageLine {
+	lines := []*UsageLine{}
+	for _, ing := range ingredients {
+		openQty, ok := opening[ing.ID]
+		if !ok {
+			continue
+		}
+		closeQty, ok := closing[ing.ID]
+		if !ok {
+			continue
+		}
+
+		l := &UsageLine{
+			IngredientID:     ing.ID,
+			Name:             ing.Name,
+			Category:         ing.Category,
+			Unit:             ing.Unit,
+			OpeningStock:     openQty,
+			Purchases:        round2(purchases[ing.ID]),
+			ClosingStock:     closeQty,
+			TheoreticalUsage: round2(theoretical[ing.ID]),
+			RecordedWaste:    round2(waste[ing.ID]),
+			UnitCost:         ing.CostPerUnit,
+		}
+		l.ActualUsage = round2(openQty + purchases[ing.ID] - closeQty)
+		l.Variance = round2(l.ActualUsage - l.TheoreticalUsage - l.RecordedWaste)
+		if l.TheoreticalUsage > 0 {
+			l.VariancePercent = round2(l.Variance / l.TheoreticalUsage * 100)
+		}
+		l.ActualValue = math.Round(l.ActualUsage * l.UnitCost)
+		l.TheoreticalValue = math.Round(l.TheoreticalUsage * l.UnitCost)
+		l.WasteValue = math.Round(l.RecordedWaste * l.UnitCost)
+		l.VarianceValue = math.Round(l.Variance * l.UnitCost)
+		l.Shrinkage = l.Variance > 0 && (l.TheoreticalUsage == 0 || l.VariancePercent > tolerancePercent)
+		lines = append(lines, l)
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].VarianceValue > lines[j].VarianceValue })
+	return lines
+}
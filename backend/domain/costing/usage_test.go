@@ -0,0 +1,84 @@
+package costing
+
+import (
+	"testing"
+
+	"cafe-pos/backend/domain/ingredient"
+	"cafe-pos/backend/domain/menu"
+	"cafe-pos/backend/domain/order"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestUsageVariance tests theoretical usage from sales against actual usage between counts
+func TestUsageVariance(t *testing.T) {
+	milk := &ingredient.Ingredient{ID: primitive.NewObjectID(), Name: "Sữa tươi", Unit: ingredient.UnitLiter, CostPerUnit: 30000}
+	coffee := &ingredient.Ingredient{ID: primitive.NewObjectID(), Name: "Cà phê bột", Unit: ingredient.UnitKilogram, CostPerUnit: 300000}
+	latte := &menu.MenuItem{ID: primitive.NewObjectID(), Name: "Latte", Ingredients: []menu.Ingredient{
+		{Name: "Sữa tươi", Quantity: 200, Unit: "ml"},
+		{Name: "cà phê bột", Quantity: 20, Unit: "g"},
+	}}
+	removed := primitive.NewObjectID()
+
+	orders := []*order.Order{
+		{Status: order.StatusServed, Items: []order.OrderItem{{MenuItemID: latte.ID, Name: "Latte", Quantity: 30}}},
+		{Status: order.StatusPaid, Items: []order.OrderItem{{MenuItemID: latte.ID, Name: "Latte", Quantity: 20}, {MenuItemID: removed, Name: "Bạc xỉu", Quantity: 1}}},
+		{Status: order.StatusCancelled, Items: []order.OrderItem{{MenuItemID: latte.ID, Name: "Latte", Quantity: 100}}},
+	}
+	ingredients := map[string]*ingredient.Ingredient{menu.StockKey(milk.Name): milk, menu.StockKey(coffee.Name): coffee}
+
+	theoretical, problems := TheoreticalUsage([]*menu.MenuItem{latte}, orders, ingredients)
+	// 50 lattes: 10 L milk, 1 kg coffee
+	if theoretical[milk.ID] != 10 || theoretical[coffee.ID] != 1 {
+		t.Fatalf("Expected 10 L milk and 1 kg coffee, got %v and %v", theoretical[milk.ID], theoretical[coffee.ID])
+	}
+	if len(problems) != 1 {
+		t.Errorf("Expected the removed item reported, got %v", problems)
+	}
+
+	history := []*ingredient.StockHistory{
+		{IngredientID: milk.ID, Type: ingredient.TransactionPurchase, Quantity: 12},
+		{IngredientID: milk.ID, Type: ingredient.TransactionWaste, Quantity: -1},
+		{IngredientID: coffee.ID, Type: ingredient.TransactionAdjustment, Quantity: 0.5},
+		{IngredientID: coffee.ID, Type: ingredient.TransactionAdjustment, Quantity: -0.2},
+	}
+	purchases, waste := StockMovements(history)
+	if purchases[milk.ID] != 12 || waste[milk.ID] != 1 || purchases[coffee.ID] != 0.5 {
+		t.Fatalf("Unexpected movements: purchases %v, waste %v", purchases, waste)
+	}
+
+	opening := map[primitive.ObjectID]float64{milk.ID: 5, coffee.ID: 2}
+	closing := map[primitive.ObjectID]float64{milk.ID: 4, coffee.ID: 1.48}
+	lines := BuildUsageVariance([]*ingredient.Ingredient{milk, coffee}, opening, closing, purchases, waste, theoretical, DefaultUsageTolerancePercent)
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(lines))
+	}
+
+	// Milk: 5 + 12 - 4 = 13 used, 10 sold, 1 wasted, 2 L missing (20%)
+	m := lines[0]
+	if m.Name != "Sữa tươi" || m.ActualUsage != 13 || m.Variance != 2 || m.VariancePercent != 20 || m.VarianceValue != 60000 || !m.Shrinkage {
+		t.Errorf("Unexpected milk line: %+v", m)
+	}
+	// Coffee: 2 + 0.5 - 1.48 = 1.02 used, 1 sold, 2% over, within tolerance
+	c := lines[1]
+	if c.ActualUsage != 1.02 || c.Variance != 0.02 || c.Shrinkage {
+		t.Errorf("Unexpected coffee line: %+v", c)
+	}
+}
+
+// TestProductionMovements tests that making a prepared item adds it to stock and counts the
+// ingredients used as theoretical usage
+func TestProductionMovements(t *testing.T) {
+	sugar, syrup := primitive.NewObjectID(), primitive.NewObjectID()
+	history := []*ingredient.StockHistory{
+		{IngredientID: sugar, Type: ingredient.TransactionProduction, Quantity: -1},
+		{IngredientID: syrup, Type: ingredient.TransactionProduction, Quantity: 1.5},
+	}
+
+	purchases, _ := StockMovements(history)
+	if purchases[syrup] != 1.5 || purchases[sugar] != 0 {
+		t.Errorf("Expected produced syrup as stock added, got %v", purchases)
+	}
+	if usage := ProductionUsage(history); usage[sugar] != 1 || usage[syrup] != 0 {
+		t.Errorf("Expected 1 kg sugar used in production, got %v", usage)
+	}
+}
@@ -23,10 +23,12 @@ const (
 
 // Source Type Constants for auto-tracking
 const (
-	SourceTypeIngredient  = "ingredient"
-	SourceTypeFacility    = "facility"
-	SourceTypeMaintenance = "maintenance"
-	SourceTypeManual      = "manual" // For manually created expenses
+	SourceTypeIngredient    = "ingredient"
+	SourceTypeFacility      = "facility"
+	SourceTypeMaintenance   = "maintenance"
+	SourceTypePurchaseOrder = "purchase_order"
+	SourceTypePayroll       = "payroll"
+	SourceTypeManual        = "manual" // For manually created expenses
 )
 
 type Expense struct {
@@ -37,6 +39,7 @@ type Expense struct {
 	Description   string             `bson:"description" json:"description"`
 	PaymentMethod string             `bson:"payment_method" json:"payment_method"`
 	Vendor        string             `bson:"vendor,omitempty" json:"vendor,omitempty"`
+	SupplierID    *primitive.ObjectID `bson:"supplier_id,omitempty" json:"supplier_id,omitempty"`
 	Notes         string             `bson:"notes,omitempty" json:"notes,omitempty"`
 	
 	// Source tracking for auto-generated expenses
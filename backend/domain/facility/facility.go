@@ -15,6 +15,7 @@ type Facility struct {
 	PurchaseDate time.Time         `json:"purchase_date" bson:"purchase_date"`
 	Cost        float64            `json:"cost" bson:"cost"`
 	Supplier    string             `json:"supplier" bson:"supplier"`
+	SupplierID  *primitive.ObjectID `json:"supplier_id,omitempty" bson:"supplier_id"`
 	Notes       string             `json:"notes" bson:"notes"`
 	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
 	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
@@ -27,6 +28,7 @@ type MaintenanceRecord struct {
 	Description string             `json:"description" bson:"description"`
 	Cost        float64            `json:"cost" bson:"cost"`
 	Vendor      string             `json:"vendor" bson:"vendor"`
+	VendorID    *primitive.ObjectID `json:"vendor_id,omitempty" bson:"vendor_id"`
 	Date        time.Time          `json:"date" bson:"date"`
 	UserID      primitive.ObjectID `json:"user_id" bson:"user_id"`
 	Username    string             `json:"username" bson:"username"`
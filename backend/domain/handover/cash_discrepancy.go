@@ -166,16 +166,20 @@ func (cd *CashDiscrepancy) SetManagerResolution(
 		return errors.New("manager name is required")
 	}
 
+	now := time.Now()
 	cd.ManagerID = &managerID
 	cd.ManagerName = &managerName
 	cd.ManagerApproved = &approved
 	cd.ManagerNotes = &managerNotes
-	cd.UpdatedAt = time.Now()
+	cd.UpdatedAt = now
 
-	// If approved, mark as resolved
+	// If approved, mark as resolved. Resolve only accepts pending discrepancies, so the
+	// escalated one is resolved here.
 	if approved {
 		action := "Manager approved discrepancy"
-		cd.Resolve(action)
+		cd.Status = DiscrepancyStatusResolved
+		cd.ResolutionAction = &action
+		cd.ResolvedAt = &now
 	}
 
 	return nil
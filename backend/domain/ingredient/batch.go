@@ -0,0 +1,140 @@
+package ingredient
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrBatchNotActive is returned when a batch was depleted, written off or drawn from since it
+// was read
+var ErrBatchNotActive = errors.New("batch is no longer active")
+
+type BatchStatus string
+
+const (
+	BatchActive     BatchStatus = "ACTIVE"
+	BatchDepleted   BatchStatus = "DEPLETED"
+	BatchWrittenOff BatchStatus = "WRITTEN_OFF"
+)
+
+// Batch is a lot of an ingredient received into stock. Quantities are in the ingredient stock unit.
+type Batch struct {
+	ID              primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	IngredientID    primitive.ObjectID  `bson:"ingredient_id" json:"ingredient_id"`
+	IngredientName  string              `bson:"ingredient_name" json:"ingredient_name"`
+	Unit            UnitType            `bson:"unit" json:"unit"`
+	LotNumber       string              `bson:"lot_number" json:"lot_number"`
+	ExpiryDate      *time.Time          `bson:"expiry_date,omitempty" json:"expiry_date,omitempty"` // usable until the end of this day
+	InitialQuantity float64             `bson:"initial_quantity" json:"initial_quantity"`
+	Quantity        float64             `bson:"quantity" json:"quantity"` // remaining
+	UnitCost        float64             `bson:"unit_cost" json:"unit_cost"`
+	PurchaseOrderID *primitive.ObjectID `bson:"purchase_order_id,omitempty" json:"purchase_order_id,omitempty"`
+	Status          BatchStatus         `bson:"status" json:"status"`
+	ReceivedAt      time.Time           `bson:"received_at" json:"received_at"`
+	WrittenOffAt    *time.Time          `bson:"written_off_at,omitempty" json:"written_off_at,omitempty"`
+	CreatedAt       time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+// BatchDraw is the quantity taken from one batch by a stock movement
+type BatchDraw struct {
+	BatchID   primitive.ObjectID `bson:"batch_id" json:"batch_id"`
+	LotNumber string             `bson:"lot_number" json:"lot_number"`
+	Quantity  float64            `bson:"quantity" json:"quantity"`
+}
+
+// ExpiringBatch is an active batch with the days left before it expires, negative once expired
+type ExpiringBatch struct {
+	*Batch
+	DaysLeft int `json:"days_left"`
+}
+
+type WriteOffRequest struct {
+	Reason   string `json:"reason"`
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+}
+
+// IsExpired reports whether the expiry day has passed at the given time
+func (b *Batch) IsExpired(at time.Time) bool {
+	return b.ExpiryDate != nil && !at.Before(b.ExpiryDate.AddDate(0, 0, 1))
+}
+
+// DaysLeft returns the whole days from at until the end of the expiry day.
+// Zero means the batch expires today.
+func (b *Batch) DaysLeft(at time.Time) int {
+	if b.ExpiryDate == nil {
+		return math.MaxInt32
+	}
+	return int(math.Floor(b.ExpiryDate.AddDate(0, 0, 1).Sub(at).Hours()/24 - 1e-9))
+}
+
+// SortFEFO orders batches first-expired-first-out: earliest expiry first, batches without
+// expiry last, ties by receipt time
+func SortFEFO(batches []*Batch) {
+	sort.SliceStable(batches, func(i, j int) bool {
+		a, b := batches[i], batches[j]
+		switch {
+		case a.ExpiryDate == nil && b.ExpiryDate == nil:
+			return a.ReceivedAt.Before(b.ReceivedAt)
+		case a.ExpiryDate == nil:
+			return false
+		case b.ExpiryDate == nil:
+			return true
+		case !a.ExpiryDate.Equal(*b.ExpiryDate):
+			return a.ExpiryDate.Before(*b.ExpiryDate)
+		}
+		return a.ReceivedAt.Before(b.ReceivedAt)
+	})
+}
+
+// ConsumeFEFO takes quantity from the active batches in FEFO order, reducing their remaining
+// quantity and marking emptied batches depleted. Batches expired at at are skipped, they are
+// left to be written off. Returns the draws and any quantity that could not be taken from a
+// batch (stock recorded before batches were tracked).
+func ConsumeFEFO(batches []*Batch, quantity float64, at time.Time) ([]BatchDraw, float64) {
+	SortFEFO(batches)
+
+	var draws []BatchDraw
+	for _, b := range batches {
+		if quantity <= 1e-9 {
+			break
+		}
+		if b.Status != BatchActive || b.Quantity <= 0 || b.IsExpired(at) {
+			continue
+		}
+		take := math.Min(b.Quantity, quantity)
+		b.Quantity -= take
+		if b.Quantity <= 1e-9 {
+			b.Quantity = 0
+			b.Status = BatchDepleted
+		}
+		quantity -= take
+		draws = append(draws, BatchDraw{BatchID: b.ID, LotNumber: b.LotNumber, Quantity: take})
+	}
+	if quantity < 1e-9 {
+		quantity = 0
+	}
+	return draws, quantity
+}
+
+// ExpiringWithin returns the active batches expiring within the given number of days,
+// including those already expired and not yet written off, soonest first
+func ExpiringWithin(batches []*Batch, at time.Time, days int) []ExpiringBatch {
+	SortFEFO(batches)
+
+	result := []ExpiringBatch{}
+	for _, b := range batches {
+		if b.Status != BatchActive || b.ExpiryDate == nil {
+			continue
+		}
+		if left := b.DaysLeft(at); left <= days {
+			result = append(result, ExpiringBatch{Batch: b, DaysLeft: left})
+		}
+	}
+	return result
+}
@@ -0,0 +1,82 @@
+package ingredient
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func day(offset int) *time.Time {
+	d := time.Date(2024, 6, 15, 0, 0, 0, 0, time.Local).AddDate(0, 0, offset)
+	return &d
+}
+
+// TestConsumeFEFO tests that consumption takes the earliest-expiring batch first
+func TestConsumeFEFO(t *testing.T) {
+	received := time.Date(2024, 6, 1, 8, 0, 0, 0, time.Local)
+	noExpiry := &Batch{ID: primitive.NewObjectID(), LotNumber: "C", Quantity: 5, Status: BatchActive, ReceivedAt: received}
+	late := &Batch{ID: primitive.NewObjectID(), LotNumber: "B", ExpiryDate: day(5), Quantity: 2, Status: BatchActive, ReceivedAt: received}
+	early := &Batch{ID: primitive.NewObjectID(), LotNumber: "A", ExpiryDate: day(2), Quantity: 1.5, Status: BatchActive, ReceivedAt: received.AddDate(0, 0, 3)}
+	expired := &Batch{ID: primitive.NewObjectID(), LotNumber: "X", ExpiryDate: day(-1), Quantity: 4, Status: BatchActive, ReceivedAt: received}
+	batches := []*Batch{noExpiry, late, early, expired}
+	now := time.Date(2024, 6, 15, 10, 0, 0, 0, time.Local)
+
+	draws, remaining := ConsumeFEFO(batches, 3, now)
+	if remaining != 0 {
+		t.Errorf("Expected nothing left to consume, got %v", remaining)
+	}
+	if len(draws) != 2 || draws[0].LotNumber != "A" || draws[0].Quantity != 1.5 || draws[1].LotNumber != "B" || draws[1].Quantity != 1.5 {
+		t.Fatalf("Expected 1.5 from A then 1.5 from B, got %+v", draws)
+	}
+	if early.Status != BatchDepleted || early.Quantity != 0 {
+		t.Errorf("Expected batch A depleted, got %s with %v", early.Status, early.Quantity)
+	}
+	if late.Status != BatchActive || late.Quantity != 0.5 {
+		t.Errorf("Expected batch B active with 0.5, got %s with %v", late.Status, late.Quantity)
+	}
+
+	// More than the batches hold leaves the rest for stock without a batch
+	draws, remaining = ConsumeFEFO(batches, 10, now)
+	if remaining != 4.5 {
+		t.Errorf("Expected 4.5 not taken from batches, got %v", remaining)
+	}
+	if len(draws) != 2 || draws[1].LotNumber != "C" {
+		t.Errorf("Expected draws from B then C, got %+v", draws)
+	}
+
+	// The expired batch is never drawn from, it waits to be written off
+	if expired.Status != BatchActive || expired.Quantity != 4 {
+		t.Errorf("Expected expired batch X untouched, got %s with %v", expired.Status, expired.Quantity)
+	}
+}
+
+// TestExpiry tests expiry checks and the expiring-within list
+func TestExpiry(t *testing.T) {
+	now := time.Date(2024, 6, 15, 10, 0, 0, 0, time.Local)
+	expired := &Batch{LotNumber: "OLD", ExpiryDate: day(-1), Quantity: 1, Status: BatchActive}
+	today := &Batch{LotNumber: "TODAY", ExpiryDate: day(0), Quantity: 1, Status: BatchActive}
+	soon := &Batch{LotNumber: "SOON", ExpiryDate: day(3), Quantity: 1, Status: BatchActive}
+	later := &Batch{LotNumber: "LATER", ExpiryDate: day(10), Quantity: 1, Status: BatchActive}
+	writtenOff := &Batch{LotNumber: "GONE", ExpiryDate: day(-2), Status: BatchWrittenOff}
+
+	if !expired.IsExpired(now) {
+		t.Error("Expected batch expired yesterday to be expired")
+	}
+	if today.IsExpired(now) {
+		t.Error("Expected batch expiring today to still be usable")
+	}
+	if today.DaysLeft(now) != 0 || soon.DaysLeft(now) != 3 || expired.DaysLeft(now) != -1 {
+		t.Errorf("Unexpected days left: today %d, soon %d, expired %d", today.DaysLeft(now), soon.DaysLeft(now), expired.DaysLeft(now))
+	}
+
+	list := ExpiringWithin([]*Batch{later, soon, writtenOff, today, expired}, now, 3)
+	if len(list) != 3 {
+		t.Fatalf("Expected 3 expiring batches, got %d", len(list))
+	}
+	for i, lot := range []string{"OLD", "TODAY", "SOON"} {
+		if list[i].LotNumber != lot {
+			t.Errorf("Expected %s at position %d, got %s", lot, i, list[i].LotNumber)
+		}
+	}
+}
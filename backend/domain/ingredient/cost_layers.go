@@ -0,0 +1,195 @@
+package ingredient
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CostingMethod string
+
+const (
+	// CostingWeightedAverage values stock and consumption at the average cost of the stock on hand
+	CostingWeightedAverage CostingMethod = "WEIGHTED_AVERAGE"
+	// CostingFIFO values consumption at the cost of the oldest receipts still in stock
+	CostingFIFO CostingMethod = "FIFO"
+)
+
+// ParseCostingMethod accepts WEIGHTED_AVERAGE (or WAC, AVERAGE) and FIFO, case-insensitive
+func ParseCostingMethod(s string) (CostingMethod, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "WEIGHTED_AVERAGE", "WAC", "AVERAGE":
+		return CostingWeightedAverage, nil
+	case "FIFO":
+		return CostingFIFO, nil
+	}
+	return "", fmt.Errorf("unknown costing method %q", s)
+}
+
+// CostLayer is stock received at one unit cost, in the stock unit. Quantity is what remains.
+type CostLayer struct {
+	ReceivedAt      time.Time           `bson:"received_at" json:"received_at"`
+	Quantity        float64             `bson:"quantity" json:"quantity"`
+	UnitCost        float64             `bson:"unit_cost" json:"unit_cost"`
+	PurchaseOrderID *primitive.ObjectID `bson:"purchase_order_id,omitempty" json:"purchase_order_id,omitempty"`
+}
+
+// AddStock adds quantity received at unitCost per stock unit as a new cost layer and updates
+// the effective cost per unit. A zero unitCost uses the current cost per unit.
+func (i *Ingredient) AddStock(method CostingMethod, quantity, unitCost float64, at time.Time, poID *primitive.ObjectID) {
+	if quantity <= 0 {
+		return
+	}
+	i.reconcileLayers(at)
+	if unitCost <= 0 {
+		unitCost = i.CostPerUnit
+	}
+
+	onHand := math.Max(i.Quantity, 0)
+	i.Quantity = onHand + quantity
+	i.CostLayers = append(i.CostLayers, CostLayer{ReceivedAt: at, Quantity: quantity, UnitCost: unitCost, PurchaseOrderID: poID})
+
+	if method == CostingFIFO {
+		i.CostPerUnit = i.CostLayers[0].UnitCost
+	} else {
+		i.CostPerUnit = (onHand*i.CostPerUnit + quantity*unitCost) / i.Quantity
+	}
+}
+
+// RemoveStock takes quantity out of stock, never below zero, and returns the quantity removed
+// and its cost. FIFO values it at the oldest layers, weighted average at the current average.
+func (i *Ingredient) RemoveStock(method CostingMethod, quantity float64, at time.Time) (removed, cost float64) {
+	if quantity <= 0 {
+		return 0, 0
+	}
+	i.reconcileLayers(at)
+
+	removed = math.Min(quantity, math.Max(i.Quantity, 0))
+	i.Quantity -= removed
+
+	fifoCost := 0.0
+	left := removed
+	for len(i.CostLayers) > 0 && left > 1e-9 {
+		layer := &i.CostLayers[0]
+		take := math.Min(layer.Quantity, left)
+		fifoCost += take * layer.UnitCost
+		layer.Quantity -= take
+		left -= take
+		if layer.Quantity <= 1e-9 {
+			i.CostLayers = i.CostLayers[1:]
+		}
+	}
+
+	if method == CostingFIFO {
+		cost = fifoCost + left*i.CostPerUnit
+		if len(i.CostLayers) > 0 {
+			i.CostPerUnit = i.CostLayers[0].UnitCost
+		}
+	} else {
+		cost = removed * i.CostPerUnit
+	}
+	return removed, math.Round(cost*100) / 100
+}
+
+// SetCountedStock sets the stock to a counted quantity, adding or removing the difference
+func (i *Ingredient) SetCountedStock(method CostingMethod, quantity float64, at time.Time) (change, value float64) {
+	if quantity > i.Quantity {
+		change = quantity - i.Quantity
+		i.AddStock(method, change, 0, at, nil)
+		return change, math.Round(change*i.CostPerUnit*100) / 100
+	}
+	removed, cost := i.RemoveStock(method, i.Quantity-quantity, at)
+	return -removed, -cost
+}
+
+// Revalue sets the cost of the stock on hand to a new unit cost
+func (i *Ingredient) Revalue(unitCost float64) {
+	i.CostPerUnit = unitCost
+	for j := range i.CostLayers {
+		i.CostLayers[j].UnitCost = unitCost
+	}
+}
+
+// StockValue returns the value of the stock on hand
+func (i *Ingredient) StockValue(method CostingMethod) float64 {
+	if method != CostingFIFO {
+		return math.Max(i.Quantity, 0) * i.CostPerUnit
+	}
+	value := 0.0
+	for _, l := range i.CostLayers {
+		value += l.Quantity * l.UnitCost
+	}
+	return value
+}
+
+// reconcileLayers makes the layers add up to the stock quantity. Stock recorded without layers
+// (before layers were tracked, or set directly) becomes an opening layer at the current cost;
+// layers exceeding the stock are removed oldest first.
+func (i *Ingredient) reconcileLayers(at time.Time) {
+	layered := 0.0
+	for _, l := range i.CostLayers {
+		layered += l.Quantity
+	}
+	onHand := math.Max(i.Quantity, 0)
+
+	switch {
+	case onHand-layered > 1e-9:
+		opening := CostLayer{ReceivedAt: at, Quantity: onHand - layered, UnitCost: i.CostPerUnit}
+		if len(i.CostLayers) > 0 {
+			opening.ReceivedAt = i.CostLayers[0].ReceivedAt
+		}
+		i.CostLayers = append([]CostLayer{opening}, i.CostLayers...)
+	case layered-onHand > 1e-9:
+		excess := layered - onHand
+		for len(i.CostLayers) > 0 && excess > 1e-9 {
+			take := math.Min(i.CostLayers[0].Quantity, excess)
+			i.CostLayers[0].Quantity -= take
+			excess -= take
+			if i.CostLayers[0].Quantity <= 1e-9 {
+				i.CostLayers = i.CostLayers[1:]
+			}
+		}
+	}
+}
+
+// ValuationLine is the value of the stock on hand of one ingredient
+type ValuationLine struct {
+	IngredientID primitive.ObjectID `json:"ingredient_id"`
+	Name         string             `json:"name"`
+	Category     string             `json:"category"`
+	Unit         UnitType           `json:"unit"`
+	Quantity     float64            `json:"quantity"`
+	CostPerUnit  float64            `json:"cost_per_unit"`
+	Value        float64            `json:"value"`
+	Layers       int                `json:"layers"`
+}
+
+// StockValuation is the value of all stock on hand under a costing method
+type StockValuation struct {
+	Method CostingMethod   `json:"method"`
+	Lines  []ValuationLine `json:"lines"`
+	Total  float64         `json:"total"`
+}
+
+// ValueStock values the stock on hand of the ingredients
+func ValueStock(ingredients []*Ingredient, method CostingMethod) *StockValuation {
+	v := &StockValuation{Method: method, Lines: []ValuationLine{}}
+	for _, ing := range ingredients {
+		value := math.Round(ing.StockValue(method))
+		v.Lines = append(v.Lines, ValuationLine{
+			IngredientID: ing.ID,
+			Name:         ing.Name,
+			Category:     ing.Category,
+			Unit:         ing.Unit,
+			Quantity:     ing.Quantity,
+			CostPerUnit:  ing.CostPerUnit,
+			Value:        value,
+			Layers:       len(ing.CostLayers),
+		})
+		v.Total += value
+	}
+	return v
+}
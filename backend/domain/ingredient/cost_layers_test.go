@@ -0,0 +1,84 @@
+package ingredient
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func almost(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+// TestCostingFIFO tests that consumption is valued at the oldest receipts first
+func TestCostingFIFO(t *testing.T) {
+	at := time.Date(2024, 6, 1, 8, 0, 0, 0, time.Local)
+	milk := &Ingredient{Name: "Sữa tươi", Unit: UnitLiter}
+
+	milk.AddStock(CostingFIFO, 10, 30000, at, nil)
+	milk.AddStock(CostingFIFO, 10, 36000, at.Add(time.Hour), nil)
+	if milk.Quantity != 20 || milk.CostPerUnit != 30000 || len(milk.CostLayers) != 2 {
+		t.Fatalf("Expected 20 L at 30000 in 2 layers, got %v at %v in %d", milk.Quantity, milk.CostPerUnit, len(milk.CostLayers))
+	}
+
+	removed, cost := milk.RemoveStock(CostingFIFO, 12, at)
+	// 10 L at 30000 + 2 L at 36000
+	if removed != 12 || cost != 372000 {
+		t.Errorf("Expected 12 L costing 372000, got %v costing %v", removed, cost)
+	}
+	if milk.CostPerUnit != 36000 || len(milk.CostLayers) != 1 || milk.CostLayers[0].Quantity != 8 {
+		t.Errorf("Expected 8 L left at 36000, got %+v at %v", milk.CostLayers, milk.CostPerUnit)
+	}
+	if milk.StockValue(CostingFIFO) != 288000 {
+		t.Errorf("Expected stock value 288000, got %v", milk.StockValue(CostingFIFO))
+	}
+
+	// Never below zero
+	removed, cost = milk.RemoveStock(CostingFIFO, 20, at)
+	if removed != 8 || cost != 288000 || milk.Quantity != 0 {
+		t.Errorf("Expected the last 8 L costing 288000, got %v costing %v with %v left", removed, cost, milk.Quantity)
+	}
+}
+
+// TestCostingWeightedAverage tests the running average cost
+func TestCostingWeightedAverage(t *testing.T) {
+	at := time.Now()
+	coffee := &Ingredient{Name: "Cà phê bột", Unit: UnitKilogram}
+
+	coffee.AddStock(CostingWeightedAverage, 4, 300000, at, nil)
+	coffee.AddStock(CostingWeightedAverage, 6, 350000, at, nil)
+	if !almost(coffee.CostPerUnit, 330000) {
+		t.Fatalf("Expected average 330000, got %v", coffee.CostPerUnit)
+	}
+
+	removed, cost := coffee.RemoveStock(CostingWeightedAverage, 5, at)
+	if removed != 5 || !almost(cost, 1650000) || !almost(coffee.CostPerUnit, 330000) {
+		t.Errorf("Expected 5 kg costing 1650000 at an unchanged average, got %v costing %v at %v", removed, cost, coffee.CostPerUnit)
+	}
+
+	// (5 * 330000 + 5 * 270000) / 10
+	coffee.AddStock(CostingWeightedAverage, 5, 270000, at, nil)
+	if !almost(coffee.CostPerUnit, 300000) {
+		t.Errorf("Expected average 300000, got %v", coffee.CostPerUnit)
+	}
+}
+
+// TestCostLayersReconcile tests stock recorded before layers and counted stock
+func TestCostLayersReconcile(t *testing.T) {
+	at := time.Now()
+	cups := &Ingredient{Name: "Ly giấy", Unit: UnitPiece, Quantity: 100, CostPerUnit: 1000}
+
+	cups.AddStock(CostingFIFO, 100, 1200, at, nil)
+	if len(cups.CostLayers) != 2 || cups.CostLayers[0].Quantity != 100 || cups.CostLayers[0].UnitCost != 1000 {
+		t.Fatalf("Expected an opening layer of 100 at 1000, got %+v", cups.CostLayers)
+	}
+
+	change, value := cups.SetCountedStock(CostingFIFO, 150, at)
+	if change != -50 || value != -50000 {
+		t.Errorf("Expected -50 valued -50000, got %v valued %v", change, value)
+	}
+	change, value = cups.SetCountedStock(CostingFIFO, 160, at)
+	if change != 10 || value != 10000 || cups.Quantity != 160 {
+		t.Errorf("Expected +10 valued 10000, got %v valued %v", change, value)
+	}
+}
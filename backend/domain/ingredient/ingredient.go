@@ -23,43 +23,76 @@ const (
 )
 
 type Ingredient struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Name        string             `bson:"name" json:"name"`
-	Category    string             `bson:"category" json:"category"`
-	Unit        UnitType           `bson:"unit" json:"unit"`
-	Quantity    float64            `bson:"quantity" json:"quantity"`
-	MinStock    float64            `bson:"min_stock" json:"min_stock"`
-	CostPerUnit float64            `bson:"cost_per_unit" json:"cost_per_unit"`
-	Supplier    string             `bson:"supplier" json:"supplier"`
-	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Name        string              `bson:"name" json:"name"`
+	Category    string              `bson:"category" json:"category"`
+	Unit        UnitType            `bson:"unit" json:"unit"`
+	Quantity    float64             `bson:"quantity" json:"quantity"`
+	MinStock    float64             `bson:"min_stock" json:"min_stock"`
+	CostPerUnit float64             `bson:"cost_per_unit" json:"cost_per_unit"`
+	Supplier    string              `bson:"supplier" json:"supplier"`
+	SupplierID  *primitive.ObjectID `bson:"supplier_id" json:"supplier_id,omitempty"`
+	// Conversions are ingredient-specific unit conversions such as 1 box = 12 piece
+	Conversions []UnitConversion `bson:"conversions" json:"conversions"`
+	// CostLayers are the receipts still in stock, oldest first. CostPerUnit is derived from
+	// them by the configured costing method.
+	CostLayers []CostLayer `bson:"cost_layers" json:"cost_layers"`
+	// Recipe makes a prepared item (syrup, cold brew) from other ingredients, nil for raw ingredients
+	Recipe    *PreparedRecipe `bson:"recipe,omitempty" json:"recipe,omitempty"`
+	CreatedAt time.Time       `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time       `bson:"updated_at" json:"updated_at"`
 }
 
 type CreateIngredientRequest struct {
-	Name        string   `json:"name" binding:"required"`
-	Category    string   `json:"category" binding:"required"`
-	Unit        UnitType `json:"unit" binding:"required"`
-	Quantity    float64  `json:"quantity" binding:"required,min=0"`
-	MinStock    float64  `json:"min_stock" binding:"min=0"`
-	CostPerUnit float64  `json:"cost_per_unit" binding:"min=0"`
-	Supplier    string   `json:"supplier"`
+	Name        string           `json:"name" binding:"required"`
+	Category    string           `json:"category" binding:"required"`
+	Unit        UnitType         `json:"unit" binding:"required"`
+	Quantity    float64          `json:"quantity" binding:"required,min=0"`
+	MinStock    float64          `json:"min_stock" binding:"min=0"`
+	CostPerUnit float64          `json:"cost_per_unit" binding:"min=0"`
+	Supplier    string           `json:"supplier"`
+	SupplierID  string           `json:"supplier_id"`
+	Conversions []UnitConversion `json:"conversions"`
+	Recipe      *RecipeRequest   `json:"recipe"`
 }
 
 type UpdateIngredientRequest struct {
-	Name        string   `json:"name"`
-	Category    string   `json:"category"`
-	Unit        UnitType `json:"unit"`
-	Quantity    *float64 `json:"quantity" binding:"omitempty,min=0"`
-	MinStock    *float64 `json:"min_stock" binding:"omitempty,min=0"`
-	CostPerUnit *float64 `json:"cost_per_unit" binding:"omitempty,min=0"`
-	Supplier    string   `json:"supplier"`
+	Name        string           `json:"name"`
+	Category    string           `json:"category"`
+	Unit        UnitType         `json:"unit"`
+	MinStock    *float64         `json:"min_stock" binding:"omitempty,min=0"`
+	CostPerUnit *float64         `json:"cost_per_unit" binding:"omitempty,min=0"`
+	Supplier    string           `json:"supplier"`
+	SupplierID  string           `json:"supplier_id"`
+	Conversions []UnitConversion `json:"conversions"` // replaces all conversions when not nil
+	Recipe      *RecipeRequest   `json:"recipe"`      // replaces the recipe when not nil
 }
 
 type StockAdjustmentRequest struct {
 	Quantity float64 `json:"quantity" binding:"required"`
 	Reason   string  `json:"reason" binding:"required"`
-	UserID   string  `json:"user_id"`
-	Username string  `json:"username"`
+	// Unit of Quantity, defaults to the ingredient unit
+	Unit string `json:"unit"`
+	// UnitCost is the cost per Unit of added stock, defaults to the current cost
+	UnitCost float64 `json:"unit_cost" binding:"min=0"`
+	// LotNumber and ExpiryDate record added stock as a batch
+	LotNumber  string     `json:"lot_number"`
+	ExpiryDate *time.Time `json:"expiry_date"`
+	UserID     string     `json:"user_id"`
+	Username   string     `json:"username"`
+}
+
+// StockReceipt is purchased stock received into inventory. Quantity and UnitPrice are in Unit.
+type StockReceipt struct {
+	Quantity        float64
+	Unit            string
+	UnitPrice       float64
+	LotNumber       string
+	ExpiryDate      *time.Time
+	PurchaseOrderID *primitive.ObjectID
+	Reason          string
+	UserID          string
+	Username        string
 }
 
 // IngredientCategory represents a category for ingredients
@@ -0,0 +1,235 @@
+package ingredient
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// ErrInvalidRecipe is returned when a prepared item recipe is incomplete or circular
+	ErrInvalidRecipe = errors.New("invalid recipe")
+	// ErrInsufficientStock is returned when removing more of an ingredient than is in stock
+	ErrInsufficientStock = errors.New("insufficient stock")
+)
+
+// RecipeLine is one ingredient used to make a prepared item
+type RecipeLine struct {
+	IngredientID   primitive.ObjectID `bson:"ingredient_id" json:"ingredient_id"`
+	IngredientName string             `bson:"ingredient_name" json:"ingredient_name"`
+	Quantity       float64            `bson:"quantity" json:"quantity"`
+	Unit           string             `bson:"unit" json:"unit"`
+}
+
+// PreparedRecipe makes a prepared item such as a syrup or cold brew from other ingredients.
+// One run of the recipe yields Yield of the prepared item in its stock unit.
+type PreparedRecipe struct {
+	Lines []RecipeLine `bson:"lines" json:"lines"`
+	Yield float64      `bson:"yield" json:"yield"`
+	// ShelfLifeHours sets the expiry of produced batches, 0 if they do not expire
+	ShelfLifeHours int    `bson:"shelf_life_hours" json:"shelf_life_hours"`
+	Instructions   string `bson:"instructions" json:"instructions"`
+}
+
+type RecipeLineRequest struct {
+	IngredientID string  `json:"ingredient_id" binding:"required"`
+	Quantity     float64 `json:"quantity" binding:"required,gt=0"`
+	Unit         string  `json:"unit"` // defaults to the ingredient stock unit
+}
+
+// RecipeRequest sets the recipe of a prepared item; no lines makes it a raw ingredient again
+type RecipeRequest struct {
+	Lines          []RecipeLineRequest `json:"lines" binding:"dive"`
+	Yield          float64             `json:"yield" binding:"min=0"`
+	ShelfLifeHours int                 `json:"shelf_life_hours" binding:"min=0"`
+	Instructions   string              `json:"instructions"`
+}
+
+// ProduceRequest makes a batch of a prepared item
+type ProduceRequest struct {
+	// Quantity produced in Unit, defaults to one recipe yield
+	Quantity float64 `json:"quantity" binding:"min=0"`
+	Unit     string  `json:"unit"`
+	// LotNumber and ExpiryDate are recorded on the batch; the expiry defaults to the shelf life
+	LotNumber  string     `json:"lot_number"`
+	ExpiryDate *time.Time `json:"expiry_date"`
+	Notes      string     `json:"notes"`
+	UserID     string     `json:"-"`
+	Username   string     `json:"-"`
+}
+
+// ProductionInput is an ingredient consumed by a production run, in its stock unit
+type ProductionInput struct {
+	IngredientID   primitive.ObjectID `bson:"ingredient_id" json:"ingredient_id"`
+	IngredientName string             `bson:"ingredient_name" json:"ingredient_name"`
+	Unit           UnitType           `bson:"unit" json:"unit"`
+	Quantity       float64            `bson:"quantity" json:"quantity"`
+	Cost           float64            `bson:"cost" json:"cost"`
+}
+
+// Production is one batch of a prepared item made from its recipe
+type Production struct {
+	ID             primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	IngredientID   primitive.ObjectID  `bson:"ingredient_id" json:"ingredient_id"`
+	IngredientName string              `bson:"ingredient_name" json:"ingredient_name"`
+	Unit           UnitType            `bson:"unit" json:"unit"`
+	Quantity       float64             `bson:"quantity" json:"quantity"`
+	Inputs         []ProductionInput   `bson:"inputs" json:"inputs"`
+	TotalCost      float64             `bson:"total_cost" json:"total_cost"`
+	UnitCost       float64             `bson:"unit_cost" json:"unit_cost"`
+	BatchID        *primitive.ObjectID `bson:"batch_id,omitempty" json:"batch_id,omitempty"`
+	Notes          string              `bson:"notes" json:"notes"`
+	UserID         primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	Username       string              `bson:"username" json:"username"`
+	CreatedAt      time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+// IsPrepared reports whether the ingredient is made in-house from a recipe
+func (i *Ingredient) IsPrepared() bool {
+	return i.Recipe != nil && len(i.Recipe.Lines) > 0
+}
+
+// NewPreparedRecipe validates a recipe for item against the inventory. Every line must be
+// an existing ingredient in a convertible unit, and the recipe must not use the item itself,
+// directly or through other prepared items.
+func NewPreparedRecipe(item *Ingredient, req *RecipeRequest, ingredients map[primitive.ObjectID]*Ingredient) (*PreparedRecipe, error) {
+	if len(req.Lines) == 0 {
+		return nil, nil
+	}
+	if req.Yield <= 0 {
+		return nil, fmt.Errorf("%w: yield must be greater than 0", ErrInvalidRecipe)
+	}
+
+	recipe := &PreparedRecipe{
+		Yield:          req.Yield,
+		ShelfLifeHours: req.ShelfLifeHours,
+		Instructions:   req.Instructions,
+	}
+	for _, l := range req.Lines {
+		id, err := primitive.ObjectIDFromHex(l.IngredientID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid ingredient id %q", ErrInvalidRecipe, l.IngredientID)
+		}
+		ing, ok := ingredients[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: ingredient %s not found", ErrInvalidRecipe, l.IngredientID)
+		}
+		if ing.ID == item.ID || ing.uses(item.ID, ingredients, 0) {
+			return nil, fmt.Errorf("%w: %s cannot be made from %s", ErrInvalidRecipe, item.Name, ing.Name)
+		}
+		unit := string(ing.Unit)
+		if l.Unit != "" {
+			if _, err := ing.ToStockUnit(1, l.Unit); err != nil {
+				return nil, err
+			}
+			unit = string(NormalizeUnit(l.Unit))
+		}
+		recipe.Lines = append(recipe.Lines, RecipeLine{
+			IngredientID:   id,
+			IngredientName: ing.Name,
+			Quantity:       l.Quantity,
+			Unit:           unit,
+		})
+	}
+	return recipe, nil
+}
+
+// maxRecipeDepth bounds nesting of prepared items, guarding against cycles in stored data
+const maxRecipeDepth = 10
+
+// uses reports whether the recipe of i needs the ingredient id, directly or through other
+// prepared items
+func (i *Ingredient) uses(id primitive.ObjectID, ingredients map[primitive.ObjectID]*Ingredient, depth int) bool {
+	if depth > maxRecipeDepth {
+		return true
+	}
+	if !i.IsPrepared() {
+		return false
+	}
+	for _, l := range i.Recipe.Lines {
+		if l.IngredientID == id {
+			return true
+		}
+		if sub, ok := ingredients[l.IngredientID]; ok && sub.uses(id, ingredients, depth+1) {
+			return true
+		}
+	}
+	return false
+}
+
+// PlanProduction returns the stock each recipe ingredient gives up to produce quantity of the
+// prepared item, in the ingredient stock units. Ingredients without enough stock are
+// reported with ErrInsufficientStock.
+func (i *Ingredient) PlanProduction(quantity float64, ingredients map[primitive.ObjectID]*Ingredient) ([]ProductionInput, error) {
+	if !i.IsPrepared() {
+		return nil, fmt.Errorf("%s has no recipe", i.Name)
+	}
+	if quantity <= 0 {
+		return nil, errors.New("quantity must be greater than 0")
+	}
+
+	scale := quantity / i.Recipe.Yield
+	inputs := make([]ProductionInput, 0, len(i.Recipe.Lines))
+	var short []string
+	for _, l := range i.Recipe.Lines {
+		ing, ok := ingredients[l.IngredientID]
+		if !ok {
+			return nil, fmt.Errorf("ingredient %s not found", l.IngredientName)
+		}
+		qty, err := ing.ToStockUnit(l.Quantity*scale, l.Unit)
+		if err != nil {
+			return nil, err
+		}
+		qty = math.Round(qty*1000) / 1000
+		if qty > ing.Quantity {
+			short = append(short, fmt.Sprintf("%s (need %g %s, have %g)", ing.Name, qty, ing.Unit, ing.Quantity))
+		}
+		inputs = append(inputs, ProductionInput{
+			IngredientID:   ing.ID,
+			IngredientName: ing.Name,
+			Unit:           ing.Unit,
+			Quantity:       qty,
+		})
+	}
+	if len(short) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInsufficientStock, strings.Join(short, ", "))
+	}
+	return inputs, nil
+}
+
+// RecipeUnitCost rolls the current costs of the recipe ingredients up to the cost of one stock
+// unit of the prepared item, through nested prepared items. Returns false if a line cannot be
+// costed.
+func (i *Ingredient) RecipeUnitCost(ingredients map[primitive.ObjectID]*Ingredient) (float64, bool) {
+	return i.recipeUnitCost(ingredients, 0)
+}
+
+func (i *Ingredient) recipeUnitCost(ingredients map[primitive.ObjectID]*Ingredient, depth int) (float64, bool) {
+	if !i.IsPrepared() || i.Recipe.Yield <= 0 || depth > maxRecipeDepth {
+		return 0, false
+	}
+	total := 0.0
+	for _, l := range i.Recipe.Lines {
+		ing, ok := ingredients[l.IngredientID]
+		if !ok {
+			return 0, false
+		}
+		qty, err := ing.ToStockUnit(l.Quantity, l.Unit)
+		if err != nil {
+			return 0, false
+		}
+		unitCost := ing.CostPerUnit
+		if ing.IsPrepared() {
+			if unitCost, ok = ing.recipeUnitCost(ingredients, depth+1); !ok {
+				return 0, false
+			}
+		}
+		total += qty * unitCost
+	}
+	return total / i.Recipe.Yield, true
+}
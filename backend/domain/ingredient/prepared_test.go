@@ -0,0 +1,104 @@
+package ingredient
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func preparedInventory() (coffee, water, sugar, syrup, coldBrew *Ingredient, byID map[primitive.ObjectID]*Ingredient) {
+	coffee = &Ingredient{ID: primitive.NewObjectID(), Name: "Cà phê xay thô", Unit: UnitKilogram, Quantity: 2, CostPerUnit: 400000}
+	water = &Ingredient{ID: primitive.NewObjectID(), Name: "Nước lọc", Unit: UnitLiter, Quantity: 50, CostPerUnit: 1000}
+	sugar = &Ingredient{ID: primitive.NewObjectID(), Name: "Đường", Unit: UnitKilogram, Quantity: 5, CostPerUnit: 20000}
+	syrup = &Ingredient{ID: primitive.NewObjectID(), Name: "Syrup đường", Unit: UnitLiter}
+	coldBrew = &Ingredient{ID: primitive.NewObjectID(), Name: "Cold brew", Unit: UnitLiter}
+	byID = map[primitive.ObjectID]*Ingredient{}
+	for _, ing := range []*Ingredient{coffee, water, sugar, syrup, coldBrew} {
+		byID[ing.ID] = ing
+	}
+	return
+}
+
+// TestNewPreparedRecipe tests recipe validation, unit normalisation and cycle detection
+func TestNewPreparedRecipe(t *testing.T) {
+	coffee, water, sugar, syrup, coldBrew, byID := preparedInventory()
+
+	recipe, err := NewPreparedRecipe(syrup, &RecipeRequest{Yield: 1.5, Lines: []RecipeLineRequest{
+		{IngredientID: sugar.ID.Hex(), Quantity: 1000, Unit: "gr"},
+		{IngredientID: water.ID.Hex(), Quantity: 1},
+	}}, byID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if recipe.Lines[0].Unit != "g" || recipe.Lines[1].Unit != "L" || recipe.Lines[0].IngredientName != "Đường" {
+		t.Errorf("Expected normalised units and names, got %+v", recipe.Lines)
+	}
+	syrup.Recipe = recipe
+
+	// Prepared items may use other prepared items
+	coldBrew.Recipe, err = NewPreparedRecipe(coldBrew, &RecipeRequest{Yield: 10, Lines: []RecipeLineRequest{
+		{IngredientID: coffee.ID.Hex(), Quantity: 1},
+		{IngredientID: water.ID.Hex(), Quantity: 10},
+		{IngredientID: syrup.ID.Hex(), Quantity: 500, Unit: "ml"},
+	}}, byID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// ...but not themselves, directly or through another prepared item
+	if _, err := NewPreparedRecipe(syrup, &RecipeRequest{Yield: 1, Lines: []RecipeLineRequest{{IngredientID: syrup.ID.Hex(), Quantity: 1}}}, byID); !errors.Is(err, ErrInvalidRecipe) {
+		t.Error("Expected a recipe using itself to be rejected")
+	}
+	if _, err := NewPreparedRecipe(syrup, &RecipeRequest{Yield: 1, Lines: []RecipeLineRequest{{IngredientID: coldBrew.ID.Hex(), Quantity: 1}}}, byID); !errors.Is(err, ErrInvalidRecipe) {
+		t.Error("Expected a recipe cycle to be rejected")
+	}
+	if _, err := NewPreparedRecipe(syrup, &RecipeRequest{Yield: 1, Lines: []RecipeLineRequest{{IngredientID: sugar.ID.Hex(), Quantity: 1, Unit: "ml"}}}, byID); !errors.Is(err, ErrInvalidUnit) {
+		t.Errorf("Expected ErrInvalidUnit, got %v", err)
+	}
+	if _, err := NewPreparedRecipe(syrup, &RecipeRequest{Lines: []RecipeLineRequest{{IngredientID: sugar.ID.Hex(), Quantity: 1}}}, byID); !errors.Is(err, ErrInvalidRecipe) {
+		t.Error("Expected a recipe without yield to be rejected")
+	}
+	if recipe, err := NewPreparedRecipe(syrup, &RecipeRequest{}, byID); recipe != nil || err != nil {
+		t.Errorf("Expected an empty recipe to clear it, got %v (%v)", recipe, err)
+	}
+}
+
+// TestRecipeUnitCostAndPlanProduction tests cost roll-up and scaling of a production run
+func TestRecipeUnitCostAndPlanProduction(t *testing.T) {
+	coffee, water, sugar, syrup, coldBrew, byID := preparedInventory()
+	syrup.Recipe = &PreparedRecipe{Yield: 1.5, Lines: []RecipeLine{
+		{IngredientID: sugar.ID, Quantity: 1000, Unit: "g"},
+		{IngredientID: water.ID, Quantity: 1, Unit: "L"},
+	}}
+	coldBrew.Recipe = &PreparedRecipe{Yield: 10, Lines: []RecipeLine{
+		{IngredientID: coffee.ID, Quantity: 1, Unit: "kg"},
+		{IngredientID: water.ID, Quantity: 10, Unit: "L"},
+		{IngredientID: syrup.ID, Quantity: 1500, Unit: "ml"},
+	}}
+
+	// (20000 + 1000) / 1.5
+	if cost, ok := syrup.RecipeUnitCost(byID); !ok || !almost(cost, 14000) {
+		t.Errorf("Expected syrup at 14000 per L, got %v (%v)", cost, ok)
+	}
+	// (400000 + 10000 + 1.5 * 14000) / 10, syrup costed from its own recipe
+	if cost, ok := coldBrew.RecipeUnitCost(byID); !ok || !almost(cost, 43100) {
+		t.Errorf("Expected cold brew at 43100 per L, got %v (%v)", cost, ok)
+	}
+
+	syrup.Quantity = 3
+	inputs, err := coldBrew.PlanProduction(5, byID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if inputs[0].Quantity != 0.5 || inputs[1].Quantity != 5 || inputs[2].Quantity != 0.75 || inputs[2].Unit != UnitLiter {
+		t.Errorf("Expected half the recipe in stock units, got %+v", inputs)
+	}
+
+	if _, err := coldBrew.PlanProduction(50, byID); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("Expected ErrInsufficientStock, got %v", err)
+	}
+	if _, err := coffee.PlanProduction(1, byID); err == nil {
+		t.Error("Expected producing a raw ingredient to fail")
+	}
+}
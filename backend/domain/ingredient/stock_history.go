@@ -12,18 +12,34 @@ const (
 	TransactionOrder      TransactionType = "order"
 	TransactionPurchase   TransactionType = "purchase"
 	TransactionWaste      TransactionType = "waste"
+	TransactionStocktake  TransactionType = "stocktake"
+	TransactionProduction TransactionType = "production"
 )
 
 type StockHistory struct {
-	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	IngredientID primitive.ObjectID `bson:"ingredient_id" json:"ingredient_id"`
-	Type         TransactionType    `bson:"type" json:"type"`
-	Quantity     float64            `bson:"quantity" json:"quantity"`
-	BeforeQty    float64            `bson:"before_qty" json:"before_qty"`
-	AfterQty     float64            `bson:"after_qty" json:"after_qty"`
-	Reason       string             `bson:"reason" json:"reason"`
-	OrderID      *primitive.ObjectID `bson:"order_id,omitempty" json:"order_id,omitempty"`
-	UserID       primitive.ObjectID `bson:"user_id" json:"user_id"`
-	Username     string             `bson:"username" json:"username"`
-	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
+	ID              primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	IngredientID    primitive.ObjectID  `bson:"ingredient_id" json:"ingredient_id"`
+	Type            TransactionType     `bson:"type" json:"type"`
+	Quantity        float64             `bson:"quantity" json:"quantity"`
+	BeforeQty       float64             `bson:"before_qty" json:"before_qty"`
+	AfterQty        float64             `bson:"after_qty" json:"after_qty"`
+	Reason          string              `bson:"reason" json:"reason"`
+	UnitCost        float64             `bson:"unit_cost" json:"unit_cost"` // cost of the stock moved
+	Value           float64             `bson:"value" json:"value"`         // signed like Quantity
+	OrderID         *primitive.ObjectID `bson:"order_id,omitempty" json:"order_id,omitempty"`
+	PurchaseOrderID *primitive.ObjectID `bson:"purchase_order_id,omitempty" json:"purchase_order_id,omitempty"`
+	BatchID         *primitive.ObjectID `bson:"batch_id,omitempty" json:"batch_id,omitempty"`
+	StocktakeID     *primitive.ObjectID `bson:"stocktake_id,omitempty" json:"stocktake_id,omitempty"`
+	ProductionID    *primitive.ObjectID `bson:"production_id,omitempty" json:"production_id,omitempty"`
+	// Batches drawn from, earliest expiry first, when stock is consumed
+	Batches   []BatchDraw        `bson:"batches,omitempty" json:"batches,omitempty"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Username  string             `bson:"username" json:"username"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// StockCount is a physically counted quantity of an ingredient in its stock unit
+type StockCount struct {
+	IngredientID primitive.ObjectID
+	Quantity     float64
 }
\ No newline at end of file
@@ -0,0 +1,221 @@
+package ingredient
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidUnit is returned when a unit is unknown or cannot be converted to the stock unit
+var ErrInvalidUnit = errors.New("invalid unit")
+
+// ErrUnitChanged is returned when an ingredient's unit was changed since it was read
+var ErrUnitChanged = errors.New("ingredient unit was changed by someone else, reload it and try again")
+
+// Dimension is the physical quantity a unit measures. Units convert freely within
+// a dimension; across dimensions only through an ingredient's custom conversions.
+type Dimension string
+
+const (
+	DimensionMass   Dimension = "mass"
+	DimensionVolume Dimension = "volume"
+	DimensionCount  Dimension = "count"
+)
+
+// unitAliases maps unit spellings used in recipes and inventory to a canonical unit
+var unitAliases = map[string]UnitType{
+	"kg":     UnitKilogram,
+	"g":      UnitGram,
+	"gr":     UnitGram,
+	"gram":   UnitGram,
+	"l":      UnitLiter,
+	"lit":    UnitLiter,
+	"lít":    UnitLiter,
+	"ml":     UnitMilliliter,
+	"piece":  UnitPiece,
+	"pieces": UnitPiece,
+	"pcs":    UnitPiece,
+	"cái":    UnitPiece,
+	"box":    UnitBox,
+	"hộp":    UnitBox,
+	"pack":   UnitPack,
+	"gói":    UnitPack,
+}
+
+// unitDefinitions gives the dimension and size of each canonical unit relative to the
+// base unit of its dimension (kg = 1000 g). Box and pack have no fixed size and are
+// their own dimension until an ingredient defines what they contain.
+var unitDefinitions = map[UnitType]struct {
+	dimension Dimension
+	base      UnitType
+	factor    float64
+}{
+	UnitKilogram:   {DimensionMass, UnitGram, 1000},
+	UnitGram:       {DimensionMass, UnitGram, 1},
+	UnitLiter:      {DimensionVolume, UnitMilliliter, 1000},
+	UnitMilliliter: {DimensionVolume, UnitMilliliter, 1},
+	UnitPiece:      {DimensionCount, UnitPiece, 1},
+	UnitBox:        {Dimension(UnitBox), UnitBox, 1},
+	UnitPack:       {Dimension(UnitPack), UnitPack, 1},
+}
+
+// UnitConversion is an ingredient-specific conversion, e.g. 1 box = 12 piece or 1 pack = 500 g
+type UnitConversion struct {
+	From   UnitType `bson:"from" json:"from"`
+	To     UnitType `bson:"to" json:"to"`
+	Factor float64  `bson:"factor" json:"factor"` // quantity of To in one From
+}
+
+// UnitInfo describes a supported unit
+type UnitInfo struct {
+	Unit      UnitType  `json:"unit"`
+	Dimension Dimension `json:"dimension"`
+	Base      UnitType  `json:"base"`
+	Factor    float64   `json:"factor"`
+}
+
+// SupportedUnits lists the canonical units in a stable order
+func SupportedUnits() []UnitInfo {
+	units := []UnitType{UnitKilogram, UnitGram, UnitLiter, UnitMilliliter, UnitPiece, UnitBox, UnitPack}
+	result := make([]UnitInfo, 0, len(units))
+	for _, u := range units {
+		def := unitDefinitions[u]
+		result = append(result, UnitInfo{Unit: u, Dimension: def.dimension, Base: def.base, Factor: def.factor})
+	}
+	return result
+}
+
+// NormalizeUnit returns the canonical unit for a spelling such as "gram" or "L"
+func NormalizeUnit(unit string) UnitType {
+	u := strings.ToLower(strings.TrimSpace(unit))
+	if canonical, ok := unitAliases[u]; ok {
+		return canonical
+	}
+	return UnitType(u)
+}
+
+// IsKnownUnit reports whether the unit, in any accepted spelling, is supported
+func IsKnownUnit(unit string) bool {
+	_, ok := unitDefinitions[NormalizeUnit(unit)]
+	return ok
+}
+
+// UnitDimension returns the dimension of a unit
+func UnitDimension(unit string) (Dimension, bool) {
+	def, ok := unitDefinitions[NormalizeUnit(unit)]
+	return def.dimension, ok
+}
+
+// ValidateUnit returns ErrInvalidUnit for units that are not supported
+func ValidateUnit(unit string) error {
+	if !IsKnownUnit(unit) {
+		return fmt.Errorf("%w: unknown unit %q", ErrInvalidUnit, unit)
+	}
+	return nil
+}
+
+// Validate checks a custom conversion links two different known units with a positive factor
+func (c UnitConversion) Validate() error {
+	if err := ValidateUnit(string(c.From)); err != nil {
+		return err
+	}
+	if err := ValidateUnit(string(c.To)); err != nil {
+		return err
+	}
+	if NormalizeUnit(string(c.From)) == NormalizeUnit(string(c.To)) {
+		return fmt.Errorf("%w: conversion from %s to itself", ErrInvalidUnit, c.From)
+	}
+	if c.Factor <= 0 {
+		return fmt.Errorf("%w: conversion factor must be positive", ErrInvalidUnit)
+	}
+	return nil
+}
+
+// NormalizeConversions validates custom conversions and stores their units in canonical form
+func NormalizeConversions(conversions []UnitConversion) ([]UnitConversion, error) {
+	result := make([]UnitConversion, 0, len(conversions))
+	for _, c := range conversions {
+		if err := c.Validate(); err != nil {
+			return nil, err
+		}
+		result = append(result, UnitConversion{
+			From:   NormalizeUnit(string(c.From)),
+			To:     NormalizeUnit(string(c.To)),
+			Factor: c.Factor,
+		})
+	}
+	return result, nil
+}
+
+// ConvertQuantity converts a quantity between units of the same dimension (g <-> kg, ml <-> L).
+// Returns false if the units cannot be converted.
+func ConvertQuantity(quantity float64, from, to string) (float64, bool) {
+	f, fok := unitDefinitions[NormalizeUnit(from)]
+	t, tok := unitDefinitions[NormalizeUnit(to)]
+	if !fok || !tok {
+		if NormalizeUnit(from) == NormalizeUnit(to) {
+			return quantity, true
+		}
+		return 0, false
+	}
+	if f.base != t.base {
+		return 0, false
+	}
+	return quantity * f.factor / t.factor, true
+}
+
+// ConvertWith converts a quantity between units, falling back to the custom conversions
+// when the units are of different dimensions (e.g. 2 box -> 24 piece with 1 box = 12 piece,
+// or 250 g -> 0.5 pack with 1 pack = 500 g). Conversions are usable in both directions.
+func ConvertWith(quantity float64, from, to string, conversions []UnitConversion) (float64, bool) {
+	if q, ok := ConvertQuantity(quantity, from, to); ok {
+		return q, true
+	}
+	for _, c := range conversions {
+		if c.Factor <= 0 {
+			continue
+		}
+		if q, ok := ConvertQuantity(quantity, from, string(c.From)); ok {
+			if q, ok := ConvertQuantity(q*c.Factor, string(c.To), to); ok {
+				return q, true
+			}
+		}
+		if q, ok := ConvertQuantity(quantity, from, string(c.To)); ok {
+			if q, ok := ConvertQuantity(q/c.Factor, string(c.From), to); ok {
+				return q, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// ToStockUnit converts a quantity in the given unit to the unit the ingredient is stocked in
+func (i *Ingredient) ToStockUnit(quantity float64, unit string) (float64, error) {
+	if unit == "" {
+		return quantity, nil
+	}
+	q, ok := ConvertWith(quantity, unit, string(i.Unit), i.Conversions)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s cannot be converted to %s for %s", ErrInvalidUnit, unit, i.Unit, i.Name)
+	}
+	return q, nil
+}
+
+// ChangeUnit restocks the ingredient in another unit, converting its quantity, minimum stock,
+// cost per unit and cost layers. It returns the quantity of the new unit in one old unit, and
+// ErrInvalidUnit if the units cannot be converted (e.g. kg to L without a custom conversion).
+func (i *Ingredient) ChangeUnit(unit UnitType) (float64, error) {
+	factor, ok := ConvertWith(1, string(i.Unit), string(unit), i.Conversions)
+	if !ok || factor <= 0 {
+		return 0, fmt.Errorf("%w: stock of %s in %s cannot be converted to %s", ErrInvalidUnit, i.Name, i.Unit, unit)
+	}
+	i.Unit = unit
+	i.Quantity *= factor
+	i.MinStock *= factor
+	i.CostPerUnit /= factor
+	for j := range i.CostLayers {
+		i.CostLayers[j].Quantity *= factor
+		i.CostLayers[j].UnitCost /= factor
+	}
+	return factor, nil
+}
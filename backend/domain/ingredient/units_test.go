@@ -0,0 +1,107 @@
+package ingredient
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestConvertWith tests standard and ingredient-specific unit conversions
+func TestConvertWith(t *testing.T) {
+	conversions := []UnitConversion{
+		{From: UnitBox, To: UnitPiece, Factor: 12},
+		{From: UnitPack, To: UnitGram, Factor: 500},
+	}
+
+	tests := []struct {
+		name     string
+		quantity float64
+		from     string
+		to       string
+		expected float64
+		ok       bool
+	}{
+		{"Grams to kilograms", 250, "gram", "kg", 0.25, true},
+		{"Liters to milliliters", 1.5, "L", "ml", 1500, true},
+		{"Boxes to pieces", 2, "box", "piece", 24, true},
+		{"Pieces to boxes", 6, "pcs", "box", 0.5, true},
+		{"Kilograms to packs", 1, "kg", "pack", 2, true},
+		{"Packs to grams", 3, "gói", "g", 1500, true},
+		{"Mass to volume", 1, "kg", "L", 0, false},
+		{"Boxes to grams without conversion", 1, "box", "g", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ConvertWith(tt.quantity, tt.from, tt.to, conversions)
+			if ok != tt.ok || math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("ConvertWith(%v, %s, %s) = (%v, %v), expected (%v, %v)",
+					tt.quantity, tt.from, tt.to, got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+// TestToStockUnit tests conversion to the stock unit and rejection of incompatible units
+func TestToStockUnit(t *testing.T) {
+	milk := &Ingredient{Name: "Sữa tươi", Unit: UnitLiter}
+
+	if q, err := milk.ToStockUnit(500, "ml"); err != nil || q != 0.5 {
+		t.Errorf("Expected 0.5 L, got %v (%v)", q, err)
+	}
+	if q, err := milk.ToStockUnit(2, ""); err != nil || q != 2 {
+		t.Errorf("Expected quantity in stock unit to pass through, got %v (%v)", q, err)
+	}
+	if _, err := milk.ToStockUnit(1, "kg"); !errors.Is(err, ErrInvalidUnit) {
+		t.Errorf("Expected ErrInvalidUnit, got %v", err)
+	}
+}
+
+// TestNormalizeConversions tests validation of custom conversions
+func TestNormalizeConversions(t *testing.T) {
+	got, err := NormalizeConversions([]UnitConversion{{From: "Hộp", To: "cái", Factor: 10}})
+	if err != nil || got[0].From != UnitBox || got[0].To != UnitPiece {
+		t.Errorf("Expected box -> piece, got %+v (%v)", got, err)
+	}
+
+	invalid := []UnitConversion{
+		{From: UnitBox, To: "cup", Factor: 4},
+		{From: UnitBox, To: UnitBox, Factor: 2},
+		{From: UnitPack, To: UnitGram, Factor: 0},
+	}
+	for _, c := range invalid {
+		if _, err := NormalizeConversions([]UnitConversion{c}); !errors.Is(err, ErrInvalidUnit) {
+			t.Errorf("Expected %+v to be rejected, got %v", c, err)
+		}
+	}
+}
+
+// TestChangeUnit tests that changing the stock unit converts the stock, its cost and layers
+func TestChangeUnit(t *testing.T) {
+	milk := &Ingredient{
+		Name:        "Milk",
+		Unit:        UnitLiter,
+		Quantity:    2,
+		MinStock:    1,
+		CostPerUnit: 30000,
+		CostLayers:  []CostLayer{{Quantity: 2, UnitCost: 30000}},
+	}
+	factor, err := milk.ChangeUnit(UnitMilliliter)
+	if err != nil {
+		t.Fatalf("ChangeUnit: %v", err)
+	}
+	if factor != 1000 || milk.Unit != UnitMilliliter || milk.Quantity != 2000 || milk.MinStock != 1000 {
+		t.Errorf("got factor %v, %v %s, min %v; expected 1000, 2000 ml, min 1000", factor, milk.Quantity, milk.Unit, milk.MinStock)
+	}
+	if math.Abs(milk.CostPerUnit-30) > 1e-9 || milk.CostLayers[0].Quantity != 2000 || math.Abs(milk.CostLayers[0].UnitCost-30) > 1e-9 {
+		t.Errorf("got cost %v, layer %+v; expected 30 per ml", milk.CostPerUnit, milk.CostLayers[0])
+	}
+
+	sugar := &Ingredient{Name: "Sugar", Unit: UnitKilogram, Quantity: 3, CostPerUnit: 20000}
+	if _, err := sugar.ChangeUnit(UnitLiter); !errors.Is(err, ErrInvalidUnit) {
+		t.Errorf("expected ErrInvalidUnit changing kg to L, got %v", err)
+	}
+	if sugar.Unit != UnitKilogram || sugar.Quantity != 3 || sugar.CostPerUnit != 20000 {
+		t.Errorf("rejected change modified the ingredient: %+v", sugar)
+	}
+}
@@ -0,0 +1,59 @@
+package menu
+
+import (
+	"math"
+	"strings"
+
+	"cafe-pos/backend/domain/ingredient"
+)
+
+// StockLevel is the quantity of an inventory ingredient currently on hand
+type StockLevel struct {
+	Quantity    float64
+	Unit        string
+	Conversions []ingredient.UnitConversion
+}
+
+// StockKey normalises an ingredient name for matching recipe lines against inventory
+func StockKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// PortionsFromStock returns how many portions of the recipe can be made from the given stock,
+// keyed by StockKey of the ingredient name.
+// Recipe lines whose ingredient is not tracked in inventory, or whose unit cannot be converted
+// to the inventory unit, do not limit the count. Returns false if no line could be evaluated.
+func PortionsFromStock(recipe []Ingredient, stock map[string]StockLevel) (int, bool) {
+	portions := math.MaxInt32
+	tracked := false
+
+	for _, line := range recipe {
+		if line.Quantity <= 0 {
+			continue
+		}
+		level, ok := stock[StockKey(line.Name)]
+		if !ok {
+			continue
+		}
+
+		need, ok := ingredient.ConvertWith(line.Quantity, line.Unit, level.Unit, level.Conversions)
+		if !ok {
+			continue
+		}
+		have := level.Quantity
+
+		tracked = true
+		n := 0
+		if have > 0 {
+			n = int(math.Floor(have/need + 1e-9))
+		}
+		if n < portions {
+			portions = n
+		}
+	}
+
+	if !tracked {
+		return 0, false
+	}
+	return portions, true
+}
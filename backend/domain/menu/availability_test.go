@@ -0,0 +1,84 @@
+package menu
+
+import (
+	"testing"
+
+	"cafe-pos/backend/domain/ingredient"
+)
+
+// TestPortionsFromStock tests portion counting from recipe and stock levels
+func TestPortionsFromStock(t *testing.T) {
+	recipe := []Ingredient{
+		{Name: "Cà phê bột", Quantity: 20, Unit: "gram"},
+		{Name: "Sữa tươi", Quantity: 150, Unit: "ml"},
+		{Name: "Đá", Quantity: 100, Unit: "gram"},
+	}
+
+	tests := []struct {
+		name            string
+		stock           map[string]StockLevel
+		expectedCount   int
+		expectedTracked bool
+	}{
+		{
+			name: "Limited by the scarcest ingredient",
+			stock: map[string]StockLevel{
+				StockKey("Cà phê bột"): {Quantity: 1, Unit: "kg"},
+				StockKey("Sữa tươi"):   {Quantity: 0.5, Unit: "L"},
+			},
+			expectedCount:   3,
+			expectedTracked: true,
+		},
+		{
+			name: "Out of stock ingredient gives zero portions",
+			stock: map[string]StockLevel{
+				StockKey("Cà phê bột"): {Quantity: 1, Unit: "kg"},
+				StockKey("Sữa tươi"):   {Quantity: 0, Unit: "L"},
+			},
+			expectedCount:   0,
+			expectedTracked: true,
+		},
+		{
+			name: "Name matching ignores case and spaces",
+			stock: map[string]StockLevel{
+				StockKey(" CÀ PHÊ BỘT "): {Quantity: 60, Unit: "g"},
+			},
+			expectedCount:   3,
+			expectedTracked: true,
+		},
+		{
+			name: "Custom conversion of the ingredient is used",
+			stock: map[string]StockLevel{
+				StockKey("Cà phê bột"): {Quantity: 0.2, Unit: "pack", Conversions: []ingredient.UnitConversion{
+					{From: ingredient.UnitPack, To: ingredient.UnitGram, Factor: 500},
+				}},
+			},
+			expectedCount:   5,
+			expectedTracked: true,
+		},
+		{
+			name: "Incompatible unit is ignored",
+			stock: map[string]StockLevel{
+				StockKey("Sữa tươi"): {Quantity: 2, Unit: "kg"},
+			},
+			expectedCount:   0,
+			expectedTracked: false,
+		},
+		{
+			name:            "Recipe not tracked in inventory",
+			stock:           map[string]StockLevel{},
+			expectedCount:   0,
+			expectedTracked: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, tracked := PortionsFromStock(recipe, tt.stock)
+			if count != tt.expectedCount || tracked != tt.expectedTracked {
+				t.Errorf("PortionsFromStock() = (%d, %v), expected (%d, %v)",
+					count, tracked, tt.expectedCount, tt.expectedTracked)
+			}
+		})
+	}
+}
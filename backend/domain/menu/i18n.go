@@ -0,0 +1,135 @@
+package menu
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultLanguage is the language of MenuItem.Name and Description
+const DefaultLanguage = "vi"
+
+// Translation holds localized text of a menu item or category
+type Translation struct {
+	Name        string `bson:"name" json:"name"`
+	Description string `bson:"description,omitempty" json:"description,omitempty"`
+}
+
+// Category groups menu items and carries the translations of the category name.
+// Name is the Vietnamese name stored in MenuItem.Category.
+type Category struct {
+	ID           primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	Name         string                 `bson:"name" json:"name"`
+	SortOrder    int                    `bson:"sort_order" json:"sort_order"`
+	Translations map[string]Translation `bson:"translations" json:"translations"`
+	CreatedAt    time.Time              `bson:"created_at" json:"created_at"`
+	UpdatedAt    time.Time              `bson:"updated_at" json:"updated_at"`
+	// Display fields for the requested language, not stored
+	DisplayName string `bson:"-" json:"display_name,omitempty"`
+	Language    string `bson:"-" json:"language,omitempty"`
+}
+
+type CategoryRequest struct {
+	Name         string                 `json:"name" binding:"required"`
+	SortOrder    int                    `json:"sort_order"`
+	Translations map[string]Translation `json:"translations"`
+}
+
+// NormalizeLanguage reduces a language tag to its lower-case primary subtag ("en-US" -> "en")
+func NormalizeLanguage(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	return tag
+}
+
+// ParseAcceptLanguage returns the languages of an Accept-Language header ordered by preference
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+	var langs []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		lang := NormalizeLanguage(fields[0])
+		if lang == "" || lang == "*" {
+			continue
+		}
+		q := 1.0
+		for _, f := range fields[1:] {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(f), "q="); ok {
+				if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q > 0 {
+			langs = append(langs, weighted{lang, q})
+		}
+	}
+
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+	result := make([]string, 0, len(langs))
+	for _, l := range langs {
+		result = append(result, l.lang)
+	}
+	return result
+}
+
+// translate picks the first preferred language with a translation, falling back to Vietnamese
+func translate(translations map[string]Translation, langs []string) (Translation, string, bool) {
+	for _, lang := range langs {
+		if lang == DefaultLanguage {
+			break
+		}
+		if t, ok := translations[lang]; ok && t.Name != "" {
+			return t, lang, true
+		}
+	}
+	return Translation{}, DefaultLanguage, false
+}
+
+// Localize fills the display fields of the item for the preferred languages.
+// Name and Description keep the Vietnamese text; untranslated fields fall back to it.
+// categories maps Vietnamese category names to their category entity and may be nil.
+func (item *MenuItem) Localize(langs []string, categories map[string]*Category) {
+	item.DisplayName = item.Name
+	item.DisplayDescription = item.Description
+	item.DisplayCategory = item.Category
+	item.Language = DefaultLanguage
+
+	if t, lang, ok := translate(item.Translations, langs); ok {
+		item.DisplayName = t.Name
+		if t.Description != "" {
+			item.DisplayDescription = t.Description
+		}
+		item.Language = lang
+	}
+	if cat, ok := categories[item.Category]; ok {
+		cat.Localize(langs)
+		item.DisplayCategory = cat.DisplayName
+	}
+}
+
+// LocalizedName returns the item name in the given language, or the Vietnamese name
+func (item *MenuItem) LocalizedName(lang string) string {
+	if t, _, ok := translate(item.Translations, []string{NormalizeLanguage(lang)}); ok {
+		return t.Name
+	}
+	return item.Name
+}
+
+// Localize fills the display name of the category for the preferred languages
+func (c *Category) Localize(langs []string) {
+	c.DisplayName = c.Name
+	c.Language = DefaultLanguage
+	if t, lang, ok := translate(c.Translations, langs); ok {
+		c.DisplayName = t.Name
+		c.Language = lang
+	}
+}
@@ -0,0 +1,64 @@
+package menu
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestParseAcceptLanguage tests ordering of Accept-Language values by quality
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected []string
+	}{
+		{"en-US,en;q=0.9,vi;q=0.8", []string{"en", "en", "vi"}},
+		{"vi;q=0.5, ko", []string{"ko", "vi"}},
+		{"*, fr;q=0", []string{}},
+		{"", []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseAcceptLanguage(tt.header); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("ParseAcceptLanguage(%q) = %v, expected %v", tt.header, got, tt.expected)
+		}
+	}
+}
+
+// TestMenuItemLocalize tests translation lookup with Vietnamese fallback
+func TestMenuItemLocalize(t *testing.T) {
+	categories := map[string]*Category{
+		"Cà phê": {Name: "Cà phê", Translations: map[string]Translation{"en": {Name: "Coffee"}}},
+	}
+
+	newItem := func() *MenuItem {
+		return &MenuItem{
+			Name:        "Cà phê sữa",
+			Description: "Cà phê sữa đá",
+			Category:    "Cà phê",
+			Translations: map[string]Translation{
+				"en": {Name: "Iced milk coffee"},
+			},
+		}
+	}
+
+	item := newItem()
+	item.Localize([]string{"en", "vi"}, categories)
+	if item.DisplayName != "Iced milk coffee" || item.DisplayCategory != "Coffee" || item.Language != "en" {
+		t.Errorf("unexpected English display fields: %+v", item)
+	}
+	if item.DisplayDescription != "Cà phê sữa đá" {
+		t.Errorf("missing description translation should fall back to Vietnamese, got %q", item.DisplayDescription)
+	}
+
+	item = newItem()
+	item.Localize([]string{"ja", "vi"}, categories)
+	if item.DisplayName != "Cà phê sữa" || item.DisplayCategory != "Cà phê" || item.Language != DefaultLanguage {
+		t.Errorf("unexpected fallback display fields: %+v", item)
+	}
+
+	item = newItem()
+	item.Localize([]string{"vi", "en"}, categories)
+	if item.DisplayName != "Cà phê sữa" {
+		t.Errorf("Vietnamese preferred first should win, got %q", item.DisplayName)
+	}
+}
@@ -0,0 +1,219 @@
+package menu
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Columns of the menu import/export file. Recipe lines are kept in one cell,
+// formatted as "name:quantity:unit" separated by ";".
+var ImportColumns = []string{"name", "category", "price", "description", "available", "ingredients"}
+
+var requiredImportColumns = []string{"name", "category", "price"}
+
+// RowError is a validation error of one row of an import file (rows are 1-based, header is row 1)
+type RowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportRow is a validated row of an import file
+type ImportRow struct {
+	Row         int
+	Name        string
+	Category    string
+	Price       float64
+	Description string
+	Available   *bool // nil when the cell is empty
+	Ingredients []Ingredient
+	columns     map[string]bool
+}
+
+// Has reports whether the file has the column, so an update only overwrites the fields the
+// file contains (a file with only name, category and price keeps descriptions and recipes)
+func (r ImportRow) Has(column string) bool {
+	return r.columns[column]
+}
+
+type ImportAction string
+
+const (
+	ImportCreate ImportAction = "create"
+	ImportUpdate ImportAction = "update"
+)
+
+type ImportRowResult struct {
+	Row    int          `json:"row"`
+	Name   string       `json:"name"`
+	Action ImportAction `json:"action"`
+}
+
+type ImportResult struct {
+	DryRun    bool              `json:"dry_run"`
+	Applied   bool              `json:"applied"`
+	TotalRows int               `json:"total_rows"`
+	Created   int               `json:"created"`
+	Updated   int               `json:"updated"`
+	Rows      []ImportRowResult `json:"rows"`
+	Errors    []RowError        `json:"errors"`
+}
+
+// FormatRecipe renders recipe lines into the single-cell import format
+func FormatRecipe(ingredients []Ingredient) string {
+	parts := make([]string, 0, len(ingredients))
+	for _, ing := range ingredients {
+		parts = append(parts, fmt.Sprintf("%s:%s:%s", ing.Name, strconv.FormatFloat(ing.Quantity, 'f', -1, 64), ing.Unit))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// ParseRecipe parses recipe lines in the "name:quantity:unit; ..." format
+func ParseRecipe(s string) ([]Ingredient, error) {
+	var ingredients []Ingredient
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		fields := strings.Split(part, ":")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("recipe line %q must be name:quantity:unit", part)
+		}
+		name := strings.TrimSpace(fields[0])
+		if name == "" {
+			return nil, fmt.Errorf("recipe line %q has no ingredient name", part)
+		}
+		qty, err := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+		if err != nil || qty <= 0 {
+			return nil, fmt.Errorf("recipe line %q has an invalid quantity", part)
+		}
+		ingredients = append(ingredients, Ingredient{
+			Name:     name,
+			Quantity: qty,
+			Unit:     strings.TrimSpace(fields[2]),
+		})
+	}
+	return ingredients, nil
+}
+
+// ExportRecord converts a menu item into a row of the import/export file
+func ExportRecord(item *MenuItem) []string {
+	return []string{
+		item.Name,
+		item.Category,
+		strconv.FormatFloat(item.Price, 'f', -1, 64),
+		item.Description,
+		strconv.FormatBool(item.Available),
+		FormatRecipe(item.Ingredients),
+	}
+}
+
+// ParseImportRecords validates the records of an import file. The first record is the header;
+// columns are matched by name, case-insensitively, and may appear in any order.
+// Duplicate item names within the file are reported as errors.
+func ParseImportRecords(records [][]string) ([]ImportRow, []RowError) {
+	if len(records) == 0 {
+		return nil, []RowError{{Row: 1, Message: "file is empty"}}
+	}
+
+	index := make(map[string]int)
+	columns := make(map[string]bool)
+	for i, h := range records[0] {
+		col := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		index[col] = i
+		columns[col] = true
+	}
+	var errs []RowError
+	for _, col := range requiredImportColumns {
+		if _, ok := index[col]; !ok {
+			errs = append(errs, RowError{Row: 1, Column: col, Message: "missing required column"})
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	cell := func(record []string, col string) string {
+		i, ok := index[col]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []ImportRow
+	seen := make(map[string]int)
+	for n, record := range records[1:] {
+		rowNum := n + 2
+		if isBlankRecord(record) {
+			continue
+		}
+
+		row := ImportRow{
+			Row:         rowNum,
+			Name:        cell(record, "name"),
+			Category:    cell(record, "category"),
+			Description: cell(record, "description"),
+			columns:     columns,
+		}
+		rowErrs := len(errs)
+
+		if row.Name == "" {
+			errs = append(errs, RowError{Row: rowNum, Column: "name", Message: "name is required"})
+		} else if first, dup := seen[StockKey(row.Name)]; dup {
+			errs = append(errs, RowError{Row: rowNum, Column: "name", Message: fmt.Sprintf("duplicate of row %d", first)})
+		} else {
+			seen[StockKey(row.Name)] = rowNum
+		}
+		if row.Category == "" {
+			errs = append(errs, RowError{Row: rowNum, Column: "category", Message: "category is required"})
+		}
+
+		price, err := strconv.ParseFloat(cell(record, "price"), 64)
+		if err != nil || price < 0 {
+			errs = append(errs, RowError{Row: rowNum, Column: "price", Message: "price must be a non-negative number"})
+		}
+		row.Price = price
+
+		if v := cell(record, "available"); v != "" {
+			available, err := parseBool(v)
+			if err != nil {
+				errs = append(errs, RowError{Row: rowNum, Column: "available", Message: err.Error()})
+			}
+			row.Available = &available
+		}
+
+		ingredients, err := ParseRecipe(cell(record, "ingredients"))
+		if err != nil {
+			errs = append(errs, RowError{Row: rowNum, Column: "ingredients", Message: err.Error()})
+		}
+		row.Ingredients = ingredients
+
+		if len(errs) == rowErrs {
+			rows = append(rows, row)
+		}
+	}
+
+	return rows, errs
+}
+
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "true", "yes", "y", "1", "có", "co":
+		return true, nil
+	case "false", "no", "n", "0", "không", "khong":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid availability %q, use true or false", s)
+}
+
+func isBlankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
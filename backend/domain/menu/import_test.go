@@ -0,0 +1,80 @@
+package menu
+
+import (
+	"testing"
+)
+
+// TestParseImportRecords tests validation of menu import rows
+func TestParseImportRecords(t *testing.T) {
+	records := [][]string{
+		{"Name", "Price", "Category", "Available", "Ingredients"},
+		{"Cà phê sữa", "30000", "Cà phê", "true", "Cà phê bột:20:g; Sữa đặc:30:ml"},
+		{"Trà đào", "abc", "Trà", "", ""},
+		{"", "", "", "", ""},
+		{"cà phê sữa", "32000", "Cà phê", "", ""},
+		{"Bạc xỉu", "35000", "", "maybe", "Sữa đặc:nhiều:ml"},
+	}
+
+	rows, errs := ParseImportRecords(records)
+
+	if len(rows) != 1 {
+		t.Fatalf("expected 1 valid row, got %d", len(rows))
+	}
+	row := rows[0]
+	if row.Name != "Cà phê sữa" || row.Price != 30000 || row.Available == nil || !*row.Available {
+		t.Errorf("unexpected row: %+v", row)
+	}
+	if len(row.Ingredients) != 2 || row.Ingredients[1].Name != "Sữa đặc" || row.Ingredients[1].Quantity != 30 {
+		t.Errorf("unexpected ingredients: %+v", row.Ingredients)
+	}
+	if !row.Has("ingredients") || row.Has("description") {
+		t.Errorf("expected the ingredients column and no description column")
+	}
+
+	expected := map[int][]string{
+		3: {"price"},
+		5: {"name"},
+		6: {"category", "available", "ingredients"},
+	}
+	got := make(map[int][]string)
+	for _, e := range errs {
+		got[e.Row] = append(got[e.Row], e.Column)
+	}
+	for rowNum, cols := range expected {
+		if len(got[rowNum]) != len(cols) {
+			t.Errorf("row %d: expected errors on %v, got %v", rowNum, cols, got[rowNum])
+		}
+	}
+	if len(got) != len(expected) {
+		t.Errorf("expected errors on rows %v, got %v", expected, got)
+	}
+}
+
+// TestParseImportRecordsMissingColumns tests that required columns are checked
+func TestParseImportRecordsMissingColumns(t *testing.T) {
+	_, errs := ParseImportRecords([][]string{{"name", "description"}})
+	if len(errs) != 2 {
+		t.Errorf("expected 2 missing column errors, got %v", errs)
+	}
+}
+
+// TestRecipeRoundTrip tests that exported recipes can be imported back
+func TestRecipeRoundTrip(t *testing.T) {
+	recipe := []Ingredient{
+		{Name: "Espresso", Quantity: 30, Unit: "ml"},
+		{Name: "Sữa tươi", Quantity: 150.5, Unit: "ml"},
+	}
+
+	parsed, err := ParseRecipe(FormatRecipe(recipe))
+	if err != nil {
+		t.Fatalf("ParseRecipe() error = %v", err)
+	}
+	if len(parsed) != len(recipe) {
+		t.Fatalf("expected %d lines, got %d", len(recipe), len(parsed))
+	}
+	for i := range recipe {
+		if parsed[i] != recipe[i] {
+			t.Errorf("line %d = %+v, expected %+v", i, parsed[i], recipe[i])
+		}
+	}
+}
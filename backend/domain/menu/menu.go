@@ -19,23 +19,53 @@ type MenuItem struct {
 	Description string             `bson:"description" json:"description"`
 	Ingredients []Ingredient       `bson:"ingredients" json:"ingredients"`
 	Available   bool               `bson:"available" json:"available"`
-	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+	// AutoDisabled is set when the item was made unavailable because an ingredient ran out.
+	// Only auto-disabled items are re-enabled automatically when stock comes back.
+	AutoDisabled bool `bson:"auto_disabled" json:"auto_disabled"`
+	// RemainingPortions is computed from current stock for the waiter menu, nil if the recipe is not tracked
+	RemainingPortions *int `bson:"-" json:"remaining_portions,omitempty"`
+	// Photo of the item in display size and as a square thumbnail
+	ImageURL     string   `bson:"image_url" json:"image_url,omitempty"`
+	ThumbnailURL string   `bson:"thumbnail_url" json:"thumbnail_url,omitempty"`
+	ImageKeys    []string `bson:"image_keys" json:"-"` // storage keys, for cleanup on replace
+	// Translations of name and description keyed by language code (e.g. "en")
+	Translations map[string]Translation `bson:"translations" json:"translations,omitempty"`
+	// Costing is the last theoretical cost calculation of the recipe
+	Costing *CostSnapshot `bson:"costing,omitempty" json:"costing,omitempty"`
+	// Display fields for the requested language, not stored
+	DisplayName        string    `bson:"-" json:"display_name,omitempty"`
+	DisplayDescription string    `bson:"-" json:"display_description,omitempty"`
+	DisplayCategory    string    `bson:"-" json:"display_category,omitempty"`
+	Language           string    `bson:"-" json:"language,omitempty"`
+	CreatedAt          time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt          time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// CostSnapshot summarises the theoretical recipe cost and margin of a menu item
+type CostSnapshot struct {
+	Cost               float64   `bson:"cost" json:"cost"`
+	FoodCostPercent    float64   `bson:"food_cost_percent" json:"food_cost_percent"`
+	GrossMarginPercent float64   `bson:"gross_margin_percent" json:"gross_margin_percent"`
+	BelowTarget        bool      `bson:"below_target" json:"below_target"`
+	Complete           bool      `bson:"complete" json:"complete"`
+	CalculatedAt       time.Time `bson:"calculated_at" json:"calculated_at"`
 }
 
 type CreateMenuItemRequest struct {
-	Name        string       `json:"name" binding:"required"`
-	Price       float64      `json:"price" binding:"required,min=0"`
-	Category    string       `json:"category" binding:"required"`
-	Description string       `json:"description"`
-	Ingredients []Ingredient `json:"ingredients"`
+	Name         string                 `json:"name" binding:"required"`
+	Price        float64                `json:"price" binding:"required,min=0"`
+	Category     string                 `json:"category" binding:"required"`
+	Description  string                 `json:"description"`
+	Ingredients  []Ingredient           `json:"ingredients"`
+	Translations map[string]Translation `json:"translations"`
 }
 
 type UpdateMenuItemRequest struct {
-	Name        string       `json:"name"`
-	Price       float64      `json:"price" binding:"min=0"`
-	Category    string       `json:"category"`
-	Description string       `json:"description"`
-	Ingredients []Ingredient `json:"ingredients"`
-	Available   *bool        `json:"available"`
+	Name         string                 `json:"name"`
+	Price        float64                `json:"price" binding:"min=0"`
+	Category     string                 `json:"category"`
+	Description  string                 `json:"description"`
+	Ingredients  []Ingredient           `json:"ingredients"`
+	Available    *bool                  `json:"available"`
+	Translations map[string]Translation `json:"translations"`
 }
\ No newline at end of file
@@ -0,0 +1,155 @@
+package menu
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MenuSchedule restricts a set of menu items to given days of the week and a time window,
+// e.g. a breakfast menu served until 10:30. Items not covered by any schedule are always shown.
+type MenuSchedule struct {
+	ID          primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Name        string               `bson:"name" json:"name"`
+	DaysOfWeek  []time.Weekday       `bson:"days_of_week" json:"days_of_week"` // 0 = Sunday; empty means every day
+	StartTime   string               `bson:"start_time" json:"start_time"`     // HH:MM
+	EndTime     string               `bson:"end_time" json:"end_time"`         // HH:MM, before StartTime for overnight windows
+	MenuItemIDs []primitive.ObjectID `bson:"menu_item_ids" json:"menu_item_ids"`
+	Active      bool                 `bson:"active" json:"active"`
+	CreatedAt   time.Time            `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time            `bson:"updated_at" json:"updated_at"`
+}
+
+type MenuScheduleRequest struct {
+	Name        string         `json:"name" binding:"required"`
+	DaysOfWeek  []time.Weekday `json:"days_of_week"`
+	StartTime   string         `json:"start_time" binding:"required"`
+	EndTime     string         `json:"end_time" binding:"required"`
+	MenuItemIDs []string       `json:"menu_item_ids" binding:"required,min=1"`
+	Active      *bool          `json:"active"`
+}
+
+// ParseClock parses a HH:MM time of day into minutes since midnight
+func ParseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Validate checks the time window and days of the schedule
+func (s *MenuSchedule) Validate() error {
+	start, err := ParseClock(s.StartTime)
+	if err != nil {
+		return err
+	}
+	end, err := ParseClock(s.EndTime)
+	if err != nil {
+		return err
+	}
+	if start == end {
+		return errors.New("start_time and end_time must differ")
+	}
+	for _, d := range s.DaysOfWeek {
+		if d < time.Sunday || d > time.Saturday {
+			return fmt.Errorf("invalid day of week %d", d)
+		}
+	}
+	return nil
+}
+
+// IsActiveAt reports whether the schedule window contains t.
+// For overnight windows (end before start) the day is that of the window start.
+func (s *MenuSchedule) IsActiveAt(t time.Time) bool {
+	if !s.Active {
+		return false
+	}
+	start, err := ParseClock(s.StartTime)
+	if err != nil {
+		return false
+	}
+	end, err := ParseClock(s.EndTime)
+	if err != nil {
+		return false
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+
+	if start < end {
+		return minute >= start && minute < end && s.runsOn(day)
+	}
+	// Overnight window: evening part belongs to today, early part to yesterday
+	if minute >= start {
+		return s.runsOn(day)
+	}
+	if minute < end {
+		return s.runsOn((day + 6) % 7)
+	}
+	return false
+}
+
+func (s *MenuSchedule) runsOn(day time.Weekday) bool {
+	if len(s.DaysOfWeek) == 0 {
+		return true
+	}
+	for _, d := range s.DaysOfWeek {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// ScheduledVisibility returns, for every item covered by at least one active schedule,
+// whether one of its schedules is open at t. Items missing from the map are unscheduled.
+func ScheduledVisibility(schedules []*MenuSchedule, t time.Time) map[primitive.ObjectID]bool {
+	visible := make(map[primitive.ObjectID]bool)
+	for _, s := range schedules {
+		if !s.Active {
+			continue
+		}
+		open := s.IsActiveAt(t)
+		for _, id := range s.MenuItemIDs {
+			visible[id] = visible[id] || open
+		}
+	}
+	return visible
+}
+
+// PriceEntry schedules a price for a menu item from a given date
+type PriceEntry struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	MenuItemID    primitive.ObjectID `bson:"menu_item_id" json:"menu_item_id"`
+	Price         float64            `bson:"price" json:"price"`
+	EffectiveFrom time.Time          `bson:"effective_from" json:"effective_from"`
+	Note          string             `bson:"note" json:"note"`
+	CreatedBy     string             `bson:"created_by" json:"created_by"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+}
+
+type CreatePriceEntryRequest struct {
+	Price         float64   `json:"price" binding:"required,gt=0"`
+	EffectiveFrom time.Time `json:"effective_from" binding:"required"`
+	Note          string    `json:"note"`
+}
+
+// ResolvePrice returns the price in effect at t: the latest entry that has become
+// effective, or the base price if none has
+func ResolvePrice(base float64, entries []*PriceEntry, t time.Time) float64 {
+	price := base
+	var latest time.Time
+	for _, e := range entries {
+		if e.EffectiveFrom.After(t) {
+			continue
+		}
+		if latest.IsZero() || !e.EffectiveFrom.Before(latest) {
+			latest = e.EffectiveFrom
+			price = e.Price
+		}
+	}
+	return price
+}
@@ -0,0 +1,69 @@
+package menu
+
+import (
+	"testing"
+	"time"
+)
+
+// TestMenuScheduleIsActiveAt tests schedule windows including overnight ones
+func TestMenuScheduleIsActiveAt(t *testing.T) {
+	// 2024-01-01 is a Monday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 1, day, hour, minute, 0, 0, time.Local)
+	}
+
+	breakfast := &MenuSchedule{StartTime: "06:00", EndTime: "10:30", Active: true}
+	weekdays := &MenuSchedule{
+		StartTime:  "06:00",
+		EndTime:    "10:30",
+		DaysOfWeek: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+		Active:     true,
+	}
+	lateNight := &MenuSchedule{StartTime: "22:00", EndTime: "02:00", DaysOfWeek: []time.Weekday{time.Saturday}, Active: true}
+	inactive := &MenuSchedule{StartTime: "00:00", EndTime: "23:59", Active: false}
+
+	tests := []struct {
+		name     string
+		schedule *MenuSchedule
+		time     time.Time
+		expected bool
+	}{
+		{"Inside breakfast window", breakfast, at(1, 8, 0), true},
+		{"End time is exclusive", breakfast, at(1, 10, 30), false},
+		{"Before breakfast window", breakfast, at(1, 5, 59), false},
+		{"Weekday schedule on Monday", weekdays, at(1, 7, 0), true},
+		{"Weekday schedule on Sunday", weekdays, at(7, 7, 0), false},
+		{"Overnight window on its start day", lateNight, at(6, 23, 0), true},
+		{"Overnight window after midnight", lateNight, at(7, 1, 0), true},
+		{"Overnight window after midnight on wrong day", lateNight, at(6, 1, 0), false},
+		{"Inactive schedule", inactive, at(1, 12, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.IsActiveAt(tt.time); got != tt.expected {
+				t.Errorf("IsActiveAt() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestResolvePrice tests that the latest effective price entry wins
+func TestResolvePrice(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.Local)
+	entries := []*PriceEntry{
+		{Price: 32000, EffectiveFrom: time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local)},
+		{Price: 35000, EffectiveFrom: time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)},
+		{Price: 38000, EffectiveFrom: time.Date(2024, 4, 1, 0, 0, 0, 0, time.Local)},
+	}
+
+	if got := ResolvePrice(30000, entries, now); got != 35000 {
+		t.Errorf("ResolvePrice() = %v, expected 35000", got)
+	}
+	if got := ResolvePrice(30000, entries, time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)); got != 30000 {
+		t.Errorf("ResolvePrice() before any entry = %v, expected base price 30000", got)
+	}
+	if got := ResolvePrice(30000, nil, now); got != 30000 {
+		t.Errorf("ResolvePrice() without entries = %v, expected base price 30000", got)
+	}
+}
@@ -1,10 +1,14 @@
 package order
 
 import (
+	"errors"
 	"time"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// ErrOverrideTokenUsed is returned when a manager override token was already used for another order
+var ErrOverrideTokenUsed = errors.New("manager override token has already been used for another order")
+
 type OrderStatus string
 
 const (
@@ -34,6 +38,10 @@ type OrderItem struct {
 	Quantity    int                `bson:"quantity" json:"quantity"`
 	Note        string             `bson:"note,omitempty" json:"note,omitempty"`
 	Subtotal    float64            `bson:"subtotal" json:"subtotal"`
+	// OverridePrice is a price requested by staff instead of the menu price, requires a manager override token
+	OverridePrice     *float64 `bson:"-" json:"override_price,omitempty"`
+	MenuPrice         float64  `bson:"menu_price,omitempty" json:"menu_price,omitempty"` // menu price when overridden
+	PriceOverriddenBy string   `bson:"price_overridden_by,omitempty" json:"price_overridden_by,omitempty"`
 }
 
 type Order struct {
@@ -67,6 +75,9 @@ type Order struct {
 	ReadyAt         *time.Time         `bson:"ready_at,omitempty" json:"ready_at,omitempty"`
 	ServedAt        *time.Time         `bson:"served_at,omitempty" json:"served_at,omitempty"`
 	LockedAt        *time.Time         `bson:"locked_at,omitempty" json:"locked_at,omitempty"`
+	// OverrideTokenIDs are the manager override tokens used for this order's prices; a token
+	// cannot be used for another order
+	OverrideTokenIDs []string `bson:"override_token_ids,omitempty" json:"-"`
 }
 
 type CreateOrderRequest struct {
@@ -75,6 +86,8 @@ type CreateOrderRequest struct {
 	Note         string      `json:"note"`
 	WaiterID     string      `json:"waiter_id"`
 	ShiftID      string      `json:"shift_id"`
+	// OverrideToken is a manager authorisation required when any item has an override price
+	OverrideToken string `json:"override_token"`
 }
 
 type PaymentRequest struct {
@@ -85,9 +98,10 @@ type PaymentRequest struct {
 }
 
 type EditOrderRequest struct {
-	Items    []OrderItem `json:"items" binding:"required,min=1"`
-	Discount float64     `json:"discount"`
-	Note     string      `json:"note"`
+	Items         []OrderItem `json:"items" binding:"required,min=1"`
+	Discount      float64     `json:"discount"`
+	Note          string      `json:"note"`
+	OverrideToken string      `json:"override_token"`
 }
 
 type EditOrderResponse struct {
@@ -0,0 +1,200 @@
+package order
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ReceiptWidth is the default number of characters per line (58mm thermal paper)
+const ReceiptWidth = 32
+
+// receiptLabels holds receipt captions per language. Languages without captions use English.
+var receiptLabels = map[string]map[string]string{
+	"vi": {
+		"title":    "HÓA ĐƠN",
+		"order":    "Số",
+		"date":     "Ngày",
+		"customer": "Khách",
+		"staff":    "NV",
+		"subtotal": "Tạm tính",
+		"discount": "Giảm giá",
+		"total":    "Tổng cộng",
+		"paid":     "Đã trả",
+		"due":      "Còn lại",
+		"refund":   "Hoàn tiền",
+		"thanks":   "Cảm ơn quý khách!",
+	},
+	"en": {
+		"title":    "RECEIPT",
+		"order":    "No.",
+		"date":     "Date",
+		"customer": "Guest",
+		"staff":    "Staff",
+		"subtotal": "Subtotal",
+		"discount": "Discount",
+		"total":    "Total",
+		"paid":     "Paid",
+		"due":      "Due",
+		"refund":   "Refund",
+		"thanks":   "Thank you!",
+	},
+}
+
+type ReceiptLine struct {
+	Name           string  `json:"name"`
+	TranslatedName string  `json:"translated_name,omitempty"`
+	Quantity       int     `json:"quantity"`
+	Price          float64 `json:"price"`
+	Subtotal       float64 `json:"subtotal"`
+}
+
+// Receipt is a printable summary of an order in Vietnamese and optionally a second language
+type Receipt struct {
+	OrderNumber  string        `json:"order_number"`
+	CustomerName string        `json:"customer_name,omitempty"`
+	WaiterName   string        `json:"waiter_name"`
+	CreatedAt    time.Time     `json:"created_at"`
+	Language     string        `json:"language"` // second language, "vi" for Vietnamese only
+	Lines        []ReceiptLine `json:"lines"`
+	Subtotal     float64       `json:"subtotal"`
+	Discount     float64       `json:"discount"`
+	Total        float64       `json:"total"`
+	AmountPaid   float64       `json:"amount_paid"`
+	AmountDue    float64       `json:"amount_due"`
+	RefundAmount float64       `json:"refund_amount,omitempty"`
+	Text         string        `json:"text"`
+}
+
+// NewReceipt builds the receipt of an order. translate returns the item name in the
+// second language, or an empty string if there is no translation.
+func NewReceipt(o *Order, lang string, translate func(item OrderItem) string) *Receipt {
+	r := &Receipt{
+		OrderNumber:  o.OrderNumber,
+		CustomerName: o.CustomerName,
+		WaiterName:   o.WaiterName,
+		CreatedAt:    o.CreatedAt,
+		Language:     lang,
+		Subtotal:     o.Subtotal,
+		Discount:     o.Discount,
+		Total:        o.Total,
+		AmountPaid:   o.AmountPaid,
+		AmountDue:    o.AmountDue,
+		RefundAmount: o.RefundAmount,
+	}
+
+	for _, item := range o.Items {
+		line := ReceiptLine{
+			Name:     item.Name,
+			Quantity: item.Quantity,
+			Price:    item.Price,
+			Subtotal: item.Subtotal,
+		}
+		if lang != "vi" && translate != nil {
+			if name := translate(item); name != "" && name != item.Name {
+				line.TranslatedName = name
+			}
+		}
+		r.Lines = append(r.Lines, line)
+	}
+
+	r.Text = r.Render(ReceiptWidth)
+	return r
+}
+
+// label returns a caption in Vietnamese followed by the second language, e.g. "Tổng cộng / Total"
+func (r *Receipt) label(key string) string {
+	vi := receiptLabels["vi"][key]
+	if r.Language == "vi" {
+		return vi
+	}
+	second, ok := receiptLabels[r.Language]
+	if !ok {
+		second = receiptLabels["en"]
+	}
+	return vi + " / " + second[key]
+}
+
+// Render formats the receipt as plain text for a printer with the given line width
+func (r *Receipt) Render(width int) string {
+	var b strings.Builder
+	rule := strings.Repeat("-", width)
+
+	for _, part := range strings.Split(r.label("title"), " / ") {
+		b.WriteString(center(part, width) + "\n")
+	}
+	b.WriteString(r.label("order") + ": " + r.OrderNumber + "\n")
+	b.WriteString(r.label("date") + ": " + r.CreatedAt.Local().Format("02/01/2006 15:04") + "\n")
+	if r.CustomerName != "" {
+		b.WriteString(r.label("customer") + ": " + r.CustomerName + "\n")
+	}
+	b.WriteString(r.label("staff") + ": " + r.WaiterName + "\n")
+	b.WriteString(rule + "\n")
+
+	for _, line := range r.Lines {
+		b.WriteString(line.Name + "\n")
+		if line.TranslatedName != "" {
+			b.WriteString("  (" + line.TranslatedName + ")\n")
+		}
+		b.WriteString(columns(fmt.Sprintf("  %d x %s", line.Quantity, FormatVND(line.Price)), FormatVND(line.Subtotal), width) + "\n")
+	}
+	b.WriteString(rule + "\n")
+
+	b.WriteString(columns(r.label("subtotal"), FormatVND(r.Subtotal), width) + "\n")
+	if r.Discount > 0 {
+		b.WriteString(columns(r.label("discount"), "-"+FormatVND(r.Discount), width) + "\n")
+	}
+	b.WriteString(columns(r.label("total"), FormatVND(r.Total), width) + "\n")
+	b.WriteString(columns(r.label("paid"), FormatVND(r.AmountPaid), width) + "\n")
+	if r.AmountDue > 0 {
+		b.WriteString(columns(r.label("due"), FormatVND(r.AmountDue), width) + "\n")
+	}
+	if r.RefundAmount > 0 {
+		b.WriteString(columns(r.label("refund"), FormatVND(r.RefundAmount), width) + "\n")
+	}
+	b.WriteString(rule + "\n")
+
+	for _, part := range strings.Split(r.label("thanks"), " / ") {
+		b.WriteString(center(part, width) + "\n")
+	}
+	return b.String()
+}
+
+// FormatVND formats an amount with dot thousand separators, e.g. 1234567 -> "1.234.567đ"
+func FormatVND(amount float64) string {
+	negative := amount < 0
+	if negative {
+		amount = -amount
+	}
+	digits := fmt.Sprintf("%.0f", amount)
+
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(d)
+	}
+	if negative {
+		return "-" + b.String() + "đ"
+	}
+	return b.String() + "đ"
+}
+
+// columns puts left and right on one line, wrapping the right part if they do not fit
+func columns(left, right string, width int) string {
+	gap := width - utf8.RuneCountInString(left) - utf8.RuneCountInString(right)
+	if gap < 1 {
+		return left + "\n" + strings.Repeat(" ", max(width-utf8.RuneCountInString(right), 0)) + right
+	}
+	return left + strings.Repeat(" ", gap) + right
+}
+
+func center(s string, width int) string {
+	pad := (width - utf8.RuneCountInString(s)) / 2
+	if pad <= 0 {
+		return s
+	}
+	return strings.Repeat(" ", pad) + s
+}
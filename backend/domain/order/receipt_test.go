@@ -0,0 +1,58 @@
+package order
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestFormatVND tests thousand separators of receipt amounts
+func TestFormatVND(t *testing.T) {
+	tests := map[float64]string{
+		0:        "0đ",
+		500:      "500đ",
+		30000:    "30.000đ",
+		1234567:  "1.234.567đ",
+		-45000.4: "-45.000đ",
+	}
+	for amount, expected := range tests {
+		if got := FormatVND(amount); got != expected {
+			t.Errorf("FormatVND(%v) = %q, expected %q", amount, got, expected)
+		}
+	}
+}
+
+// TestNewReceiptBilingual tests that captions and item names are printed in both languages
+func TestNewReceiptBilingual(t *testing.T) {
+	o := &Order{
+		OrderNumber: "20240101-080000-001",
+		WaiterName:  "lan",
+		Items: []OrderItem{
+			{Name: "Cà phê sữa", Price: 30000, Quantity: 2, Subtotal: 60000},
+			{Name: "Bánh mì", Price: 20000, Quantity: 1, Subtotal: 20000},
+		},
+		Subtotal:   80000,
+		Total:      80000,
+		AmountPaid: 80000,
+	}
+	translate := func(item OrderItem) string {
+		if item.Name == "Cà phê sữa" {
+			return "Iced milk coffee"
+		}
+		return ""
+	}
+
+	receipt := NewReceipt(o, "en", translate)
+	if receipt.Lines[0].TranslatedName != "Iced milk coffee" || receipt.Lines[1].TranslatedName != "" {
+		t.Errorf("unexpected translated names: %+v", receipt.Lines)
+	}
+	for _, want := range []string{"Tổng cộng / Total", "(Iced milk coffee)", "80.000đ", "Thank you!"} {
+		if !strings.Contains(receipt.Text, want) {
+			t.Errorf("receipt text missing %q:\n%s", want, receipt.Text)
+		}
+	}
+
+	viOnly := NewReceipt(o, "vi", translate)
+	if strings.Contains(viOnly.Text, "Total") || strings.Contains(viOnly.Text, "Iced milk coffee") {
+		t.Errorf("Vietnamese-only receipt contains English text:\n%s", viOnly.Text)
+	}
+}
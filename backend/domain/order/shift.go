@@ -13,6 +13,8 @@ const (
 	ShiftClosed ShiftStatus = "CLOSED"
 )
 
+// ShiftType is the code of a shift type. The types and their time windows are configured per
+// store; these are the defaults.
 type ShiftType string
 
 const (
@@ -21,6 +23,27 @@ const (
 	ShiftEvening   ShiftType = "EVENING"
 )
 
+// BreakType tells whether a break counts as paid working time
+type BreakType string
+
+const (
+	BreakPaid   BreakType = "PAID"
+	BreakUnpaid BreakType = "UNPAID"
+)
+
+// IsValid checks if the BreakType is paid or unpaid
+func (t BreakType) IsValid() bool {
+	return t == BreakPaid || t == BreakUnpaid
+}
+
+// ShiftBreak is a break taken during a shift
+type ShiftBreak struct {
+	Type      BreakType  `bson:"type" json:"type"`
+	StartedAt time.Time  `bson:"started_at" json:"started_at"`
+	EndedAt   *time.Time `bson:"ended_at,omitempty" json:"ended_at,omitempty"`
+	Notes     string     `bson:"notes,omitempty" json:"notes,omitempty"`
+}
+
 type RoleType string
 
 const (
@@ -77,6 +100,17 @@ type Shift struct {
 	RemainingCash    float64 `bson:"remaining_cash" json:"remaining_cash"`       // Cash remaining after handovers
 	TotalDiscrepancy float64 `bson:"total_discrepancy" json:"total_discrepancy"` // Total discrepancy from all handovers
 	HandoverCount    int     `bson:"handover_count" json:"handover_count"`       // Number of handovers made
+
+	// Roster shift this shift was started for, if any
+	PlannedShiftID *primitive.ObjectID `bson:"planned_shift_id,omitempty" json:"planned_shift_id,omitempty"`
+
+	// Set when the shift was left open and ended without the staff member; the end time and
+	// cash stay provisional until a manager reconciles them
+	ForceEnded             bool `bson:"force_ended,omitempty" json:"force_ended,omitempty"`
+	ReconciliationRequired bool `bson:"reconciliation_required,omitempty" json:"reconciliation_required,omitempty"`
+
+	// Breaks taken during the shift; unpaid ones are not counted as hours worked
+	Breaks []ShiftBreak `bson:"breaks,omitempty" json:"breaks,omitempty"`
 	
 	StartedAt     time.Time          `bson:"started_at" json:"started_at"`
 	EndedAt       *time.Time         `bson:"ended_at,omitempty" json:"ended_at,omitempty"`
@@ -85,12 +119,17 @@ type Shift struct {
 }
 
 type StartShiftRequest struct {
-	Type      ShiftType `json:"type" binding:"required"`
+	Type      ShiftType `json:"type"` // derived from the start time if empty
 	StartCash float64   `json:"start_cash" binding:"min=0"`
 	UserID    string    `json:"user_id"`
 	RoleType  RoleType  `json:"role_type"`
 }
 
+type StartBreakRequest struct {
+	Type  BreakType `json:"type" binding:"required"`
+	Notes string    `json:"notes"`
+}
+
 type EndShiftRequest struct {
 	EndCash float64 `json:"end_cash" binding:"min=0"`
 }
@@ -125,3 +164,44 @@ func (s *Shift) CanHandover(amount float64) error {
 func (s *Shift) GetAvailableCash() float64 {
 	return s.RemainingCash
 }
+
+// CurrentBreak returns the break in progress, or nil
+func (s *Shift) CurrentBreak() *ShiftBreak {
+	if n := len(s.Breaks); n > 0 && s.Breaks[n-1].EndedAt == nil {
+		return &s.Breaks[n-1]
+	}
+	return nil
+}
+
+// OnBreak reports whether the staff member is on a break
+func (s *Shift) OnBreak() bool {
+	return s.CurrentBreak() != nil
+}
+
+// StartBreak starts a break at now
+func (s *Shift) StartBreak(breakType BreakType, notes string, now time.Time) error {
+	if s.Status != ShiftOpen {
+		return errors.New("breaks can only be taken during an open shift")
+	}
+	if !breakType.IsValid() {
+		return errors.New("invalid break type: must be PAID or UNPAID")
+	}
+	if s.OnBreak() {
+		return errors.New("already on a break")
+	}
+	s.Breaks = append(s.Breaks, ShiftBreak{Type: breakType, StartedAt: now, Notes: notes})
+	return nil
+}
+
+// EndBreak ends the break in progress at now
+func (s *Shift) EndBreak(now time.Time) error {
+	current := s.CurrentBreak()
+	if current == nil {
+		return errors.New("not on a break")
+	}
+	if now.Before(current.StartedAt) {
+		now = current.StartedAt
+	}
+	current.EndedAt = &now
+	return nil
+}
@@ -2,6 +2,7 @@ package order
 
 import (
 	"testing"
+	"time"
 )
 
 // TestParseRoleType tests the ParseRoleType function
@@ -22,9 +23,9 @@ func TestParseRoleType(t *testing.T) {
 			expected: RoleBarista,
 		},
 		{
-			name:     "Parse cashier role",
+			name:     "Parse cashier role (cashier shifts live in the cashier domain, defaults to waiter)",
 			input:    "cashier",
-			expected: RoleCashier,
+			expected: RoleWaiter,
 		},
 		{
 			name:     "Parse manager role (should default to waiter)",
@@ -76,9 +77,9 @@ func TestRoleType_IsValid(t *testing.T) {
 			expected: true,
 		},
 		{
-			name:     "Cashier role is valid",
-			roleType: RoleCashier,
-			expected: true,
+			name:     "Cashier role is invalid (handled by the cashier domain)",
+			roleType: RoleType("cashier"),
+			expected: false,
 		},
 		{
 			name:     "Empty role is invalid",
@@ -124,11 +125,6 @@ func TestRoleType_String(t *testing.T) {
 			roleType: RoleBarista,
 			expected: "barista",
 		},
-		{
-			name:     "Cashier role to string",
-			roleType: RoleCashier,
-			expected: "cashier",
-		},
 		{
 			name:     "Empty role to string",
 			roleType: RoleType(""),
@@ -174,9 +170,9 @@ func TestParseRoleType_FromUserRole(t *testing.T) {
 			expected: RoleBarista,
 		},
 		{
-			name:     "Convert user.Role cashier to order.RoleType",
+			name:     "Convert user.Role cashier to order.RoleType (defaults to waiter)",
 			userRole: UserRoleCashier,
-			expected: RoleCashier,
+			expected: RoleWaiter,
 		},
 		{
 			name:     "Convert user.Role manager to order.RoleType (defaults to waiter)",
@@ -252,6 +248,73 @@ func TestRoleTypeConversion_WrongAssertion(t *testing.T) {
 	_ = contextValue.(string)
 }
 
+// TestShiftBreaks tests starting and ending breaks during a shift
+func TestShiftBreaks(t *testing.T) {
+	start := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	t.Run("start and end a break", func(t *testing.T) {
+		s := &Shift{Status: ShiftOpen, StartedAt: start}
+		if s.OnBreak() {
+			t.Fatal("new shift should not be on a break")
+		}
+		if err := s.StartBreak(BreakUnpaid, "lunch", start.Add(time.Hour)); err != nil {
+			t.Fatalf("StartBreak: %v", err)
+		}
+		if !s.OnBreak() || s.CurrentBreak().Type != BreakUnpaid {
+			t.Fatalf("expected an unpaid break in progress, got %+v", s.Breaks)
+		}
+		if err := s.StartBreak(BreakPaid, "", start.Add(90*time.Minute)); err == nil {
+			t.Error("expected an error starting a second break")
+		}
+		if err := s.EndBreak(start.Add(2 * time.Hour)); err != nil {
+			t.Fatalf("EndBreak: %v", err)
+		}
+		if s.OnBreak() {
+			t.Error("break should have ended")
+		}
+		if got := *s.Breaks[0].EndedAt; !got.Equal(start.Add(2 * time.Hour)) {
+			t.Errorf("EndedAt = %v, want %v", got, start.Add(2*time.Hour))
+		}
+	})
+
+	t.Run("end before start is clamped", func(t *testing.T) {
+		s := &Shift{Status: ShiftOpen, StartedAt: start}
+		s.StartBreak(BreakPaid, "", start.Add(time.Hour))
+		if err := s.EndBreak(start); err != nil {
+			t.Fatalf("EndBreak: %v", err)
+		}
+		if got := *s.Breaks[0].EndedAt; !got.Equal(start.Add(time.Hour)) {
+			t.Errorf("EndedAt = %v, want the break start", got)
+		}
+	})
+
+	tests := []struct {
+		name      string
+		shift     *Shift
+		breakType BreakType
+	}{
+		{"closed shift", &Shift{Status: ShiftClosed}, BreakPaid},
+		{"invalid break type", &Shift{Status: ShiftOpen}, BreakType("LONG")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.shift.StartBreak(tt.breakType, "", start); err == nil {
+				t.Error("expected StartBreak to fail")
+			}
+			if tt.shift.OnBreak() {
+				t.Error("shift should not be on a break")
+			}
+		})
+	}
+
+	t.Run("end without a break", func(t *testing.T) {
+		s := &Shift{Status: ShiftOpen}
+		if err := s.EndBreak(start); err == nil {
+			t.Error("expected an error ending a break that was not started")
+		}
+	})
+}
+
 // BenchmarkParseRoleType benchmarks the ParseRoleType function
 func BenchmarkParseRoleType(b *testing.B) {
 	roles := []string{"waiter", "barista", "cashier", "manager", "invalid"}
@@ -265,7 +328,7 @@ func BenchmarkParseRoleType(b *testing.B) {
 
 // BenchmarkRoleType_IsValid benchmarks the IsValid method
 func BenchmarkRoleType_IsValid(b *testing.B) {
-	roleTypes := []RoleType{RoleWaiter, RoleBarista, RoleCashier, RoleType("invalid")}
+	roleTypes := []RoleType{RoleWaiter, RoleBarista, RoleType("cashier"), RoleType("invalid")}
 	
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -0,0 +1,179 @@
+package order
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Default SLA targets used when no target is configured for a menu category
+const (
+	DefaultMaxWaitMinutes  = 5.0
+	DefaultMaxPrepMinutes  = 10.0
+	DefaultMaxServeMinutes = 5.0
+)
+
+// OrderTimings holds the durations of the preparation lifecycle of an order in minutes.
+// A stage that has started but not finished is measured up to the reference time.
+type OrderTimings struct {
+	WaitMinutes  *float64 `json:"wait_minutes,omitempty"`  // QueuedAt -> AcceptedAt
+	PrepMinutes  *float64 `json:"prep_minutes,omitempty"`  // AcceptedAt -> ReadyAt
+	ServeMinutes *float64 `json:"serve_minutes,omitempty"` // ReadyAt -> ServedAt
+	TotalMinutes *float64 `json:"total_minutes,omitempty"` // QueuedAt -> ServedAt
+}
+
+// Timings computes wait, preparation and serve durations of the order.
+// Stages still in progress are measured against now.
+func (o *Order) Timings(now time.Time) OrderTimings {
+	var t OrderTimings
+	if o.QueuedAt != nil {
+		t.WaitMinutes = stageMinutes(*o.QueuedAt, o.AcceptedAt, now)
+		t.TotalMinutes = stageMinutes(*o.QueuedAt, o.ServedAt, now)
+	}
+	if o.AcceptedAt != nil {
+		t.PrepMinutes = stageMinutes(*o.AcceptedAt, o.ReadyAt, now)
+	}
+	if o.ReadyAt != nil {
+		t.ServeMinutes = stageMinutes(*o.ReadyAt, o.ServedAt, now)
+	}
+	return t
+}
+
+// PrepDuration returns the completed preparation time, or false if the order is not ready yet
+func (o *Order) PrepDuration() (time.Duration, bool) {
+	if o.AcceptedAt == nil || o.ReadyAt == nil {
+		return 0, false
+	}
+	return o.ReadyAt.Sub(*o.AcceptedAt), true
+}
+
+func stageMinutes(start time.Time, end *time.Time, now time.Time) *float64 {
+	stop := now
+	if end != nil {
+		stop = *end
+	}
+	minutes := stop.Sub(start).Minutes()
+	if minutes < 0 {
+		minutes = 0
+	}
+	return &minutes
+}
+
+// SLATarget defines the maximum allowed duration of each preparation stage for a menu category.
+// A target with an empty category is the store-wide default.
+type SLATarget struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Category        string             `bson:"category" json:"category"`
+	MaxWaitMinutes  float64            `bson:"max_wait_minutes" json:"max_wait_minutes"`
+	MaxPrepMinutes  float64            `bson:"max_prep_minutes" json:"max_prep_minutes"`
+	MaxServeMinutes float64            `bson:"max_serve_minutes" json:"max_serve_minutes"`
+	CreatedAt       time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+type SLATargetRequest struct {
+	Category        string  `json:"category"`
+	MaxWaitMinutes  float64 `json:"max_wait_minutes" binding:"required,gt=0"`
+	MaxPrepMinutes  float64 `json:"max_prep_minutes" binding:"required,gt=0"`
+	MaxServeMinutes float64 `json:"max_serve_minutes" binding:"required,gt=0"`
+}
+
+// DefaultSLATarget returns the built-in target used when nothing is configured
+func DefaultSLATarget() *SLATarget {
+	return &SLATarget{
+		MaxWaitMinutes:  DefaultMaxWaitMinutes,
+		MaxPrepMinutes:  DefaultMaxPrepMinutes,
+		MaxServeMinutes: DefaultMaxServeMinutes,
+	}
+}
+
+// Strictest combines two targets keeping the shortest limit of each stage.
+// Used for orders mixing items from several categories.
+func (t *SLATarget) Strictest(other *SLATarget) *SLATarget {
+	if other == nil {
+		return t
+	}
+	return &SLATarget{
+		Category:        t.Category,
+		MaxWaitMinutes:  math.Min(t.MaxWaitMinutes, other.MaxWaitMinutes),
+		MaxPrepMinutes:  math.Min(t.MaxPrepMinutes, other.MaxPrepMinutes),
+		MaxServeMinutes: math.Min(t.MaxServeMinutes, other.MaxServeMinutes),
+	}
+}
+
+// LateOrder describes an order whose current stage has exceeded its SLA target
+type LateOrder struct {
+	Order          *Order       `json:"order"`
+	Stage          OrderStatus  `json:"stage"`
+	ElapsedMinutes float64      `json:"elapsed_minutes"`
+	TargetMinutes  float64      `json:"target_minutes"`
+	OverdueMinutes float64      `json:"overdue_minutes"`
+	Timings        OrderTimings `json:"timings"`
+}
+
+// CheckLate evaluates the order's current stage against the target.
+// Returns nil if the order is on time or not in a tracked stage.
+func (t *SLATarget) CheckLate(o *Order, now time.Time) *LateOrder {
+	var start *time.Time
+	var target float64
+
+	switch o.Status {
+	case StatusQueued:
+		start, target = o.QueuedAt, t.MaxWaitMinutes
+	case StatusInProgress:
+		start, target = o.AcceptedAt, t.MaxPrepMinutes
+	case StatusReady:
+		start, target = o.ReadyAt, t.MaxServeMinutes
+	default:
+		return nil
+	}
+	if start == nil {
+		return nil
+	}
+
+	elapsed := now.Sub(*start).Minutes()
+	if elapsed <= target {
+		return nil
+	}
+
+	return &LateOrder{
+		Order:          o,
+		Stage:          o.Status,
+		ElapsedMinutes: elapsed,
+		TargetMinutes:  target,
+		OverdueMinutes: elapsed - target,
+		Timings:        o.Timings(now),
+	}
+}
+
+// DurationStats summarises a set of durations in minutes
+type DurationStats struct {
+	Count          int     `json:"count"`
+	AverageMinutes float64 `json:"average_minutes"`
+	P90Minutes     float64 `json:"p90_minutes"`
+}
+
+// NewDurationStats computes the average and 90th percentile (nearest-rank) of the given durations
+func NewDurationStats(minutes []float64) DurationStats {
+	stats := DurationStats{Count: len(minutes)}
+	if len(minutes) == 0 {
+		return stats
+	}
+
+	sorted := make([]float64, len(minutes))
+	copy(sorted, minutes)
+	sort.Float64s(sorted)
+
+	total := 0.0
+	for _, m := range sorted {
+		total += m
+	}
+	stats.AverageMinutes = total / float64(len(sorted))
+
+	rank := int(math.Ceil(0.9 * float64(len(sorted))))
+	stats.P90Minutes = sorted[rank-1]
+
+	return stats
+}
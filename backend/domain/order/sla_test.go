@@ -0,0 +1,131 @@
+package order
+
+import (
+	"testing"
+	"time"
+)
+
+// TestNewDurationStats tests the average and the nearest-rank 90th percentile
+func TestNewDurationStats(t *testing.T) {
+	upTo := func(n int) []float64 {
+		minutes := make([]float64, n)
+		for i := range minutes {
+			minutes[i] = float64(n - i) // descending, so the input has to be sorted
+		}
+		return minutes
+	}
+
+	tests := []struct {
+		name    string
+		minutes []float64
+		want    DurationStats
+	}{
+		{"empty", nil, DurationStats{}},
+		{"single sample", []float64{7}, DurationStats{Count: 1, AverageMinutes: 7, P90Minutes: 7}},
+		{"two samples use the larger", []float64{4, 2}, DurationStats{Count: 2, AverageMinutes: 3, P90Minutes: 4}},
+		{"rank 9 of 10", upTo(10), DurationStats{Count: 10, AverageMinutes: 5.5, P90Minutes: 9}},
+		{"rank 10 of 11", upTo(11), DurationStats{Count: 11, AverageMinutes: 6, P90Minutes: 10}},
+		{"rank 18 of 20", upTo(20), DurationStats{Count: 20, AverageMinutes: 10.5, P90Minutes: 18}},
+		{"rank 90 of 100", upTo(100), DurationStats{Count: 100, AverageMinutes: 50.5, P90Minutes: 90}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewDurationStats(tt.minutes); got != tt.want {
+				t.Errorf("NewDurationStats = %+v, expected %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSLATargetStrictest tests combining targets of several categories
+func TestSLATargetStrictest(t *testing.T) {
+	drinks := &SLATarget{Category: "drinks", MaxWaitMinutes: 3, MaxPrepMinutes: 8, MaxServeMinutes: 5}
+	food := &SLATarget{Category: "food", MaxWaitMinutes: 5, MaxPrepMinutes: 15, MaxServeMinutes: 2}
+
+	tests := []struct {
+		name  string
+		other *SLATarget
+		want  SLATarget
+	}{
+		{"no other target", nil, *drinks},
+		{"shortest of each stage", food, SLATarget{Category: "drinks", MaxWaitMinutes: 3, MaxPrepMinutes: 8, MaxServeMinutes: 2}},
+		{"same target", drinks, *drinks},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := drinks.Strictest(tt.other); *got != tt.want {
+				t.Errorf("Strictest = %+v, expected %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSLATargetCheckLate tests the current stage of an order against its target
+func TestSLATargetCheckLate(t *testing.T) {
+	queued := time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC)
+	accepted := queued.Add(2 * time.Minute)
+	ready := accepted.Add(9 * time.Minute)
+	target := DefaultSLATarget()
+
+	tests := []struct {
+		name    string
+		order   *Order
+		now     time.Time
+		late    bool
+		overdue float64
+	}{
+		{"queued within the wait target", &Order{Status: StatusQueued, QueuedAt: &queued}, queued.Add(5 * time.Minute), false, 0},
+		{"queued too long", &Order{Status: StatusQueued, QueuedAt: &queued}, queued.Add(8 * time.Minute), true, 3},
+		{"preparing on time", &Order{Status: StatusInProgress, QueuedAt: &queued, AcceptedAt: &accepted}, accepted.Add(9 * time.Minute), false, 0},
+		{"preparing too long", &Order{Status: StatusInProgress, QueuedAt: &queued, AcceptedAt: &accepted}, accepted.Add(12 * time.Minute), true, 2},
+		{"ready but not served", &Order{Status: StatusReady, QueuedAt: &queued, AcceptedAt: &accepted, ReadyAt: &ready}, ready.Add(6 * time.Minute), true, 1},
+		{"stage without its timestamp", &Order{Status: StatusInProgress, QueuedAt: &queued}, queued.Add(time.Hour), false, 0},
+		{"served orders are not tracked", &Order{Status: StatusServed, QueuedAt: &queued}, queued.Add(time.Hour), false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := target.CheckLate(tt.order, tt.now)
+			if !tt.late {
+				if got != nil {
+					t.Errorf("Expected on time, got %+v", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("Expected the order to be late")
+			}
+			if got.Stage != tt.order.Status || got.OverdueMinutes != tt.overdue || got.ElapsedMinutes-got.TargetMinutes != tt.overdue {
+				t.Errorf("Expected %v minutes overdue in %s, got %+v", tt.overdue, tt.order.Status, got)
+			}
+		})
+	}
+}
+
+// TestPrepDuration tests the completed preparation time
+func TestPrepDuration(t *testing.T) {
+	accepted := time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC)
+	ready := accepted.Add(7*time.Minute + 30*time.Second)
+
+	tests := []struct {
+		name  string
+		order *Order
+		want  time.Duration
+		ok    bool
+	}{
+		{"no timestamps", &Order{}, 0, false},
+		{"not ready yet", &Order{AcceptedAt: &accepted}, 0, false},
+		{"ready", &Order{AcceptedAt: &accepted, ReadyAt: &ready}, 7*time.Minute + 30*time.Second, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.order.PrepDuration()
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("PrepDuration = %v %v, expected %v %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -16,10 +16,10 @@ func TestShiftStateMachine_ValidTransitions(t *testing.T) {
 		wantErr   bool
 	}{
 		// Valid transitions
-		{"Start shift", ShiftOpen, EventStartShift, ShiftOpen, false},
 		{"End shift", ShiftOpen, EventEndShift, ShiftClosed, false},
 		
-		// Invalid transitions
+		// Invalid transitions (starting a shift is checked by ValidateShiftStart)
+		{"Cannot start an open shift again", ShiftOpen, EventStartShift, "", true},
 		{"Cannot end closed shift", ShiftClosed, EventEndShift, "", true},
 	}
 
@@ -142,12 +142,12 @@ func TestShiftStateMachine_GetShiftDuration(t *testing.T) {
 		want  float64
 	}{
 		{
-			name: "Open shift 1 hour",
+			name: "Open shift has no duration yet",
 			shift: &Shift{
 				Status:    ShiftOpen,
 				StartedAt: now.Add(-1 * time.Hour),
 			},
-			want: 1.0,
+			want: 0,
 		},
 		{
 			name: "Closed shift 2 hours",
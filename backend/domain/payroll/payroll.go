@@ -0,0 +1,343 @@
+package payroll
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"cafe-pos/backend/domain/handover"
+	"cafe-pos/backend/domain/timesheet"
+	"cafe-pos/backend/domain/user"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Default pay rules follow the Vietnamese Labour Code: overtime at 150%, holidays at 300%,
+// a 30% night premium, and a monthly salary covering 26 days of 8 hours
+const (
+	DefaultOvertimeMultiplier   = 1.5
+	DefaultHolidayMultiplier    = 3.0
+	DefaultNightPremium         = 0.3
+	DefaultStandardMonthlyHours = 208.0
+)
+
+type RunStatus string
+
+const (
+	RunDraft  RunStatus = "DRAFT"
+	RunPosted RunStatus = "POSTED" // total posted as a salary expense, payslips final
+)
+
+// ErrRunNotDraft is returned when a payroll run is posted that is no longer a draft
+var ErrRunNotDraft = errors.New("payroll run has already been posted")
+
+type DeductionSource string
+
+const (
+	DeductionCashShortage DeductionSource = "CASH_SHORTAGE"
+	DeductionManual       DeductionSource = "MANUAL"
+)
+
+// Rules configure how worked hours are paid
+type Rules struct {
+	OvertimeMultiplier   float64 `json:"overtime_multiplier"`
+	HolidayMultiplier    float64 `json:"holiday_multiplier"`
+	NightPremium         float64 `json:"night_premium"`          // added to the rate of night hours
+	StandardMonthlyHours float64 `json:"standard_monthly_hours"` // converts a monthly salary to an hourly rate
+}
+
+// DefaultRules returns the default multipliers and standard hours
+func DefaultRules() Rules {
+	return Rules{
+		OvertimeMultiplier:   DefaultOvertimeMultiplier,
+		HolidayMultiplier:    DefaultHolidayMultiplier,
+		NightPremium:         DefaultNightPremium,
+		StandardMonthlyHours: DefaultStandardMonthlyHours,
+	}
+}
+
+// Deduction is an approved amount taken off an employee's gross pay
+type Deduction struct {
+	Source      DeductionSource     `bson:"source" json:"source"`
+	ReferenceID *primitive.ObjectID `bson:"reference_id,omitempty" json:"reference_id,omitempty"` // discrepancy of a cash shortage
+	Reason      string              `bson:"reason" json:"reason"`
+	Amount      float64             `bson:"amount" json:"amount"`
+	Date        time.Time           `bson:"date" json:"date"`
+}
+
+// Payslip is the pay of one employee for the period of a payroll run
+type Payslip struct {
+	UserID          primitive.ObjectID `bson:"user_id" json:"user_id"`
+	UserName        string             `bson:"user_name" json:"user_name"`
+	PayType         user.PayType       `bson:"pay_type" json:"pay_type"`
+	HourlyRate      float64            `bson:"hourly_rate" json:"hourly_rate"` // monthly salaries converted to an hourly rate
+	MonthlySalary   float64            `bson:"monthly_salary,omitempty" json:"monthly_salary,omitempty"`
+	RegularHours    float64            `bson:"regular_hours" json:"regular_hours"`
+	OvertimeHours   float64            `bson:"overtime_hours" json:"overtime_hours"`
+	NightHours      float64            `bson:"night_hours" json:"night_hours"`
+	HolidayHours    float64            `bson:"holiday_hours" json:"holiday_hours"`
+	BasePay         float64            `bson:"base_pay" json:"base_pay"`
+	OvertimePay     float64            `bson:"overtime_pay" json:"overtime_pay"`
+	NightPay        float64            `bson:"night_pay" json:"night_pay"`
+	HolidayPay      float64            `bson:"holiday_pay" json:"holiday_pay"`
+	GrossPay        float64            `bson:"gross_pay" json:"gross_pay"`
+	Deductions      []Deduction        `bson:"deductions" json:"deductions"`
+	TotalDeductions float64            `bson:"total_deductions" json:"total_deductions"`
+	NetPay          float64            `bson:"net_pay" json:"net_pay"`
+	MissingRate     bool               `bson:"missing_rate" json:"missing_rate"` // no pay rate set, nothing paid
+}
+
+// PayrollRun is the payroll of all employees for one pay period
+type PayrollRun struct {
+	ID              primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	From            time.Time           `bson:"from" json:"from"`
+	To              time.Time           `bson:"to" json:"to"`
+	Status          RunStatus           `bson:"status" json:"status"`
+	Rules           Rules               `bson:"rules" json:"rules"`
+	Payslips        []Payslip           `bson:"payslips" json:"payslips"`
+	TotalGross      float64             `bson:"total_gross" json:"total_gross"`
+	TotalDeductions float64             `bson:"total_deductions" json:"total_deductions"`
+	TotalNet        float64             `bson:"total_net" json:"total_net"`
+	ExpenseID       *primitive.ObjectID `bson:"expense_id,omitempty" json:"expense_id,omitempty"`
+	CreatedBy       string              `bson:"created_by" json:"created_by"`
+	PostedBy        string              `bson:"posted_by,omitempty" json:"posted_by,omitempty"`
+	PostedAt        *time.Time          `bson:"posted_at,omitempty" json:"posted_at,omitempty"`
+	CreatedAt       time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+type ManualDeductionRequest struct {
+	UserID string  `json:"user_id" binding:"required"`
+	Amount float64 `json:"amount" binding:"required,gt=0"`
+	Reason string  `json:"reason" binding:"required"`
+}
+
+type RunRequest struct {
+	From       string                   `json:"from" binding:"required"` // YYYY-MM-DD
+	To         string                   `json:"to" binding:"required"`   // YYYY-MM-DD, inclusive
+	Deductions []ManualDeductionRequest `json:"deductions"`
+}
+
+// Period returns the pay period of the request as an inclusive day range in loc
+func (r *RunRequest) Period(loc *time.Location) (time.Time, time.Time, error) {
+	from, err := time.ParseInLocation("2006-01-02", r.From, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("invalid from date, expected YYYY-MM-DD")
+	}
+	to, err := time.ParseInLocation("2006-01-02", r.To, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("invalid to date, expected YYYY-MM-DD")
+	}
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, errors.New("to must not be before from")
+	}
+	return from, to.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+}
+
+// Overlaps reports whether the run covers any day of [from, to]
+func (r *PayrollRun) Overlaps(from, to time.Time) bool {
+	return !r.From.After(to) && !from.After(r.To)
+}
+
+// ShortageDeductions returns the approved cash shortages attributed to each employee: resolved
+// shortage discrepancies, or escalated ones the manager approved, where the waiter or the
+// cashier was held responsible
+func ShortageDeductions(discrepancies []*handover.CashDiscrepancy) map[primitive.ObjectID][]Deduction {
+	byUser := make(map[primitive.ObjectID][]Deduction)
+	for _, d := range discrepancies {
+		if d.Type != handover.DiscrepancyTypeShortage || !shortageApproved(d) {
+			continue
+		}
+		var userID primitive.ObjectID
+		switch d.Responsibility {
+		case handover.ResponsibilityWaiter:
+			userID = d.WaiterID
+		case handover.ResponsibilityCashier:
+			userID = d.CashierID
+		default:
+			continue
+		}
+		id := d.ID
+		byUser[userID] = append(byUser[userID], Deduction{
+			Source:      DeductionCashShortage,
+			ReferenceID: &id,
+			Reason:      "Thiếu tiền bàn giao: " + d.Reason,
+			Amount:      math.Abs(d.Amount),
+			Date:        d.OccurredAt,
+		})
+	}
+	return byUser
+}
+
+// shortageApproved reports whether a discrepancy was resolved. Manager approvals used to
+// leave the discrepancy escalated, so those count as resolved too.
+func shortageApproved(d *handover.CashDiscrepancy) bool {
+	switch d.Status {
+	case handover.DiscrepancyStatusResolved:
+		return true
+	case handover.DiscrepancyStatusEscalated:
+		return d.ManagerApproved != nil && *d.ManagerApproved
+	}
+	return false
+}
+
+// NewPayslip pays the hours of an employee's timesheet at their pay rate. Hourly staff are
+// paid their regular hours at the rate; monthly staff get their salary prorated over the days
+// of the period instead. Overtime and holiday hours are paid at their multipliers and night
+// hours get the night premium on top, both at the hourly rate, which for monthly staff is the
+// salary divided by the standard monthly hours. Deductions are capped at the gross pay.
+func NewPayslip(sheet *timesheet.EmployeeTimesheet, pay *user.PayRate, deductions []Deduction, rules Rules, from, to time.Time) Payslip {
+	slip := Payslip{
+		UserID:        sheet.UserID,
+		UserName:      sheet.UserName,
+		RegularHours:  sheet.RegularHours,
+		OvertimeHours: sheet.OvertimeHours,
+		NightHours:    sheet.NightHours,
+		HolidayHours:  sheet.HolidayHours,
+		Deductions:    []Deduction{},
+	}
+	if pay == nil {
+		slip.MissingRate = true
+		return slip
+	}
+
+	slip.PayType = pay.Type
+	switch pay.Type {
+	case user.PayMonthly:
+		slip.MonthlySalary = pay.MonthlySalary
+		slip.HourlyRate = pay.MonthlySalary / rules.StandardMonthlyHours
+		slip.BasePay = ProratedSalary(pay.MonthlySalary, from, to)
+	default:
+		slip.HourlyRate = pay.HourlyRate
+		slip.BasePay = slip.RegularHours * pay.HourlyRate
+	}
+	slip.OvertimePay = slip.OvertimeHours * slip.HourlyRate * rules.OvertimeMultiplier
+	slip.HolidayPay = slip.HolidayHours * slip.HourlyRate * rules.HolidayMultiplier
+	slip.NightPay = slip.NightHours * slip.HourlyRate * rules.NightPremium
+
+	slip.HourlyRate = roundMoney(slip.HourlyRate)
+	slip.BasePay = roundMoney(slip.BasePay)
+	slip.OvertimePay = roundMoney(slip.OvertimePay)
+	slip.HolidayPay = roundMoney(slip.HolidayPay)
+	slip.NightPay = roundMoney(slip.NightPay)
+	slip.GrossPay = slip.BasePay + slip.OvertimePay + slip.HolidayPay + slip.NightPay
+
+	for _, d := range deductions {
+		slip.Deductions = append(slip.Deductions, d)
+		slip.TotalDeductions += d.Amount
+	}
+	slip.TotalDeductions = math.Min(roundMoney(slip.TotalDeductions), slip.GrossPay)
+	slip.NetPay = slip.GrossPay - slip.TotalDeductions
+	return slip
+}
+
+// ProratedSalary returns the part of a monthly salary earned over the days of [from, to],
+// each day being worth the salary divided by the days of its month
+func ProratedSalary(monthly float64, from, to time.Time) float64 {
+	total := 0.0
+	for day := startOfDay(from); !day.After(to); day = day.AddDate(0, 0, 1) {
+		daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location()).Day()
+		total += monthly / float64(daysInMonth)
+	}
+	return total
+}
+
+// NewRun computes the payslips of every employee who worked in the period or has a
+// deduction, and the run totals
+func NewRun(ts *timesheet.Timesheet, users map[primitive.ObjectID]*user.User, deductions map[primitive.ObjectID][]Deduction, rules Rules) *PayrollRun {
+	run := &PayrollRun{From: ts.From, To: ts.To, Status: RunDraft, Rules: rules, Payslips: []Payslip{}}
+
+	seen := make(map[primitive.ObjectID]bool)
+	add := func(sheet *timesheet.EmployeeTimesheet) {
+		seen[sheet.UserID] = true
+		var pay *user.PayRate
+		if u, ok := users[sheet.UserID]; ok {
+			pay = u.Pay
+			if u.Name != "" {
+				sheet.UserName = u.Name
+			}
+		}
+		run.Payslips = append(run.Payslips, NewPayslip(sheet, pay, deductions[sheet.UserID], rules, ts.From, ts.To))
+	}
+	for i := range ts.Employees {
+		add(&ts.Employees[i])
+	}
+	// Salaried staff are paid without worked shifts, and deductions apply to anyone
+	for id, u := range users {
+		if seen[id] || !u.Active {
+			continue
+		}
+		if (u.Pay != nil && u.Pay.Type == user.PayMonthly) || len(deductions[id]) > 0 {
+			add(&timesheet.EmployeeTimesheet{UserID: id, UserName: u.Username})
+		}
+	}
+
+	sort.SliceStable(run.Payslips, func(i, j int) bool { return run.Payslips[i].UserName < run.Payslips[j].UserName })
+	for _, slip := range run.Payslips {
+		run.TotalGross += slip.GrossPay
+		run.TotalDeductions += slip.TotalDeductions
+		run.TotalNet += slip.NetPay
+	}
+	return run
+}
+
+// MissingRates returns the names of employees who worked in the period without a pay rate
+func (r *PayrollRun) MissingRates() []string {
+	var names []string
+	for _, s := range r.Payslips {
+		if s.MissingRate {
+			names = append(names, s.UserName)
+		}
+	}
+	return names
+}
+
+// Payslip returns the payslip of an employee in the run
+func (r *PayrollRun) Payslip(userID primitive.ObjectID) (*Payslip, bool) {
+	for i := range r.Payslips {
+		if r.Payslips[i].UserID == userID {
+			return &r.Payslips[i], true
+		}
+	}
+	return nil, false
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v)
+}
+
+// ExportColumns are the columns of the CSV/XLSX export of a payroll run
+var ExportColumns = []string{
+	"employee", "pay_type", "hourly_rate", "regular_hours", "overtime_hours", "night_hours", "holiday_hours",
+	"base_pay", "overtime_pay", "night_pay", "holiday_pay", "gross_pay", "deductions", "net_pay",
+}
+
+// ExportRecords returns one row per payslip including the header
+func (r *PayrollRun) ExportRecords() [][]string {
+	num := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	records := [][]string{ExportColumns}
+	for _, s := range r.Payslips {
+		records = append(records, []string{
+			s.UserName,
+			string(s.PayType),
+			num(s.HourlyRate),
+			num(s.RegularHours),
+			num(s.OvertimeHours),
+			num(s.NightHours),
+			num(s.HolidayHours),
+			num(s.BasePay),
+			num(s.OvertimePay),
+			num(s.NightPay),
+			num(s.HolidayPay),
+			num(s.GrossPay),
+			num(s.TotalDeductions),
+			num(s.NetPay),
+		})
+	}
+	return records
+}
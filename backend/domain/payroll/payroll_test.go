@@ -0,0 +1,145 @@
+package payroll
+
+import (
+	"testing"
+	"time"
+
+	"cafe-pos/backend/domain/handover"
+	"cafe-pos/backend/domain/timesheet"
+	"cafe-pos/backend/domain/user"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestNewPayslipHourly tests pay of regular, overtime, night and holiday hours with deductions
+func TestNewPayslipHourly(t *testing.T) {
+	from := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0).Add(-time.Nanosecond)
+	sheet := &timesheet.EmployeeTimesheet{
+		UserID: primitive.NewObjectID(), UserName: "Lan",
+		RegularHours: 100, OvertimeHours: 4, NightHours: 10, HolidayHours: 8,
+	}
+	pay := &user.PayRate{Type: user.PayHourly, HourlyRate: 25000}
+	deductions := []Deduction{{Source: DeductionManual, Reason: "Vỡ ly", Amount: 50000}}
+
+	slip := NewPayslip(sheet, pay, deductions, DefaultRules(), from, to)
+	if slip.BasePay != 2500000 || slip.OvertimePay != 150000 || slip.NightPay != 75000 || slip.HolidayPay != 600000 {
+		t.Errorf("Unexpected pay %+v", slip)
+	}
+	if slip.GrossPay != 3325000 || slip.TotalDeductions != 50000 || slip.NetPay != 3275000 {
+		t.Errorf("Expected 3325000 gross and 3275000 net, got %v and %v", slip.GrossPay, slip.NetPay)
+	}
+
+	// Deductions never make the net pay negative
+	big := []Deduction{{Source: DeductionManual, Amount: 5000000}}
+	if slip := NewPayslip(sheet, pay, big, DefaultRules(), from, to); slip.NetPay != 0 || slip.TotalDeductions != slip.GrossPay {
+		t.Errorf("Expected deductions capped at the gross pay, got %+v", slip)
+	}
+
+	if slip := NewPayslip(sheet, nil, deductions, DefaultRules(), from, to); !slip.MissingRate || slip.GrossPay != 0 {
+		t.Errorf("Expected nothing paid without a rate, got %+v", slip)
+	}
+}
+
+// TestNewPayslipMonthly tests prorated salaries and overtime at the derived hourly rate
+func TestNewPayslipMonthly(t *testing.T) {
+	from := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	sheet := &timesheet.EmployeeTimesheet{UserID: primitive.NewObjectID(), RegularHours: 160, OvertimeHours: 2}
+	pay := &user.PayRate{Type: user.PayMonthly, MonthlySalary: 8320000}
+
+	// Half of February 2024 (29 days) is not half the salary
+	half := NewPayslip(sheet, pay, nil, DefaultRules(), from, from.AddDate(0, 0, 14).Add(-time.Nanosecond))
+	if half.BasePay != 4016552 {
+		t.Errorf("Expected 14/29 of the salary, got %v", half.BasePay)
+	}
+	full := NewPayslip(sheet, pay, nil, DefaultRules(), from, from.AddDate(0, 1, 0).Add(-time.Nanosecond))
+	if full.BasePay != 8320000 || full.HourlyRate != 40000 || full.OvertimePay != 120000 {
+		t.Errorf("Expected the full salary and overtime at 40000/h, got %+v", full)
+	}
+}
+
+// TestNewRun tests shortage deductions, salaried staff without shifts and totals
+func TestNewRun(t *testing.T) {
+	from := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0).Add(-time.Nanosecond)
+	waiter := &user.User{ID: primitive.NewObjectID(), Name: "Lan", Active: true, Pay: &user.PayRate{Type: user.PayHourly, HourlyRate: 25000}}
+	cashier := &user.User{ID: primitive.NewObjectID(), Name: "Minh", Active: true, Pay: &user.PayRate{Type: user.PayHourly, HourlyRate: 30000}}
+	manager := &user.User{ID: primitive.NewObjectID(), Name: "Hoa", Active: true, Pay: &user.PayRate{Type: user.PayMonthly, MonthlySalary: 10000000}}
+	users := map[primitive.ObjectID]*user.User{waiter.ID: waiter, cashier.ID: cashier, manager.ID: manager}
+
+	discrepancies := []*handover.CashDiscrepancy{
+		{ID: primitive.NewObjectID(), Type: handover.DiscrepancyTypeShortage, Status: handover.DiscrepancyStatusResolved, Responsibility: handover.ResponsibilityWaiter, Amount: -20000, WaiterID: waiter.ID, CashierID: cashier.ID},
+		{ID: primitive.NewObjectID(), Type: handover.DiscrepancyTypeShortage, Status: handover.DiscrepancyStatusPending, Responsibility: handover.ResponsibilityWaiter, Amount: -30000, WaiterID: waiter.ID},
+		{ID: primitive.NewObjectID(), Type: handover.DiscrepancyTypeOverage, Status: handover.DiscrepancyStatusResolved, Responsibility: handover.ResponsibilityCashier, Amount: 10000, CashierID: cashier.ID},
+		{ID: primitive.NewObjectID(), Type: handover.DiscrepancyTypeShortage, Status: handover.DiscrepancyStatusResolved, Responsibility: handover.ResponsibilitySystem, Amount: -5000, CashierID: cashier.ID},
+	}
+	deductions := ShortageDeductions(discrepancies)
+	if len(deductions) != 1 || len(deductions[waiter.ID]) != 1 || deductions[waiter.ID][0].Amount != 20000 {
+		t.Fatalf("Expected one approved shortage for the waiter, got %+v", deductions)
+	}
+
+	ts := &timesheet.Timesheet{From: from, To: to, Employees: []timesheet.EmployeeTimesheet{
+		{UserID: waiter.ID, UserName: "lan", RegularHours: 40},
+		{UserID: cashier.ID, UserName: "minh", RegularHours: 10},
+	}}
+	run := NewRun(ts, users, deductions, DefaultRules())
+	if len(run.Payslips) != 3 || run.Status != RunDraft {
+		t.Fatalf("Expected 3 payslips in a draft run, got %+v", run.Payslips)
+	}
+	lan, _ := run.Payslip(waiter.ID)
+	if lan.UserName != "Lan" || lan.GrossPay != 1000000 || lan.NetPay != 980000 {
+		t.Errorf("Expected Lan paid 1000000 less 20000, got %+v", lan)
+	}
+	if hoa, ok := run.Payslip(manager.ID); !ok || hoa.BasePay != 10000000 {
+		t.Errorf("Expected the manager's full salary without shifts, got %+v", hoa)
+	}
+	if run.TotalGross != 11300000 || run.TotalDeductions != 20000 || run.TotalNet != 11280000 {
+		t.Errorf("Unexpected totals %v %v %v", run.TotalGross, run.TotalDeductions, run.TotalNet)
+	}
+	if records := run.ExportRecords(); len(records) != 4 || records[0][0] != "employee" {
+		t.Errorf("Unexpected export %v", records)
+	}
+}
+
+// TestShortageDeductionsManagerApproval tests that only shortages the manager approved are deducted
+func TestShortageDeductionsManagerApproval(t *testing.T) {
+	approved, rejected := true, false
+	waiterID := primitive.NewObjectID()
+	escalated := func(managerApproved *bool) *handover.CashDiscrepancy {
+		return &handover.CashDiscrepancy{ID: primitive.NewObjectID(), Type: handover.DiscrepancyTypeShortage, Status: handover.DiscrepancyStatusEscalated, Responsibility: handover.ResponsibilityWaiter, Amount: -15000, WaiterID: waiterID, ManagerApproved: managerApproved}
+	}
+
+	tests := []struct {
+		name     string
+		resolve  func(d *handover.CashDiscrepancy) error
+		approved *bool
+		want     int
+	}{
+		{name: "awaiting the manager", want: 0},
+		{name: "approved before the status was resolved", approved: &approved, want: 1},
+		{name: "rejected", approved: &rejected, want: 0},
+		{name: "approved by the manager", want: 1, resolve: func(d *handover.CashDiscrepancy) error {
+			return d.SetManagerResolution(primitive.NewObjectID(), "Hoa", true, "")
+		}},
+		{name: "rejected by the manager", want: 0, resolve: func(d *handover.CashDiscrepancy) error {
+			return d.SetManagerResolution(primitive.NewObjectID(), "Hoa", false, "")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := escalated(tt.approved)
+			if tt.resolve != nil {
+				if err := tt.resolve(d); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got := ShortageDeductions([]*handover.CashDiscrepancy{d})[waiterID]
+			if len(got) != tt.want {
+				t.Fatalf("Expected %d deductions, got %+v", tt.want, got)
+			}
+			if tt.want == 1 && got[0].Amount != 15000 {
+				t.Errorf("Expected 15000 deducted, got %v", got[0].Amount)
+			}
+		})
+	}
+}
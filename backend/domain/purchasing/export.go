@@ -0,0 +1,77 @@
+package purchasing
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"cafe-pos/backend/domain/order"
+)
+
+// ExportColumns are the columns of the CSV/XLSX export of a purchase order
+var ExportColumns = []string{"po_number", "supplier", "ingredient", "quantity", "unit", "expected_price", "amount"}
+
+// ExportRecords returns the lines of the order as rows including the header
+func (po *PurchaseOrder) ExportRecords() [][]string {
+	records := [][]string{ExportColumns}
+	for _, l := range po.Lines {
+		records = append(records, []string{
+			po.PONumber,
+			po.Supplier,
+			l.IngredientName,
+			strconv.FormatFloat(l.Quantity, 'f', -1, 64),
+			string(l.Unit),
+			strconv.FormatFloat(l.ExpectedPrice, 'f', -1, 64),
+			strconv.FormatFloat(l.Quantity*l.ExpectedPrice, 'f', 0, 64),
+		})
+	}
+	return records
+}
+
+// DocumentLines renders the order as fixed-width text lines for printing or PDF export
+func (po *PurchaseOrder) DocumentLines(width int) []string {
+	rule := strings.Repeat("-", width)
+	lines := []string{
+		"ĐƠN ĐẶT HÀNG / PURCHASE ORDER",
+		"",
+		"Số / No.:        " + po.PONumber,
+		"Ngày / Date:     " + po.CreatedAt.Local().Format("02/01/2006"),
+		"NCC / Supplier:  " + po.Supplier,
+	}
+	if po.ExpectedDate != nil {
+		lines = append(lines, "Giao / Delivery: "+po.ExpectedDate.Local().Format("02/01/2006"))
+	}
+	lines = append(lines, "", rule)
+
+	nameWidth := width - 45
+	lines = append(lines, fmt.Sprintf("%-4s%s%10s %-6s%12s%12s", "#", pad("Nguyên liệu", nameWidth), "SL", "ĐVT", "Đơn giá", "Thành tiền"))
+	lines = append(lines, rule)
+	for i, l := range po.Lines {
+		lines = append(lines, fmt.Sprintf("%-4d%s%10s %-6s%12s%12s",
+			i+1,
+			pad(l.IngredientName, nameWidth),
+			strconv.FormatFloat(l.Quantity, 'f', -1, 64),
+			string(l.Unit),
+			order.FormatVND(l.ExpectedPrice),
+			order.FormatVND(l.Quantity*l.ExpectedPrice),
+		))
+	}
+	lines = append(lines, rule)
+	lines = append(lines, fmt.Sprintf("%*s", width, "Tổng / Total: "+order.FormatVND(po.ExpectedTotal)))
+
+	if po.Notes != "" {
+		lines = append(lines, "", "Ghi chú / Notes: "+po.Notes)
+	}
+	return lines
+}
+
+// pad truncates or pads s to exactly width characters
+func pad(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		r := []rune(s)
+		return string(r[:width-1]) + " "
+	}
+	return s + strings.Repeat(" ", width-n)
+}
@@ -0,0 +1,228 @@
+package purchasing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"cafe-pos/backend/domain/ingredient"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrConcurrentUpdate is returned when a purchase order was changed since it was read
+var ErrConcurrentUpdate = errors.New("purchase order was changed by someone else, reload it and try again")
+
+type Status string
+
+const (
+	StatusDraft             Status = "DRAFT"              // Đang soạn, có thể sửa
+	StatusSent              Status = "SENT"               // Đã gửi nhà cung cấp
+	StatusPartiallyReceived Status = "PARTIALLY_RECEIVED" // Đã nhận một phần
+	StatusReceived          Status = "RECEIVED"           // Đã nhận đủ hoặc đã chốt
+	StatusCancelled         Status = "CANCELLED"          // Đã hủy
+)
+
+// Line is one ingredient on a purchase order. Quantity and prices are in Unit, which
+// may differ from the stock unit (e.g. ordered in boxes, stocked in pieces).
+type Line struct {
+	IngredientID     primitive.ObjectID  `bson:"ingredient_id" json:"ingredient_id"`
+	IngredientName   string              `bson:"ingredient_name" json:"ingredient_name"`
+	Unit             ingredient.UnitType `bson:"unit" json:"unit"`
+	Quantity         float64             `bson:"quantity" json:"quantity"`
+	ExpectedPrice    float64             `bson:"expected_price" json:"expected_price"` // per Unit
+	ReceivedQuantity float64             `bson:"received_quantity" json:"received_quantity"`
+	ReceivedAmount   float64             `bson:"received_amount" json:"received_amount"` // actual total paid
+}
+
+// Remaining returns the quantity still expected
+func (l *Line) Remaining() float64 {
+	return math.Max(l.Quantity-l.ReceivedQuantity, 0)
+}
+
+// ReceiptLine is the actual quantity and price of one ingredient in a delivery
+type ReceiptLine struct {
+	IngredientID   primitive.ObjectID  `bson:"ingredient_id" json:"ingredient_id"`
+	IngredientName string              `bson:"ingredient_name" json:"ingredient_name"`
+	Unit           ingredient.UnitType `bson:"unit" json:"unit"`
+	Quantity       float64             `bson:"quantity" json:"quantity"`
+	UnitPrice      float64             `bson:"unit_price" json:"unit_price"`
+	Amount         float64             `bson:"amount" json:"amount"`
+	LotNumber      string              `bson:"lot_number,omitempty" json:"lot_number,omitempty"`
+	ExpiryDate     *time.Time          `bson:"expiry_date,omitempty" json:"expiry_date,omitempty"`
+}
+
+// Receipt is one delivery against a purchase order
+type Receipt struct {
+	ReceivedAt time.Time          `bson:"received_at" json:"received_at"`
+	ReceivedBy string             `bson:"received_by" json:"received_by"`
+	Lines      []ReceiptLine      `bson:"lines" json:"lines"`
+	Total      float64            `bson:"total" json:"total"`
+	Notes      string             `bson:"notes,omitempty" json:"notes,omitempty"`
+	ExpenseID  primitive.ObjectID `bson:"expense_id,omitempty" json:"expense_id,omitempty"`
+}
+
+type PurchaseOrder struct {
+	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	PONumber      string              `bson:"po_number" json:"po_number"`
+	Supplier      string              `bson:"supplier" json:"supplier"`
+	SupplierID    *primitive.ObjectID `bson:"supplier_id" json:"supplier_id,omitempty"`
+	Status        Status              `bson:"status" json:"status"`
+	Lines         []Line              `bson:"lines" json:"lines"`
+	ExpectedTotal float64             `bson:"expected_total" json:"expected_total"`
+	ReceivedTotal float64             `bson:"received_total" json:"received_total"`
+	ExpectedDate  *time.Time          `bson:"expected_date" json:"expected_date,omitempty"`
+	Notes         string              `bson:"notes" json:"notes"`
+	Receipts      []Receipt           `bson:"receipts" json:"receipts"`
+	CreatedBy     string              `bson:"created_by" json:"created_by"`
+	SentAt        *time.Time          `bson:"sent_at" json:"sent_at,omitempty"`
+	SentBy        string              `bson:"sent_by" json:"sent_by,omitempty"`
+	ReceivedAt    *time.Time          `bson:"received_at" json:"received_at,omitempty"`
+	CancelledAt   *time.Time          `bson:"cancelled_at" json:"cancelled_at,omitempty"`
+	CreatedAt     time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time           `bson:"updated_at" json:"updated_at"`
+	// Version is incremented by every update; an update of an older version fails with
+	// ErrConcurrentUpdate so concurrent changes are not lost
+	Version int `bson:"version" json:"version"`
+}
+
+type LineRequest struct {
+	IngredientID  string              `json:"ingredient_id" binding:"required"`
+	Quantity      float64             `json:"quantity" binding:"required,gt=0"`
+	Unit          ingredient.UnitType `json:"unit"` // defaults to the ingredient stock unit
+	ExpectedPrice float64             `json:"expected_price" binding:"min=0"`
+}
+
+type PurchaseOrderRequest struct {
+	Supplier     string        `json:"supplier"`
+	SupplierID   string        `json:"supplier_id"` // supplier entity, takes precedence over Supplier
+	ExpectedDate *time.Time    `json:"expected_date"`
+	Notes        string        `json:"notes"`
+	Lines        []LineRequest `json:"lines" binding:"required,min=1,dive"`
+}
+
+type ReceiveLineRequest struct {
+	IngredientID string   `json:"ingredient_id" binding:"required"`
+	Quantity     float64  `json:"quantity" binding:"required,gt=0"`
+	UnitPrice    *float64 `json:"unit_price" binding:"omitempty,min=0"` // defaults to the expected price
+	// LotNumber and ExpiryDate are recorded on the stock batch for expiry tracking
+	LotNumber  string     `json:"lot_number"`
+	ExpiryDate *time.Time `json:"expiry_date"`
+}
+
+type ReceiveRequest struct {
+	Lines []ReceiveLineRequest `json:"lines" binding:"required,min=1,dive"`
+	Notes string               `json:"notes"`
+	// Close marks the order as received even if some lines are short
+	Close bool `json:"close"`
+}
+
+func (po *PurchaseOrder) CanEdit() bool {
+	return po.Status == StatusDraft
+}
+
+func (po *PurchaseOrder) CanSend() bool {
+	return po.Status == StatusDraft || po.Status == StatusSent
+}
+
+func (po *PurchaseOrder) CanReceive() bool {
+	return po.Status == StatusSent || po.Status == StatusPartiallyReceived
+}
+
+func (po *PurchaseOrder) CanCancel() bool {
+	return po.Status == StatusDraft || po.Status == StatusSent
+}
+
+// CalculateTotals recomputes the expected and received totals from the lines
+func (po *PurchaseOrder) CalculateTotals() {
+	po.ExpectedTotal = 0
+	po.ReceivedTotal = 0
+	for _, l := range po.Lines {
+		po.ExpectedTotal += l.Quantity * l.ExpectedPrice
+		po.ReceivedTotal += l.ReceivedAmount
+	}
+	po.ExpectedTotal = math.Round(po.ExpectedTotal)
+	po.ReceivedTotal = math.Round(po.ReceivedTotal)
+}
+
+// LineFor returns the line of an ingredient, or nil
+func (po *PurchaseOrder) LineFor(ingredientID primitive.ObjectID) *Line {
+	for i := range po.Lines {
+		if po.Lines[i].IngredientID == ingredientID {
+			return &po.Lines[i]
+		}
+	}
+	return nil
+}
+
+// NewReceipt validates a delivery against the order and builds the receipt.
+// The order is not modified until ApplyReceipt.
+func (po *PurchaseOrder) NewReceipt(req *ReceiveRequest, receivedBy string, at time.Time) (*Receipt, error) {
+	if !po.CanReceive() {
+		return nil, fmt.Errorf("cannot receive a purchase order with status %s", po.Status)
+	}
+
+	receipt := &Receipt{ReceivedAt: at, ReceivedBy: receivedBy, Notes: req.Notes}
+	seen := make(map[primitive.ObjectID]bool)
+	for _, r := range req.Lines {
+		id, err := primitive.ObjectIDFromHex(r.IngredientID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ingredient id %q", r.IngredientID)
+		}
+		line := po.LineFor(id)
+		if line == nil {
+			return nil, fmt.Errorf("ingredient %s is not on this purchase order", r.IngredientID)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("ingredient %s is received twice", line.IngredientName)
+		}
+		seen[id] = true
+		if r.Quantity <= 0 {
+			return nil, errors.New("received quantity must be positive")
+		}
+
+		price := line.ExpectedPrice
+		if r.UnitPrice != nil {
+			price = *r.UnitPrice
+		}
+		rl := ReceiptLine{
+			IngredientID:   id,
+			IngredientName: line.IngredientName,
+			Unit:           line.Unit,
+			Quantity:       r.Quantity,
+			UnitPrice:      price,
+			Amount:         math.Round(r.Quantity * price),
+			LotNumber:      r.LotNumber,
+			ExpiryDate:     r.ExpiryDate,
+		}
+		receipt.Lines = append(receipt.Lines, rl)
+		receipt.Total += rl.Amount
+	}
+	return receipt, nil
+}
+
+// ApplyReceipt records a delivery and moves the order to partially received or received.
+// With closeOrder the order is received even if some lines are short.
+func (po *PurchaseOrder) ApplyReceipt(receipt *Receipt, closeOrder bool) {
+	for _, rl := range receipt.Lines {
+		line := po.LineFor(rl.IngredientID)
+		line.ReceivedQuantity += rl.Quantity
+		line.ReceivedAmount += rl.Amount
+	}
+	po.Receipts = append(po.Receipts, *receipt)
+	po.CalculateTotals()
+
+	complete := true
+	for i := range po.Lines {
+		if po.Lines[i].Remaining() > 1e-9 {
+			complete = false
+		}
+	}
+	if complete || closeOrder {
+		po.Status = StatusReceived
+		at := receipt.ReceivedAt
+		po.ReceivedAt = &at
+	} else {
+		po.Status = StatusPartiallyReceived
+	}
+}
@@ -0,0 +1,129 @@
+package purchasing
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"cafe-pos/backend/domain/ingredient"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newSentOrder() *PurchaseOrder {
+	po := &PurchaseOrder{
+		PONumber: "PO-20240101-080000-001",
+		Supplier: "Trung Nguyên",
+		Status:   StatusSent,
+		Lines: []Line{
+			{IngredientID: primitive.NewObjectID(), IngredientName: "Cà phê bột", Unit: ingredient.UnitKilogram, Quantity: 10, ExpectedPrice: 300000},
+			{IngredientID: primitive.NewObjectID(), IngredientName: "Ly giấy", Unit: ingredient.UnitBox, Quantity: 5, ExpectedPrice: 60000},
+		},
+	}
+	po.CalculateTotals()
+	return po
+}
+
+// TestReceivePartialThenFull tests partial and full deliveries against a purchase order
+func TestReceivePartialThenFull(t *testing.T) {
+	po := newSentOrder()
+	if po.ExpectedTotal != 3300000 {
+		t.Fatalf("Expected total 3300000, got %v", po.ExpectedTotal)
+	}
+
+	actual := 310000.0
+	receipt, err := po.NewReceipt(&ReceiveRequest{Lines: []ReceiveLineRequest{
+		{IngredientID: po.Lines[0].IngredientID.Hex(), Quantity: 6, UnitPrice: &actual},
+	}}, "manager", time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if receipt.Total != 1860000 {
+		t.Errorf("Expected receipt total 1860000, got %v", receipt.Total)
+	}
+	po.ApplyReceipt(receipt, false)
+
+	if po.Status != StatusPartiallyReceived {
+		t.Errorf("Expected PARTIALLY_RECEIVED, got %s", po.Status)
+	}
+	if po.Lines[0].Remaining() != 4 {
+		t.Errorf("Expected 4 kg remaining, got %v", po.Lines[0].Remaining())
+	}
+
+	receipt, err = po.NewReceipt(&ReceiveRequest{Lines: []ReceiveLineRequest{
+		{IngredientID: po.Lines[0].IngredientID.Hex(), Quantity: 4},
+		{IngredientID: po.Lines[1].IngredientID.Hex(), Quantity: 5},
+	}}, "manager", time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	po.ApplyReceipt(receipt, false)
+
+	if po.Status != StatusReceived || po.ReceivedAt == nil {
+		t.Errorf("Expected RECEIVED with a received time, got %s", po.Status)
+	}
+	// 1860000 + 4 * 300000 + 5 * 60000
+	if po.ReceivedTotal != 3360000 {
+		t.Errorf("Expected received total 3360000, got %v", po.ReceivedTotal)
+	}
+	if len(po.Receipts) != 2 {
+		t.Errorf("Expected 2 receipts, got %d", len(po.Receipts))
+	}
+}
+
+// TestReceiveClose tests closing an order with short deliveries
+func TestReceiveClose(t *testing.T) {
+	po := newSentOrder()
+	receipt, err := po.NewReceipt(&ReceiveRequest{Lines: []ReceiveLineRequest{
+		{IngredientID: po.Lines[1].IngredientID.Hex(), Quantity: 2},
+	}}, "manager", time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	po.ApplyReceipt(receipt, true)
+
+	if po.Status != StatusReceived {
+		t.Errorf("Expected RECEIVED, got %s", po.Status)
+	}
+}
+
+// TestNewReceiptValidation tests that invalid deliveries are rejected
+func TestNewReceiptValidation(t *testing.T) {
+	po := newSentOrder()
+	id := po.Lines[0].IngredientID.Hex()
+
+	tests := []struct {
+		name   string
+		status Status
+		lines  []ReceiveLineRequest
+	}{
+		{"Draft order", StatusDraft, []ReceiveLineRequest{{IngredientID: id, Quantity: 1}}},
+		{"Ingredient not on order", StatusSent, []ReceiveLineRequest{{IngredientID: primitive.NewObjectID().Hex(), Quantity: 1}}},
+		{"Same ingredient twice", StatusSent, []ReceiveLineRequest{{IngredientID: id, Quantity: 1}, {IngredientID: id, Quantity: 2}}},
+		{"Invalid id", StatusSent, []ReceiveLineRequest{{IngredientID: "abc", Quantity: 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			po.Status = tt.status
+			if _, err := po.NewReceipt(&ReceiveRequest{Lines: tt.lines}, "manager", time.Now()); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+// TestDocumentLines tests the printable layout fits the line width
+func TestDocumentLines(t *testing.T) {
+	po := newSentOrder()
+	lines := po.DocumentLines(80)
+
+	text := strings.Join(lines, "\n")
+	if !strings.Contains(text, "Cà phê bột") || !strings.Contains(text, "3.300.000đ") {
+		t.Errorf("Expected ingredient and total in document:\n%s", text)
+	}
+	for _, l := range lines {
+		if n := len([]rune(l)); n > 80 {
+			t.Errorf("Line exceeds width (%d): %q", n, l)
+		}
+	}
+}
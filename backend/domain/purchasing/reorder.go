@@ -0,0 +1,309 @@
+package purchasing
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"cafe-pos/backend/domain/ingredient"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	DefaultLookbackDays = 28 // four weeks of history, so each weekday is seen four times
+	DefaultSafetyDays   = 2  // extra days of usage kept against late deliveries and busy days
+	DefaultReviewDays   = 7  // days until stock is reviewed again, an order covers them
+	DefaultLeadTimeDays = 2  // used when the supplier has no lead time
+)
+
+// ReorderParams tunes the reorder forecast
+type ReorderParams struct {
+	LookbackDays int
+	SafetyDays   int
+	ReviewDays   int
+}
+
+// WithDefaults fills unset (zero) parameters with the defaults
+func (p ReorderParams) WithDefaults() ReorderParams {
+	if p.LookbackDays <= 0 {
+		p.LookbackDays = DefaultLookbackDays
+	}
+	if p.SafetyDays <= 0 {
+		p.SafetyDays = DefaultSafetyDays
+	}
+	if p.ReviewDays <= 0 {
+		p.ReviewDays = DefaultReviewDays
+	}
+	return p
+}
+
+// WeekdayUsage is the average consumption of an ingredient per day of the week, in its stock unit
+type WeekdayUsage [7]float64
+
+// Average returns the average consumption per day
+func (u WeekdayUsage) Average() float64 {
+	total := 0.0
+	for _, q := range u {
+		total += q
+	}
+	return total / 7
+}
+
+// Forecast returns the expected consumption over the days starting at start
+func (u WeekdayUsage) Forecast(start time.Time, days int) float64 {
+	total := 0.0
+	for d := 0; d < days; d++ {
+		total += u[start.AddDate(0, 0, d).Weekday()]
+	}
+	return total
+}
+
+// DaysOfCover returns how many days the quantity lasts from start, or nil if nothing is consumed
+func (u WeekdayUsage) DaysOfCover(quantity float64, start time.Time) *float64 {
+	weekly := 0.0
+	for _, q := range u {
+		weekly += q
+	}
+	if weekly <= 0 {
+		return nil
+	}
+	// Whole weeks use the weekly total; at most a week of days is walked for what is left over
+	days := 0.0
+	if weeks := math.Ceil(quantity/weekly) - 1; weeks > 0 {
+		quantity -= weeks * weekly
+		days = weeks * 7
+	}
+	for d := 0; quantity > 0; d++ {
+		used := u[start.AddDate(0, 0, d).Weekday()]
+		if used >= quantity {
+			days += quantity / used
+			break
+		}
+		quantity -= used
+		days++
+	}
+	days = math.Round(days*10) / 10
+	return &days
+}
+
+// ConsumptionWindow returns the whole days of history used for the forecast, ending at the
+// start of the day of at
+func ConsumptionWindow(at time.Time, lookbackDays int) (time.Time, time.Time) {
+	to := startOfDay(at)
+	return to.AddDate(0, 0, -lookbackDays), to
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// UsageByWeekday averages stock consumed per ingredient by day of the week over [from, to).
+// Sales, adjustments and waste count as consumption; stocktake corrections do not, as they
+// fix the count rather than reflect a day's usage.
+func UsageByWeekday(history []*ingredient.StockHistory, from, to time.Time) map[primitive.ObjectID]WeekdayUsage {
+	var occurrences [7]int
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		occurrences[d.Weekday()]++
+	}
+
+	totals := make(map[primitive.ObjectID]*WeekdayUsage)
+	for _, h := range history {
+		if h.Quantity >= 0 || h.Type == ingredient.TransactionStocktake {
+			continue
+		}
+		at := h.CreatedAt.In(from.Location())
+		if at.Before(from) || !at.Before(to) {
+			continue
+		}
+		u, ok := totals[h.IngredientID]
+		if !ok {
+			u = &WeekdayUsage{}
+			totals[h.IngredientID] = u
+		}
+		u[at.Weekday()] -= h.Quantity
+	}
+
+	usage := make(map[primitive.ObjectID]WeekdayUsage, len(totals))
+	for id, u := range totals {
+		var avg WeekdayUsage
+		for d, q := range u {
+			if occurrences[d] > 0 {
+				avg[d] = q / float64(occurrences[d])
+			}
+		}
+		usage[id] = avg
+	}
+	return usage
+}
+
+// OnOrder totals the quantity still expected on open purchase orders per ingredient, in the
+// stock unit of each ingredient. Lines in units that cannot be converted are skipped.
+func OnOrder(orders []*PurchaseOrder, ingredients map[primitive.ObjectID]*ingredient.Ingredient) map[primitive.ObjectID]float64 {
+	onOrder := make(map[primitive.ObjectID]float64)
+	for _, po := range orders {
+		if !po.CanReceive() {
+			continue
+		}
+		for _, l := range po.Lines {
+			ing, ok := ingredients[l.IngredientID]
+			if !ok {
+				continue
+			}
+			qty, err := ing.ToStockUnit(l.Remaining(), string(l.Unit))
+			if err != nil {
+				continue
+			}
+			onOrder[l.IngredientID] += qty
+		}
+	}
+	return onOrder
+}
+
+// ReorderLine is the stock forecast of one ingredient and the quantity to order
+type ReorderLine struct {
+	IngredientID      primitive.ObjectID  `json:"ingredient_id"`
+	IngredientName    string              `json:"ingredient_name"`
+	Category          string              `json:"category"`
+	Unit              ingredient.UnitType `json:"unit"`
+	OnHand            float64             `json:"on_hand"`
+	OnOrder           float64             `json:"on_order"`
+	MinStock          float64             `json:"min_stock"`
+	AverageDailyUsage float64             `json:"average_daily_usage"`
+	// UsageByWeekday maps weekday names to average consumption
+	UsageByWeekday map[string]float64 `json:"usage_by_weekday"`
+	DaysOfCover    *float64           `json:"days_of_cover"` // nil when nothing is consumed
+	LeadTimeDays   int                `json:"lead_time_days"`
+	SafetyStock    float64            `json:"safety_stock"`
+	// ReorderPoint is the stock at which to order: usage over the lead time plus safety stock
+	ReorderPoint      float64 `json:"reorder_point"`
+	NeedsReorder      bool    `json:"needs_reorder"`
+	SuggestedQuantity float64 `json:"suggested_quantity"` // in the stock unit
+	// Order quantity in the supplier's price list unit, rounded up to whole units and the minimum order
+	SupplierID    *primitive.ObjectID `json:"supplier_id,omitempty"`
+	SupplierName  string              `json:"supplier_name"`
+	OrderUnit     ingredient.UnitType `json:"order_unit"`
+	OrderQuantity float64             `json:"order_quantity"`
+	UnitPrice     float64             `json:"unit_price"` // per OrderUnit
+	EstimatedCost float64             `json:"estimated_cost"`
+}
+
+// PlanReorder forecasts stock of an ingredient. Safety stock is the larger of MinStock and
+// SafetyDays of average usage. An order is needed when stock on hand plus on order falls to
+// the reorder point; it covers usage over the lead time and review period plus safety stock.
+func PlanReorder(ing *ingredient.Ingredient, usage WeekdayUsage, onOrder float64, leadTimeDays int, params ReorderParams, at time.Time) *ReorderLine {
+	params = params.WithDefaults()
+	if leadTimeDays <= 0 {
+		leadTimeDays = DefaultLeadTimeDays
+	}
+	start := startOfDay(at)
+
+	line := &ReorderLine{
+		IngredientID:      ing.ID,
+		IngredientName:    ing.Name,
+		Category:          ing.Category,
+		Unit:              ing.Unit,
+		OnHand:            ing.Quantity,
+		OnOrder:           round3(onOrder),
+		MinStock:          ing.MinStock,
+		AverageDailyUsage: round3(usage.Average()),
+		UsageByWeekday:    make(map[string]float64, 7),
+		DaysOfCover:       usage.DaysOfCover(ing.Quantity, start),
+		LeadTimeDays:      leadTimeDays,
+		SupplierID:        ing.SupplierID,
+		SupplierName:      ing.Supplier,
+		OrderUnit:         ing.Unit,
+		UnitPrice:         ing.CostPerUnit,
+	}
+	for d, q := range usage {
+		line.UsageByWeekday[time.Weekday(d).String()] = round3(q)
+	}
+
+	safety := math.Max(ing.MinStock, float64(params.SafetyDays)*usage.Average())
+	line.SafetyStock = round3(safety)
+	line.ReorderPoint = round3(usage.Forecast(start, leadTimeDays) + safety)
+
+	available := ing.Quantity + onOrder
+	line.NeedsReorder = line.ReorderPoint > 0 && available <= line.ReorderPoint
+	if line.NeedsReorder {
+		target := usage.Forecast(start, leadTimeDays+params.ReviewDays) + safety
+		line.SuggestedQuantity = round3(math.Max(target-available, 0))
+	}
+	line.OrderQuantity = line.SuggestedQuantity
+	line.EstimatedCost = math.Round(line.OrderQuantity * line.UnitPrice)
+	return line
+}
+
+// SetOrderUnit expresses the suggested quantity in a supplier's unit: stockPerUnit is the
+// stock quantity in one order unit. The quantity is rounded up to whole units and to the
+// minimum order quantity.
+func (l *ReorderLine) SetOrderUnit(unit ingredient.UnitType, stockPerUnit, price, minOrderQty float64) {
+	if stockPerUnit <= 0 {
+		return
+	}
+	l.OrderUnit = unit
+	l.UnitPrice = price
+	l.OrderQuantity = 0
+	if l.SuggestedQuantity > 0 {
+		// Tolerate float noise so 2.0000001 units does not become 3
+		l.OrderQuantity = math.Max(math.Ceil(l.SuggestedQuantity/stockPerUnit-1e-9), minOrderQty)
+	}
+	l.EstimatedCost = math.Round(l.OrderQuantity * price)
+}
+
+// SupplierSuggestion is the suggested purchase order for one supplier
+type SupplierSuggestion struct {
+	SupplierID     *primitive.ObjectID `json:"supplier_id,omitempty"`
+	SupplierName   string              `json:"supplier_name"`
+	LeadTimeDays   int                 `json:"lead_time_days"`
+	Lines          []*ReorderLine      `json:"lines"`
+	EstimatedTotal float64             `json:"estimated_total"`
+}
+
+// GroupBySupplier groups the lines that need reordering by supplier, most urgent supplier
+// first. Ingredients without a linked supplier are grouped by their free-text supplier name.
+func GroupBySupplier(lines []*ReorderLine) []*SupplierSuggestion {
+	groups := make(map[string]*SupplierSuggestion)
+	var result []*SupplierSuggestion
+	for _, l := range lines {
+		if !l.NeedsReorder {
+			continue
+		}
+		key := "name:" + l.SupplierName
+		if l.SupplierID != nil {
+			key = "id:" + l.SupplierID.Hex()
+		}
+		g, ok := groups[key]
+		if !ok {
+			g = &SupplierSuggestion{SupplierID: l.SupplierID, SupplierName: l.SupplierName}
+			groups[key] = g
+			result = append(result, g)
+		}
+		g.Lines = append(g.Lines, l)
+		g.EstimatedTotal += l.EstimatedCost
+		if l.LeadTimeDays > g.LeadTimeDays {
+			g.LeadTimeDays = l.LeadTimeDays
+		}
+	}
+
+	for _, g := range result {
+		sort.SliceStable(g.Lines, func(i, j int) bool { return coverLess(g.Lines[i], g.Lines[j]) })
+	}
+	sort.SliceStable(result, func(i, j int) bool { return coverLess(result[i].Lines[0], result[j].Lines[0]) })
+	return result
+}
+
+// SortByCover orders lines by days of cover, lines with no consumption last
+func SortByCover(lines []*ReorderLine) {
+	sort.SliceStable(lines, func(i, j int) bool { return coverLess(lines[i], lines[j]) })
+}
+
+func coverLess(a, b *ReorderLine) bool {
+	if a.DaysOfCover == nil || b.DaysOfCover == nil {
+		return a.DaysOfCover != nil
+	}
+	return *a.DaysOfCover < *b.DaysOfCover
+}
+
+func round3(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
@@ -0,0 +1,157 @@
+package purchasing
+
+import (
+	"testing"
+	"time"
+
+	"cafe-pos/backend/domain/ingredient"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestUsageByWeekday tests averaging consumption per weekday over the lookback window
+func TestUsageByWeekday(t *testing.T) {
+	milk := primitive.NewObjectID()
+	// Monday 2024-01-15; two weeks back starts Monday 2024-01-01
+	at := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	from, to := ConsumptionWindow(at, 14)
+	if !from.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) || !to.Equal(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Unexpected window %v - %v", from, to)
+	}
+
+	history := []*ingredient.StockHistory{
+		{IngredientID: milk, Type: ingredient.TransactionOrder, Quantity: -4, CreatedAt: time.Date(2024, 1, 6, 10, 0, 0, 0, time.UTC)},  // Saturday
+		{IngredientID: milk, Type: ingredient.TransactionOrder, Quantity: -6, CreatedAt: time.Date(2024, 1, 13, 10, 0, 0, 0, time.UTC)}, // Saturday
+		{IngredientID: milk, Type: ingredient.TransactionWaste, Quantity: -1, CreatedAt: time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)},  // Tuesday
+		{IngredientID: milk, Type: ingredient.TransactionPurchase, Quantity: 20, CreatedAt: time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)},
+		{IngredientID: milk, Type: ingredient.TransactionStocktake, Quantity: -3, CreatedAt: time.Date(2024, 1, 4, 10, 0, 0, 0, time.UTC)},
+		{IngredientID: milk, Type: ingredient.TransactionOrder, Quantity: -9, CreatedAt: time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC)}, // today, outside
+	}
+
+	usage := UsageByWeekday(history, from, to)[milk]
+	if usage[time.Saturday] != 5 {
+		t.Errorf("Expected 5 per Saturday, got %v", usage[time.Saturday])
+	}
+	if usage[time.Tuesday] != 0.5 {
+		t.Errorf("Expected 0.5 per Tuesday, got %v", usage[time.Tuesday])
+	}
+	if usage[time.Thursday] != 0 || usage[time.Monday] != 0 {
+		t.Errorf("Expected stocktake and today's usage to be ignored, got %v", usage)
+	}
+}
+
+// TestPlanReorder tests the reorder point, days of cover and suggested quantity
+func TestPlanReorder(t *testing.T) {
+	beans := &ingredient.Ingredient{ID: primitive.NewObjectID(), Name: "Cà phê hạt", Unit: ingredient.UnitKilogram, Quantity: 5, MinStock: 1, CostPerUnit: 300000}
+	usage := WeekdayUsage{2, 1, 1, 1, 1, 1, 3}         // Sunday first, 10 kg a week
+	at := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC) // Monday
+
+	line := PlanReorder(beans, usage, 0, 2, ReorderParams{SafetyDays: 1, ReviewDays: 7}, at)
+	// Mon..Thu 4 kg, 1 kg left on Friday
+	if line.DaysOfCover == nil || *line.DaysOfCover != 5 {
+		t.Errorf("Expected 5 days of cover, got %v", line.DaysOfCover)
+	}
+	// Safety is the larger of MinStock and one day of average usage
+	if line.SafetyStock != 1.429 {
+		t.Errorf("Expected safety stock 1.429, got %v", line.SafetyStock)
+	}
+	// Monday and Tuesday usage plus safety
+	if line.ReorderPoint != 3.429 {
+		t.Errorf("Expected reorder point 3.429, got %v", line.ReorderPoint)
+	}
+	if line.NeedsReorder {
+		t.Error("Expected no reorder above the reorder point")
+	}
+
+	beans.Quantity = 3
+	line = PlanReorder(beans, usage, 0, 2, ReorderParams{SafetyDays: 1, ReviewDays: 7}, at)
+	// Nine days from Monday use 12 kg, plus safety, minus 3 on hand
+	if !line.NeedsReorder || line.SuggestedQuantity != 10.429 {
+		t.Errorf("Expected 10.429 kg suggested, got %v (%v)", line.SuggestedQuantity, line.NeedsReorder)
+	}
+
+	// Stock already on order counts as available
+	line = PlanReorder(beans, usage, 12, 2, ReorderParams{SafetyDays: 1, ReviewDays: 7}, at)
+	if line.NeedsReorder {
+		t.Error("Expected no reorder with enough stock on order")
+	}
+
+	// Nothing consumed: only MinStock triggers a reorder
+	beans.Quantity = 0.5
+	line = PlanReorder(beans, WeekdayUsage{}, 0, 0, ReorderParams{}, at)
+	if line.DaysOfCover != nil || line.LeadTimeDays != DefaultLeadTimeDays {
+		t.Errorf("Expected no cover and default lead time, got %v %v", line.DaysOfCover, line.LeadTimeDays)
+	}
+	if !line.NeedsReorder || line.SuggestedQuantity != 0.5 {
+		t.Errorf("Expected 0.5 kg to reach MinStock, got %v", line.SuggestedQuantity)
+	}
+}
+
+// TestDaysOfCover tests walking the days of the week and skipping whole weeks of stock
+func TestDaysOfCover(t *testing.T) {
+	monday := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	busy := WeekdayUsage{2, 1, 1, 1, 1, 1, 3} // Sunday first, 10 kg a week
+	mondaysOnly := WeekdayUsage{0, 4, 0, 0, 0, 0, 0}
+
+	tests := []struct {
+		name     string
+		usage    WeekdayUsage
+		quantity float64
+		start    time.Time
+		want     float64
+	}{
+		{"part of a day", busy, 0.5, monday, 0.5},
+		{"within the week", busy, 5, monday, 5},
+		{"exactly a week", busy, 10, monday, 7},
+		{"weeks and days", busy, 25, monday, 19},
+		{"a year of stock", busy, 520, monday, 364},
+		{"millions of weeks", busy, 1e9, monday, 7e8},
+		{"used on the first day", mondaysOnly, 4, monday, 1},
+		{"used on the first day of the second week", mondaysOnly, 8, monday, 8},
+		{"used at the end of the week", mondaysOnly, 4, monday.AddDate(0, 0, 1), 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.usage.DaysOfCover(tt.quantity, tt.start)
+			if got == nil || *got != tt.want {
+				t.Errorf("Expected %v days of cover, got %v", tt.want, got)
+			}
+		})
+	}
+
+	if got := (WeekdayUsage{}).DaysOfCover(5, monday); got != nil {
+		t.Errorf("Expected no cover without usage, got %v", *got)
+	}
+}
+
+// TestSetOrderUnitAndGroup tests rounding to supplier units and grouping by supplier
+func TestSetOrderUnitAndGroup(t *testing.T) {
+	supplierID := primitive.NewObjectID()
+	cover := func(d float64) *float64 { return &d }
+
+	cups := &ReorderLine{IngredientName: "Ly giấy", SupplierID: &supplierID, SupplierName: "Bao bì Minh Long", NeedsReorder: true, SuggestedQuantity: 130, LeadTimeDays: 3, DaysOfCover: cover(2)}
+	cups.SetOrderUnit(ingredient.UnitBox, 50, 60000, 0)
+	if cups.OrderQuantity != 3 || cups.EstimatedCost != 180000 {
+		t.Errorf("Expected 3 boxes for 180000, got %v for %v", cups.OrderQuantity, cups.EstimatedCost)
+	}
+
+	lids := &ReorderLine{IngredientName: "Nắp ly", SupplierID: &supplierID, SupplierName: "Bao bì Minh Long", NeedsReorder: true, SuggestedQuantity: 20, LeadTimeDays: 2, DaysOfCover: cover(1)}
+	lids.SetOrderUnit(ingredient.UnitBox, 100, 40000, 2)
+	if lids.OrderQuantity != 2 {
+		t.Errorf("Expected the minimum order of 2 boxes, got %v", lids.OrderQuantity)
+	}
+
+	sugar := &ReorderLine{IngredientName: "Đường", SupplierName: "Chợ Bến Thành", NeedsReorder: true, SuggestedQuantity: 2, OrderQuantity: 2, EstimatedCost: 40000, DaysOfCover: cover(4)}
+	milk := &ReorderLine{IngredientName: "Sữa tươi", SupplierName: "Vinamilk", DaysOfCover: cover(10)}
+
+	groups := GroupBySupplier([]*ReorderLine{sugar, cups, milk, lids})
+	if len(groups) != 2 {
+		t.Fatalf("Expected 2 suppliers, got %d", len(groups))
+	}
+	if groups[0].SupplierName != "Bao bì Minh Long" || groups[0].Lines[0] != lids || groups[0].LeadTimeDays != 3 {
+		t.Errorf("Expected the most urgent supplier first with its most urgent line first, got %+v", groups[0])
+	}
+	if groups[0].EstimatedTotal != 260000 {
+		t.Errorf("Expected total 260000, got %v", groups[0].EstimatedTotal)
+	}
+}
@@ -0,0 +1,136 @@
+package roster
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"cafe-pos/backend/domain/order"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AttendanceRecord compares a planned shift with the shift actually worked for it
+type AttendanceRecord struct {
+	PlannedShift      *PlannedShift       `json:"planned_shift"`
+	ShiftID           *primitive.ObjectID `json:"shift_id,omitempty"`
+	StartedAt         *time.Time          `json:"started_at,omitempty"`
+	EndedAt           *time.Time          `json:"ended_at,omitempty"`
+	Pending           bool                `json:"pending"`     // not started yet and not over
+	InProgress        bool                `json:"in_progress"` // started and not ended yet
+	NoShow            bool                `json:"no_show"`
+	Late              bool                `json:"late"`
+	LateMinutes       float64             `json:"late_minutes"`
+	LeftEarly         bool                `json:"left_early"`
+	EarlyLeaveMinutes float64             `json:"early_leave_minutes"`
+}
+
+// StaffAttendance totals the attendance of one staff member
+type StaffAttendance struct {
+	UserID            primitive.ObjectID `json:"user_id"`
+	UserName          string             `json:"user_name"`
+	PlannedShifts     int                `json:"planned_shifts"`
+	WorkedShifts      int                `json:"worked_shifts"`
+	NoShows           int                `json:"no_shows"`
+	LateStarts        int                `json:"late_starts"`
+	EarlyLeaves       int                `json:"early_leaves"`
+	LateMinutes       float64            `json:"late_minutes"`
+	EarlyLeaveMinutes float64            `json:"early_leave_minutes"`
+	PlannedHours      float64            `json:"planned_hours"`
+}
+
+type AttendanceReport struct {
+	From         time.Time          `json:"from"`
+	To           time.Time          `json:"to"`
+	GraceMinutes int                `json:"grace_minutes"`
+	Records      []AttendanceRecord `json:"records"`
+	Staff        []StaffAttendance  `json:"staff"`
+}
+
+// CompareAttendance checks a planned shift against the shift started for it, or nil if none
+// was. Starts later or leaves earlier than grace count as late starts and early leaves; a
+// planned shift that is over without being started is a no-show.
+func CompareAttendance(p *PlannedShift, actual *order.Shift, grace time.Duration, now time.Time) AttendanceRecord {
+	record := AttendanceRecord{PlannedShift: p}
+	if actual == nil {
+		record.NoShow = !now.Before(p.EndsAt)
+		record.Pending = !record.NoShow
+		return record
+	}
+
+	record.ShiftID = &actual.ID
+	started := actual.StartedAt
+	record.StartedAt = &started
+	if late := started.Sub(p.StartsAt); late > grace {
+		record.Late = true
+		record.LateMinutes = roundMinutes(late)
+	}
+
+	if actual.EndedAt == nil {
+		record.InProgress = true
+		return record
+	}
+	ended := *actual.EndedAt
+	record.EndedAt = &ended
+	if early := p.EndsAt.Sub(ended); early > grace {
+		record.LeftEarly = true
+		record.EarlyLeaveMinutes = roundMinutes(early)
+	}
+	return record
+}
+
+// BuildAttendanceReport compares the planned shifts with the shifts worked, looked up by ID,
+// and totals the results per staff member
+func BuildAttendanceReport(planned []*PlannedShift, shifts map[primitive.ObjectID]*order.Shift, grace time.Duration, from, to, now time.Time) *AttendanceReport {
+	report := &AttendanceReport{
+		From:         from,
+		To:           to,
+		GraceMinutes: int(grace / time.Minute),
+		Records:      make([]AttendanceRecord, 0, len(planned)),
+		Staff:        []StaffAttendance{},
+	}
+
+	sorted := make([]*PlannedShift, len(planned))
+	copy(sorted, planned)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].StartsAt.Before(sorted[j].StartsAt) })
+
+	staffIndex := make(map[primitive.ObjectID]int)
+	for _, p := range sorted {
+		var actual *order.Shift
+		if p.ShiftID != nil {
+			actual = shifts[*p.ShiftID]
+		}
+		record := CompareAttendance(p, actual, grace, now)
+		report.Records = append(report.Records, record)
+
+		i, ok := staffIndex[p.UserID]
+		if !ok {
+			i = len(report.Staff)
+			staffIndex[p.UserID] = i
+			report.Staff = append(report.Staff, StaffAttendance{UserID: p.UserID, UserName: p.UserName})
+		}
+		staff := &report.Staff[i]
+		staff.PlannedShifts++
+		staff.PlannedHours += p.Duration().Hours()
+		if record.ShiftID != nil {
+			staff.WorkedShifts++
+		}
+		if record.NoShow {
+			staff.NoShows++
+		}
+		if record.Late {
+			staff.LateStarts++
+			staff.LateMinutes += record.LateMinutes
+		}
+		if record.LeftEarly {
+			staff.EarlyLeaves++
+			staff.EarlyLeaveMinutes += record.EarlyLeaveMinutes
+		}
+	}
+
+	sort.SliceStable(report.Staff, func(i, j int) bool { return report.Staff[i].UserName < report.Staff[j].UserName })
+	return report
+}
+
+func roundMinutes(d time.Duration) float64 {
+	return math.Round(d.Minutes()*10) / 10
+}
@@ -0,0 +1,128 @@
+package roster
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"cafe-pos/backend/domain/menu"
+	"cafe-pos/backend/domain/order"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PlannedShiftStatus string
+
+const (
+	PlannedScheduled PlannedShiftStatus = "SCHEDULED"
+	PlannedStarted   PlannedShiftStatus = "STARTED" // linked to the shift actually started
+)
+
+const (
+	// EarlyStartWindow is how long before its planned start a shift may be started and
+	// still count as the planned one
+	EarlyStartWindow = 2 * time.Hour
+	// DefaultGraceMinutes is how late a start or how early a leave is tolerated
+	DefaultGraceMinutes = 5
+)
+
+// PlannedShift is a shift on the roster: who is expected to work, in which role, and when.
+// Starting a shift links it to the matching planned shift.
+type PlannedShift struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	UserName  string              `bson:"user_name" json:"user_name"`
+	RoleType  order.RoleType      `bson:"role_type" json:"role_type"`
+	Type      order.ShiftType     `bson:"type" json:"type"`
+	StartsAt  time.Time           `bson:"starts_at" json:"starts_at"`
+	EndsAt    time.Time           `bson:"ends_at" json:"ends_at"`
+	Notes     string              `bson:"notes,omitempty" json:"notes,omitempty"`
+	Status    PlannedShiftStatus  `bson:"status" json:"status"`
+	ShiftID   *primitive.ObjectID `bson:"shift_id,omitempty" json:"shift_id,omitempty"`
+	CreatedBy string              `bson:"created_by" json:"created_by"`
+	CreatedAt time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+type PlannedShiftRequest struct {
+	UserID      string          `json:"user_id" binding:"required"`
+	RoleType    order.RoleType  `json:"role_type"`                     // defaults to the role of the user
+	Type        order.ShiftType `json:"type"`                          // derived from the start time if empty
+	Date        string          `json:"date" binding:"required"`       // YYYY-MM-DD
+	StartTime   string          `json:"start_time" binding:"required"` // HH:MM
+	EndTime     string          `json:"end_time" binding:"required"`   // HH:MM, before StartTime for overnight shifts
+	Notes       string          `json:"notes"`
+	RepeatWeeks int             `json:"repeat_weeks" binding:"min=0,max=12"` // also plan the same shift this many following weeks
+}
+
+// Schedule validates the date and times of the request and returns the planned start and end.
+// Dates and times are read in loc.
+func (r *PlannedShiftRequest) Schedule(loc *time.Location) (time.Time, time.Time, error) {
+	day, err := time.ParseInLocation("2006-01-02", r.Date, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", r.Date)
+	}
+	start, err := menu.ParseClock(r.StartTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := menu.ParseClock(r.EndTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if start == end {
+		return time.Time{}, time.Time{}, errors.New("start_time and end_time must differ")
+	}
+
+	startsAt := atMinute(day, start)
+	endsAt := atMinute(day, end)
+	if end < start {
+		endsAt = atMinute(day.AddDate(0, 0, 1), end)
+	}
+	return startsAt, endsAt, nil
+}
+
+func atMinute(day time.Time, minute int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), minute/60, minute%60, 0, 0, day.Location())
+}
+
+// Duration returns the planned length of the shift
+func (p *PlannedShift) Duration() time.Duration {
+	return p.EndsAt.Sub(p.StartsAt)
+}
+
+// Overlaps reports whether both planned shifts are for the same person at overlapping times
+func (p *PlannedShift) Overlaps(other *PlannedShift) bool {
+	return p.UserID == other.UserID && p.StartsAt.Before(other.EndsAt) && other.StartsAt.Before(p.EndsAt)
+}
+
+// CanChange returns an error if the planned shift has already been worked
+func (p *PlannedShift) CanChange() error {
+	if p.Status != PlannedScheduled {
+		return errors.New("planned shift has already been started")
+	}
+	return nil
+}
+
+// MatchPlannedShift finds the scheduled shift of the given role that a shift started at `at`
+// belongs to: one that has not ended yet and starts at most EarlyStartWindow later. When
+// several match, the one with the closest planned start is used. Returns nil if none matches.
+func MatchPlannedShift(planned []*PlannedShift, role order.RoleType, at time.Time) *PlannedShift {
+	var best *PlannedShift
+	var bestGap time.Duration
+	for _, p := range planned {
+		if p.Status != PlannedScheduled || p.RoleType != role {
+			continue
+		}
+		if !at.Before(p.EndsAt) || at.Before(p.StartsAt.Add(-EarlyStartWindow)) {
+			continue
+		}
+		gap := at.Sub(p.StartsAt)
+		if gap < 0 {
+			gap = -gap
+		}
+		if best == nil || gap < bestGap {
+			best, bestGap = p, gap
+		}
+	}
+	return best
+}
@@ -0,0 +1,122 @@
+package roster
+
+import (
+	"testing"
+	"time"
+
+	"cafe-pos/backend/domain/order"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func planned(userID primitive.ObjectID, role order.RoleType, start time.Time, hours int) *PlannedShift {
+	return &PlannedShift{
+		ID:       primitive.NewObjectID(),
+		UserID:   userID,
+		UserName: "Lan",
+		RoleType: role,
+		StartsAt: start,
+		EndsAt:   start.Add(time.Duration(hours) * time.Hour),
+		Status:   PlannedScheduled,
+	}
+}
+
+// TestSchedule tests parsing of planned times, including overnight shifts
+func TestSchedule(t *testing.T) {
+	req := &PlannedShiftRequest{Date: "2024-03-04", StartTime: "18:00", EndTime: "02:00"}
+	start, end, err := req.Schedule(time.UTC)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !start.Equal(time.Date(2024, 3, 4, 18, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2024, 3, 5, 2, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected 18:00 to 02:00 the next day, got %v - %v", start, end)
+	}
+
+	for _, bad := range []PlannedShiftRequest{
+		{Date: "04/03/2024", StartTime: "07:00", EndTime: "12:00"},
+		{Date: "2024-03-04", StartTime: "7h", EndTime: "12:00"},
+		{Date: "2024-03-04", StartTime: "07:00", EndTime: "07:00"},
+	} {
+		if _, _, err := bad.Schedule(time.UTC); err == nil {
+			t.Errorf("Expected an error for %+v", bad)
+		}
+	}
+}
+
+// TestOverlapsAndMatch tests overlap detection and linking a started shift to the roster
+func TestOverlapsAndMatch(t *testing.T) {
+	user := primitive.NewObjectID()
+	day := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	morning := planned(user, order.RoleWaiter, day.Add(7*time.Hour), 5)
+	afternoon := planned(user, order.RoleWaiter, day.Add(13*time.Hour), 5)
+	bar := planned(user, order.RoleBarista, day.Add(11*time.Hour), 4)
+
+	if morning.Overlaps(afternoon) || !morning.Overlaps(bar) {
+		t.Error("Expected only the barista shift to overlap the morning shift")
+	}
+	if other := planned(primitive.NewObjectID(), order.RoleWaiter, day.Add(8*time.Hour), 4); morning.Overlaps(other) {
+		t.Error("Expected shifts of different staff not to overlap")
+	}
+
+	all := []*PlannedShift{morning, afternoon, bar}
+	if got := MatchPlannedShift(all, order.RoleWaiter, day.Add(6*time.Hour)); got != morning {
+		t.Error("Expected an early start to match the morning shift")
+	}
+	if got := MatchPlannedShift(all, order.RoleWaiter, day.Add(12*time.Hour+30*time.Minute)); got != afternoon {
+		t.Error("Expected a start just before 13:00 to match the afternoon shift")
+	}
+	if got := MatchPlannedShift(all, order.RoleBarista, day.Add(12*time.Hour)); got != bar {
+		t.Error("Expected the barista shift to match by role")
+	}
+	if got := MatchPlannedShift(all, order.RoleWaiter, day.Add(4*time.Hour)); got != nil {
+		t.Error("Expected no match long before any shift")
+	}
+	afternoon.Status = PlannedStarted
+	if got := MatchPlannedShift(all, order.RoleWaiter, day.Add(13*time.Hour)); got != nil {
+		t.Error("Expected a started planned shift not to match again")
+	}
+}
+
+// TestAttendanceReport tests no-shows, late starts and early leaves with a grace period
+func TestAttendanceReport(t *testing.T) {
+	user := primitive.NewObjectID()
+	day := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	now := day.Add(20 * time.Hour)
+	grace := DefaultGraceMinutes * time.Minute
+
+	onTime := planned(user, order.RoleWaiter, day.Add(7*time.Hour), 5)
+	late := planned(user, order.RoleWaiter, day.Add(13*time.Hour), 5)
+	missed := planned(user, order.RoleWaiter, day.Add(-24*time.Hour+7*time.Hour), 5)
+	upcoming := planned(user, order.RoleWaiter, day.Add(31*time.Hour), 5)
+
+	end1 := onTime.EndsAt.Add(-3 * time.Minute)
+	shift1 := &order.Shift{ID: primitive.NewObjectID(), StartedAt: onTime.StartsAt.Add(4 * time.Minute), EndedAt: &end1}
+	end2 := late.EndsAt.Add(-time.Hour)
+	shift2 := &order.Shift{ID: primitive.NewObjectID(), StartedAt: late.StartsAt.Add(20 * time.Minute), EndedAt: &end2}
+	onTime.ShiftID, late.ShiftID = &shift1.ID, &shift2.ID
+	shifts := map[primitive.ObjectID]*order.Shift{shift1.ID: shift1, shift2.ID: shift2}
+
+	report := BuildAttendanceReport([]*PlannedShift{upcoming, late, onTime, missed}, shifts, grace, day, now, now)
+	if len(report.Records) != 4 || report.Records[0].PlannedShift != missed {
+		t.Fatalf("Expected 4 records in planned order, got %+v", report.Records)
+	}
+	if r := report.Records[0]; !r.NoShow || r.Pending {
+		t.Errorf("Expected a no-show, got %+v", r)
+	}
+	if r := report.Records[1]; r.Late || r.LeftEarly || r.NoShow {
+		t.Errorf("Expected an on-time shift within grace, got %+v", r)
+	}
+	if r := report.Records[2]; !r.Late || r.LateMinutes != 20 || !r.LeftEarly || r.EarlyLeaveMinutes != 60 {
+		t.Errorf("Expected 20 minutes late and 60 minutes early, got %+v", r)
+	}
+	if r := report.Records[3]; !r.Pending || r.NoShow {
+		t.Errorf("Expected an upcoming shift to be pending, got %+v", r)
+	}
+
+	if len(report.Staff) != 1 {
+		t.Fatalf("Expected one staff member, got %d", len(report.Staff))
+	}
+	s := report.Staff[0]
+	if s.PlannedShifts != 4 || s.WorkedShifts != 2 || s.NoShows != 1 || s.LateStarts != 1 || s.EarlyLeaves != 1 || s.PlannedHours != 20 {
+		t.Errorf("Unexpected staff totals %+v", s)
+	}
+}
@@ -0,0 +1,86 @@
+package shifttype
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"cafe-pos/backend/domain/order"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TypeSummary totals the shifts of one shift type
+type TypeSummary struct {
+	Code               order.ShiftType `json:"code"`
+	Name               string          `json:"name"`
+	StartTime          string          `json:"start_time,omitempty"`
+	EndTime            string          `json:"end_time,omitempty"`
+	Shifts             int             `json:"shifts"`
+	OpenShifts         int             `json:"open_shifts"`
+	Staff              int             `json:"staff"`
+	Hours              float64         `json:"hours"` // closed shifts only
+	Orders             int             `json:"orders"`
+	Revenue            float64         `json:"revenue"`
+	AvgRevenuePerShift float64         `json:"avg_revenue_per_shift"`
+}
+
+type Report struct {
+	From    time.Time     `json:"from"`
+	To      time.Time     `json:"to"`
+	Types   []TypeSummary `json:"types"`
+	Shifts  int           `json:"shifts"`
+	Orders  int           `json:"orders"`
+	Revenue float64       `json:"revenue"`
+}
+
+// BuildReport groups shifts by type in the order of the definitions. Types that are no longer
+// defined, e.g. deactivated ones, are listed after them.
+func BuildReport(shifts []*order.Shift, defs []*Definition, from, to time.Time) *Report {
+	report := &Report{From: from, To: to, Types: []TypeSummary{}}
+	index := map[order.ShiftType]int{}
+	for _, d := range defs {
+		index[d.Code] = len(report.Types)
+		report.Types = append(report.Types, TypeSummary{Code: d.Code, Name: d.Name, StartTime: d.StartTime, EndTime: d.EndTime})
+	}
+
+	staff := map[order.ShiftType]map[primitive.ObjectID]bool{}
+	for _, s := range shifts {
+		i, ok := index[s.Type]
+		if !ok {
+			i = len(report.Types)
+			index[s.Type] = i
+			report.Types = append(report.Types, TypeSummary{Code: s.Type, Name: string(s.Type)})
+		}
+		summary := &report.Types[i]
+		summary.Shifts++
+		if s.EndedAt == nil {
+			summary.OpenShifts++
+		} else {
+			summary.Hours += s.EndedAt.Sub(s.StartedAt).Hours()
+		}
+		summary.Orders += s.TotalOrders
+		summary.Revenue += s.TotalRevenue
+		if staff[s.Type] == nil {
+			staff[s.Type] = map[primitive.ObjectID]bool{}
+		}
+		staff[s.Type][s.UserID] = true
+
+		report.Shifts++
+		report.Orders += s.TotalOrders
+		report.Revenue += s.TotalRevenue
+	}
+
+	for i := range report.Types {
+		summary := &report.Types[i]
+		summary.Staff = len(staff[summary.Code])
+		summary.Hours = math.Round(summary.Hours*100) / 100
+		if summary.Shifts > 0 {
+			summary.AvgRevenuePerShift = math.Round(summary.Revenue / float64(summary.Shifts))
+		}
+	}
+	sort.SliceStable(report.Types[len(defs):], func(i, j int) bool {
+		rest := report.Types[len(defs):]
+		return rest[i].Code < rest[j].Code
+	})
+	return report
+}
@@ -0,0 +1,217 @@
+package shifttype
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"cafe-pos/backend/domain/menu"
+	"cafe-pos/backend/domain/order"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const minutesPerDay = 24 * 60
+
+// Definition is a type of shift with the time window it covers, e.g. MORNING from 06:00 to
+// 12:00. Definitions without a store apply to every store that has none of its own.
+type Definition struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Code      order.ShiftType    `bson:"code" json:"code"`
+	Name      string             `bson:"name" json:"name"`
+	Store     string             `bson:"store,omitempty" json:"store,omitempty"`
+	StartTime string             `bson:"start_time" json:"start_time"` // HH:MM
+	EndTime   string             `bson:"end_time" json:"end_time"`     // HH:MM, before StartTime for overnight windows
+	Active    bool               `bson:"active" json:"active"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+type DefinitionRequest struct {
+	Code      string `json:"code" binding:"required,max=30"`
+	Name      string `json:"name" binding:"required"`
+	Store     string `json:"store"`
+	StartTime string `json:"start_time" binding:"required"`
+	EndTime   string `json:"end_time" binding:"required"`
+	Active    *bool  `json:"active"`
+}
+
+// Defaults are the shift types used until any are defined
+func Defaults() []*Definition {
+	return []*Definition{
+		{Code: order.ShiftMorning, Name: "Ca sáng", StartTime: "06:00", EndTime: "12:00", Active: true},
+		{Code: order.ShiftAfternoon, Name: "Ca chiều", StartTime: "12:00", EndTime: "18:00", Active: true},
+		{Code: order.ShiftEvening, Name: "Ca tối", StartTime: "18:00", EndTime: "06:00", Active: true},
+	}
+}
+
+// NormalizeCode upper-cases a shift type code and replaces spaces with underscores
+func NormalizeCode(code string) order.ShiftType {
+	return order.ShiftType(strings.ReplaceAll(strings.ToUpper(strings.TrimSpace(code)), " ", "_"))
+}
+
+// Apply validates the request and sets it on the definition
+func (d *Definition) Apply(req *DefinitionRequest) error {
+	code := NormalizeCode(req.Code)
+	if code == "" {
+		return errors.New("code is required")
+	}
+	start, err := menu.ParseClock(req.StartTime)
+	if err != nil {
+		return err
+	}
+	end, err := menu.ParseClock(req.EndTime)
+	if err != nil {
+		return err
+	}
+	if start == end {
+		return errors.New("start_time and end_time must differ")
+	}
+
+	d.Code = code
+	d.Name = strings.TrimSpace(req.Name)
+	d.Store = strings.TrimSpace(req.Store)
+	d.StartTime = req.StartTime
+	d.EndTime = req.EndTime
+	if req.Active != nil {
+		d.Active = *req.Active
+	}
+	return nil
+}
+
+// window returns the start and end of the definition in minutes after midnight
+func (d *Definition) window() (int, int, bool) {
+	start, err := menu.ParseClock(d.StartTime)
+	if err != nil {
+		return 0, 0, false
+	}
+	end, err := menu.ParseClock(d.EndTime)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// Covers reports whether the window contains the given minute of the day
+func (d *Definition) Covers(minute int) bool {
+	start, end, ok := d.window()
+	if !ok {
+		return false
+	}
+	if start < end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// Overlaps reports whether the windows of both definitions share any minute
+func (d *Definition) Overlaps(other *Definition) bool {
+	for minute := 0; minute < minutesPerDay; minute++ {
+		if d.Covers(minute) && other.Covers(minute) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckConflicts returns an error if the definition reuses the code or overlaps the window
+// of another active definition of the same store
+func (d *Definition) CheckConflicts(others []*Definition) error {
+	for _, other := range others {
+		if other.ID == d.ID || other.Store != d.Store {
+			continue
+		}
+		if other.Code == d.Code {
+			return fmt.Errorf("shift type %s already exists", d.Code)
+		}
+		if d.Active && other.Active && d.Overlaps(other) {
+			return fmt.Errorf("%s-%s overlaps shift type %s (%s-%s)", d.StartTime, d.EndTime, other.Code, other.StartTime, other.EndTime)
+		}
+	}
+	return nil
+}
+
+// ForStore returns the active definitions that apply to a store: its own if it has any,
+// otherwise those without a store, otherwise the defaults. They are sorted by start time.
+func ForStore(defs []*Definition, store string) []*Definition {
+	var own, shared []*Definition
+	for _, d := range defs {
+		if !d.Active {
+			continue
+		}
+		switch d.Store {
+		case store:
+			own = append(own, d)
+		case "":
+			shared = append(shared, d)
+		}
+	}
+	result := own
+	if len(result) == 0 {
+		result = shared
+	}
+	if len(result) == 0 {
+		result = Defaults()
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].StartTime < result[j].StartTime })
+	return result
+}
+
+// Derive returns the shift type whose window contains t. A start outside every window, e.g.
+// a few minutes early, gets the type whose window starts closest to t.
+func Derive(defs []*Definition, t time.Time) (order.ShiftType, bool) {
+	minute := t.Hour()*60 + t.Minute()
+	var nearest *Definition
+	nearestGap := minutesPerDay
+	for _, d := range defs {
+		if d.Covers(minute) {
+			return d.Code, true
+		}
+		start, _, ok := d.window()
+		if !ok {
+			continue
+		}
+		gap := minute - start
+		if gap < 0 {
+			gap = -gap
+		}
+		if gap > minutesPerDay/2 {
+			gap = minutesPerDay - gap
+		}
+		if gap < nearestGap {
+			nearest, nearestGap = d, gap
+		}
+	}
+	if nearest == nil {
+		return "", false
+	}
+	return nearest.Code, true
+}
+
+// Find returns the definition with the given code
+func Find(defs []*Definition, code order.ShiftType) (*Definition, bool) {
+	for _, d := range defs {
+		if d.Code == code {
+			return d, true
+		}
+	}
+	return nil, false
+}
+
+// Resolve returns the requested shift type if it is one of defs, or the type derived from the
+// start time if none was requested
+func Resolve(defs []*Definition, requested order.ShiftType, at time.Time) (order.ShiftType, error) {
+	if requested == "" {
+		code, ok := Derive(defs, at)
+		if !ok {
+			return "", errors.New("no shift types are defined")
+		}
+		return code, nil
+	}
+	code := NormalizeCode(string(requested))
+	if _, ok := Find(defs, code); !ok {
+		return "", fmt.Errorf("invalid shift type %q", requested)
+	}
+	return code, nil
+}
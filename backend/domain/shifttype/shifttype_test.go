@@ -0,0 +1,133 @@
+package shifttype
+
+import (
+	"testing"
+	"time"
+
+	"cafe-pos/backend/domain/order"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestDerive tests picking the shift type from the start time, including overnight windows
+func TestDerive(t *testing.T) {
+	defs := ForStore(nil, "")
+	day := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	cases := map[time.Duration]order.ShiftType{
+		7 * time.Hour:                 order.ShiftMorning,
+		12 * time.Hour:                order.ShiftAfternoon,
+		17*time.Hour + 59*time.Minute: order.ShiftAfternoon,
+		22 * time.Hour:                order.ShiftEvening,
+		2 * time.Hour:                 order.ShiftEvening,
+	}
+	for offset, want := range cases {
+		if got, ok := Derive(defs, day.Add(offset)); !ok || got != want {
+			t.Errorf("At %v expected %s, got %s", offset, want, got)
+		}
+	}
+
+	// Outside every window the closest start wins
+	split := []*Definition{
+		{Code: "OPENING", StartTime: "07:00", EndTime: "11:00", Active: true},
+		{Code: "CLOSING", StartTime: "15:00", EndTime: "22:00", Active: true},
+	}
+	if got, _ := Derive(split, day.Add(6*time.Hour+45*time.Minute)); got != "OPENING" {
+		t.Errorf("Expected an early start to count as OPENING, got %s", got)
+	}
+	if got, _ := Derive(split, day.Add(14*time.Hour)); got != "CLOSING" {
+		t.Errorf("Expected 14:00 to count as CLOSING, got %s", got)
+	}
+	if _, ok := Derive(nil, day); ok {
+		t.Error("Expected no shift type without definitions")
+	}
+}
+
+// TestForStore tests that a store's own definitions replace the shared ones
+func TestForStore(t *testing.T) {
+	defs := []*Definition{
+		{Code: "LATE", StartTime: "14:00", EndTime: "23:00", Active: true},
+		{Code: "EARLY", StartTime: "06:00", EndTime: "14:00", Active: true},
+		{Code: "FULL", Store: "q1", StartTime: "08:00", EndTime: "20:00", Active: true},
+		{Code: "NIGHT", Store: "q1", StartTime: "20:00", EndTime: "02:00", Active: false},
+	}
+	if got := ForStore(defs, ""); len(got) != 2 || got[0].Code != "EARLY" {
+		t.Errorf("Expected the shared definitions by start time, got %+v", got)
+	}
+	if got := ForStore(defs, "q1"); len(got) != 1 || got[0].Code != "FULL" {
+		t.Errorf("Expected only the active q1 definition, got %+v", got)
+	}
+	if got := ForStore(defs[3:], "q1"); len(got) != 3 {
+		t.Errorf("Expected the defaults without active definitions, got %+v", got)
+	}
+}
+
+// TestApplyAndConflicts tests validation of definitions
+func TestApplyAndConflicts(t *testing.T) {
+	active := true
+	d := &Definition{}
+	if err := d.Apply(&DefinitionRequest{Code: "late night", Name: "Ca khuya", StartTime: "22:00", EndTime: "22:00"}); err == nil {
+		t.Error("Expected an empty window to be rejected")
+	}
+	if err := d.Apply(&DefinitionRequest{Code: "x", Name: "x", StartTime: "25:00", EndTime: "02:00"}); err == nil {
+		t.Error("Expected an invalid time to be rejected")
+	}
+	if err := d.Apply(&DefinitionRequest{Code: "late night", Name: "Ca khuya", StartTime: "22:00", EndTime: "02:00", Active: &active}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if d.Code != "LATE_NIGHT" || !d.Active {
+		t.Errorf("Expected an active LATE_NIGHT definition, got %+v", d)
+	}
+
+	evening := &Definition{ID: primitive.NewObjectID(), Code: order.ShiftEvening, StartTime: "18:00", EndTime: "23:00", Active: true}
+	if err := d.CheckConflicts([]*Definition{evening}); err == nil {
+		t.Error("Expected an overlap with EVENING")
+	}
+	evening.Store = "q1"
+	if err := d.CheckConflicts([]*Definition{evening}); err != nil {
+		t.Errorf("Expected definitions of other stores to be ignored, got %v", err)
+	}
+	same := &Definition{ID: primitive.NewObjectID(), Code: "LATE_NIGHT", StartTime: "03:00", EndTime: "05:00", Active: true}
+	if err := d.CheckConflicts([]*Definition{same}); err == nil {
+		t.Error("Expected a duplicate code to be rejected")
+	}
+}
+
+// TestBuildReport tests grouping shifts by type
+func TestBuildReport(t *testing.T) {
+	start := time.Date(2024, 3, 4, 7, 0, 0, 0, time.UTC)
+	end := start.Add(8 * time.Hour)
+	lan, minh := primitive.NewObjectID(), primitive.NewObjectID()
+	shifts := []*order.Shift{
+		{Type: order.ShiftMorning, UserID: lan, StartedAt: start, EndedAt: &end, TotalOrders: 20, TotalRevenue: 1000000},
+		{Type: order.ShiftMorning, UserID: lan, StartedAt: start.AddDate(0, 0, 1), TotalOrders: 4, TotalRevenue: 200000},
+		{Type: order.ShiftEvening, UserID: minh, StartedAt: start, EndedAt: &end, TotalOrders: 10, TotalRevenue: 600000},
+		{Type: "BRUNCH", UserID: minh, StartedAt: start, EndedAt: &end},
+	}
+	report := BuildReport(shifts, Defaults(), start, start.AddDate(0, 0, 2))
+
+	if len(report.Types) != 4 || report.Types[3].Code != "BRUNCH" {
+		t.Fatalf("Expected the defaults followed by BRUNCH, got %+v", report.Types)
+	}
+	morning := report.Types[0]
+	if morning.Shifts != 2 || morning.OpenShifts != 1 || morning.Staff != 1 || morning.Hours != 8 || morning.AvgRevenuePerShift != 600000 {
+		t.Errorf("Unexpected morning summary %+v", morning)
+	}
+	if report.Types[1].Shifts != 0 || report.Shifts != 4 || report.Orders != 34 || report.Revenue != 1800000 {
+		t.Errorf("Unexpected totals %+v", report)
+	}
+}
+
+// TestResolve tests requested and derived shift types
+func TestResolve(t *testing.T) {
+	defs := Defaults()
+	at := time.Date(2024, 3, 4, 13, 0, 0, 0, time.UTC)
+	if got, err := Resolve(defs, "", at); err != nil || got != order.ShiftAfternoon {
+		t.Errorf("Expected AFTERNOON derived, got %s %v", got, err)
+	}
+	if got, err := Resolve(defs, "morning", at); err != nil || got != order.ShiftMorning {
+		t.Errorf("Expected the requested MORNING, got %s %v", got, err)
+	}
+	if _, err := Resolve(defs, "NIGHT", at); err == nil {
+		t.Error("Expected an undefined shift type to be rejected")
+	}
+}
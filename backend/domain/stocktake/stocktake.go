@@ -0,0 +1,189 @@
+package stocktake
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"cafe-pos/backend/domain/ingredient"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Status string
+
+const (
+	StatusOpen      Status = "OPEN"      // Đang kiểm kê
+	StatusClosed    Status = "CLOSED"    // Đã chốt và điều chỉnh tồn kho
+	StatusCancelled Status = "CANCELLED" // Đã hủy, không điều chỉnh
+)
+
+// AreaCount is the quantity of an ingredient counted in one storage area, in the stock unit
+type AreaCount struct {
+	Area      string    `bson:"area" json:"area"`
+	Quantity  float64   `bson:"quantity" json:"quantity"`
+	CountedBy string    `bson:"counted_by" json:"counted_by"`
+	CountedAt time.Time `bson:"counted_at" json:"counted_at"`
+}
+
+// Line is one ingredient in a stocktake. Quantities are in the stock unit.
+type Line struct {
+	IngredientID   primitive.ObjectID  `bson:"ingredient_id" json:"ingredient_id"`
+	IngredientName string              `bson:"ingredient_name" json:"ingredient_name"`
+	Category       string              `bson:"category" json:"category"`
+	Unit           ingredient.UnitType `bson:"unit" json:"unit"`
+	// SystemQuantity is the recorded stock when the stocktake was opened, taken again at close
+	SystemQuantity float64     `bson:"system_quantity" json:"system_quantity"`
+	Counts         []AreaCount `bson:"counts" json:"counts"`
+	// CountedQuantity is the total over all areas, nil until the ingredient is counted
+	CountedQuantity *float64 `bson:"counted_quantity,omitempty" json:"counted_quantity,omitempty"`
+	Variance        float64  `bson:"variance" json:"variance"` // counted - system
+	UnitCost        float64  `bson:"unit_cost" json:"unit_cost"`
+	VarianceValue   float64  `bson:"variance_value" json:"variance_value"`
+}
+
+type Stocktake struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Number   string             `bson:"number" json:"number"`
+	Name     string             `bson:"name" json:"name"`
+	Category string             `bson:"category,omitempty" json:"category,omitempty"` // empty counts every ingredient
+	Status   Status             `bson:"status" json:"status"`
+	Lines    []Line             `bson:"lines" json:"lines"`
+	Notes    string             `bson:"notes,omitempty" json:"notes,omitempty"`
+	// Totals at cost, filled when the stocktake is closed
+	CountedLines   int        `bson:"counted_lines" json:"counted_lines"`
+	UncountedLines int        `bson:"uncounted_lines" json:"uncounted_lines"`
+	ShrinkageValue float64    `bson:"shrinkage_value" json:"shrinkage_value"` // missing stock, positive
+	SurplusValue   float64    `bson:"surplus_value" json:"surplus_value"`
+	VarianceValue  float64    `bson:"variance_value" json:"variance_value"` // surplus - shrinkage
+	OpenedBy       string     `bson:"opened_by" json:"opened_by"`
+	OpenedAt       time.Time  `bson:"opened_at" json:"opened_at"`
+	ClosedBy       string     `bson:"closed_by,omitempty" json:"closed_by,omitempty"`
+	ClosedAt       *time.Time `bson:"closed_at,omitempty" json:"closed_at,omitempty"`
+	CreatedAt      time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time  `bson:"updated_at" json:"updated_at"`
+}
+
+type OpenRequest struct {
+	Name string `json:"name"`
+	// Category limits the stocktake to one ingredient category
+	Category string `json:"category"`
+	Notes    string `json:"notes"`
+}
+
+type CountEntry struct {
+	IngredientID string  `json:"ingredient_id" binding:"required"`
+	Area         string  `json:"area"`
+	Quantity     float64 `json:"quantity" binding:"min=0"`
+	// Unit of Quantity, defaults to the ingredient unit
+	Unit string `json:"unit"`
+}
+
+type CountRequest struct {
+	Counts []CountEntry `json:"counts" binding:"required,min=1,dive"`
+}
+
+type CloseRequest struct {
+	Notes string `json:"notes"`
+}
+
+// NewStocktake opens a stocktake over the given ingredients, recording their current stock
+func NewStocktake(number string, req *OpenRequest, ingredients []*ingredient.Ingredient, openedBy string, at time.Time) (*Stocktake, error) {
+	st := &Stocktake{
+		Number:   number,
+		Name:     req.Name,
+		Category: req.Category,
+		Status:   StatusOpen,
+		Lines:    []Line{},
+		Notes:    req.Notes,
+		OpenedBy: openedBy,
+		OpenedAt: at,
+	}
+	if st.Name == "" {
+		st.Name = "Kiểm kê " + at.Format("02/01/2006")
+	}
+	for _, ing := range ingredients {
+		if req.Category != "" && ing.Category != req.Category {
+			continue
+		}
+		st.Lines = append(st.Lines, Line{
+			IngredientID:   ing.ID,
+			IngredientName: ing.Name,
+			Category:       ing.Category,
+			Unit:           ing.Unit,
+			SystemQuantity: ing.Quantity,
+			Counts:         []AreaCount{},
+			UnitCost:       ing.CostPerUnit,
+		})
+	}
+	if len(st.Lines) == 0 {
+		return nil, errors.New("no ingredients to count")
+	}
+	return st, nil
+}
+
+// LineFor returns the line of an ingredient, nil if it is not part of the stocktake
+func (st *Stocktake) LineFor(ingredientID primitive.ObjectID) *Line {
+	for i := range st.Lines {
+		if st.Lines[i].IngredientID == ingredientID {
+			return &st.Lines[i]
+		}
+	}
+	return nil
+}
+
+// SetCount records the counted quantity of the line in an area, replacing an earlier count
+// of the same area
+func (l *Line) SetCount(area string, quantity float64, countedBy string, at time.Time) error {
+	if quantity < 0 {
+		return fmt.Errorf("counted quantity of %s cannot be negative", l.IngredientName)
+	}
+	area = strings.TrimSpace(area)
+	count := AreaCount{Area: area, Quantity: quantity, CountedBy: countedBy, CountedAt: at}
+
+	replaced := false
+	for i := range l.Counts {
+		if strings.EqualFold(l.Counts[i].Area, area) {
+			l.Counts[i] = count
+			replaced = true
+		}
+	}
+	if !replaced {
+		l.Counts = append(l.Counts, count)
+	}
+
+	total := 0.0
+	for _, c := range l.Counts {
+		total += c.Quantity
+	}
+	l.CountedQuantity = &total
+	return nil
+}
+
+// CalculateVariance computes the variance of counted lines against the system quantity and
+// the totals at cost. Uncounted lines have no variance.
+func (st *Stocktake) CalculateVariance() {
+	st.CountedLines, st.UncountedLines = 0, 0
+	st.ShrinkageValue, st.SurplusValue = 0, 0
+	for i := range st.Lines {
+		l := &st.Lines[i]
+		if l.CountedQuantity == nil {
+			l.Variance, l.VarianceValue = 0, 0
+			st.UncountedLines++
+			continue
+		}
+		st.CountedLines++
+		l.Variance = *l.CountedQuantity - l.SystemQuantity
+		if math.Abs(l.Variance) < 1e-9 {
+			l.Variance = 0
+		}
+		l.VarianceValue = math.Round(l.Variance * l.UnitCost)
+		if l.VarianceValue < 0 {
+			st.ShrinkageValue -= l.VarianceValue
+		} else {
+			st.SurplusValue += l.VarianceValue
+		}
+	}
+	st.VarianceValue = st.SurplusValue - st.ShrinkageValue
+}
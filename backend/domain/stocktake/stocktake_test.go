@@ -0,0 +1,72 @@
+package stocktake
+
+import (
+	"testing"
+	"time"
+
+	"cafe-pos/backend/domain/ingredient"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testIngredients() []*ingredient.Ingredient {
+	return []*ingredient.Ingredient{
+		{ID: primitive.NewObjectID(), Name: "Sữa tươi", Category: "Sữa", Unit: ingredient.UnitLiter, Quantity: 20, CostPerUnit: 30000},
+		{ID: primitive.NewObjectID(), Name: "Cà phê bột", Category: "Cà phê", Unit: ingredient.UnitKilogram, Quantity: 5, CostPerUnit: 300000},
+		{ID: primitive.NewObjectID(), Name: "Ly giấy", Category: "Bao bì", Unit: ingredient.UnitPiece, Quantity: 200, CostPerUnit: 1000},
+	}
+}
+
+// TestNewStocktakeCategory tests that a stocktake can be limited to one category
+func TestNewStocktakeCategory(t *testing.T) {
+	now := time.Now()
+	st, err := NewStocktake("ST-1", &OpenRequest{Category: "Sữa"}, testIngredients(), "manager", now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(st.Lines) != 1 || st.Lines[0].IngredientName != "Sữa tươi" || st.Lines[0].SystemQuantity != 20 {
+		t.Errorf("Expected one line for Sữa tươi at 20, got %+v", st.Lines)
+	}
+	if st.Status != StatusOpen || st.Name == "" {
+		t.Errorf("Expected an open stocktake with a default name, got %s %q", st.Status, st.Name)
+	}
+
+	if _, err := NewStocktake("ST-2", &OpenRequest{Category: "Bánh"}, testIngredients(), "manager", now); err == nil {
+		t.Error("Expected an error for a category without ingredients")
+	}
+}
+
+// TestVariance tests counts by area and the variance at cost
+func TestVariance(t *testing.T) {
+	ingredients := testIngredients()
+	st, _ := NewStocktake("ST-1", &OpenRequest{}, ingredients, "manager", time.Now())
+
+	milk := st.LineFor(ingredients[0].ID)
+	milk.SetCount("Tủ lạnh quầy", 6, "waiter", time.Now())
+	milk.SetCount("Kho", 12, "waiter", time.Now())
+	milk.SetCount("kho", 11, "barista", time.Now()) // recount replaces the same area
+	if *milk.CountedQuantity != 17 || len(milk.Counts) != 2 {
+		t.Fatalf("Expected 17 over 2 areas, got %v over %d", *milk.CountedQuantity, len(milk.Counts))
+	}
+
+	cups := st.LineFor(ingredients[2].ID)
+	cups.SetCount("", 210, "waiter", time.Now())
+
+	if err := cups.SetCount("", -1, "waiter", time.Now()); err == nil {
+		t.Error("Expected an error for a negative count")
+	}
+
+	st.CalculateVariance()
+
+	if milk.Variance != -3 || milk.VarianceValue != -90000 {
+		t.Errorf("Expected milk variance -3 (-90000), got %v (%v)", milk.Variance, milk.VarianceValue)
+	}
+	if cups.Variance != 10 || cups.VarianceValue != 10000 {
+		t.Errorf("Expected cups variance 10 (10000), got %v (%v)", cups.Variance, cups.VarianceValue)
+	}
+	if st.CountedLines != 2 || st.UncountedLines != 1 {
+		t.Errorf("Expected 2 counted and 1 uncounted, got %d and %d", st.CountedLines, st.UncountedLines)
+	}
+	if st.ShrinkageValue != 90000 || st.SurplusValue != 10000 || st.VarianceValue != -80000 {
+		t.Errorf("Expected shrinkage 90000, surplus 10000, net -80000, got %v, %v, %v", st.ShrinkageValue, st.SurplusValue, st.VarianceValue)
+	}
+}
@@ -0,0 +1,71 @@
+package supplier
+
+import (
+	"sort"
+	"time"
+
+	"cafe-pos/backend/domain/expense"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BuildSpendReport totals expenses per supplier. Expenses are attributed by SupplierID, or by
+// matching the free-text vendor with a supplier name for records created before suppliers existed.
+// Vendors matching no supplier are listed separately; expenses without a vendor are left out.
+func BuildSpendReport(expenses []expense.Expense, suppliers []*Supplier, from, to time.Time) *SpendReport {
+	byID := make(map[primitive.ObjectID]*Supplier, len(suppliers))
+	byName := make(map[string]*Supplier, len(suppliers))
+	for _, s := range suppliers {
+		byID[s.ID] = s
+		byName[NameKey(s.Name)] = s
+	}
+
+	lines := make(map[primitive.ObjectID]*SpendLine)
+	unassigned := make(map[string]*SpendLine)
+	report := &SpendReport{From: from, To: to, Suppliers: []SpendLine{}, Unassigned: []SpendLine{}, GeneratedAt: time.Now()}
+
+	for _, e := range expenses {
+		var line *SpendLine
+		var s *Supplier
+		if e.SupplierID != nil {
+			s = byID[*e.SupplierID]
+		}
+		if s == nil {
+			s = byName[NameKey(e.Vendor)]
+		}
+
+		switch {
+		case s != nil:
+			if line = lines[s.ID]; line == nil {
+				id := s.ID
+				line = &SpendLine{SupplierID: &id, SupplierName: s.Name, ByCategory: map[string]float64{}}
+				lines[s.ID] = line
+			}
+		case NameKey(e.Vendor) != "":
+			key := NameKey(e.Vendor)
+			if line = unassigned[key]; line == nil {
+				line = &SpendLine{SupplierName: e.Vendor, ByCategory: map[string]float64{}}
+				unassigned[key] = line
+			}
+		default:
+			continue
+		}
+
+		line.Amount += e.Amount
+		line.ExpenseCount++
+		line.ByCategory[e.CategoryID.Hex()] += e.Amount
+		report.Total += e.Amount
+	}
+
+	for _, line := range lines {
+		report.Suppliers = append(report.Suppliers, *line)
+	}
+	for _, line := range unassigned {
+		report.Unassigned = append(report.Unassigned, *line)
+	}
+	byAmount := func(list []SpendLine) func(i, j int) bool {
+		return func(i, j int) bool { return list[i].Amount > list[j].Amount }
+	}
+	sort.Slice(report.Suppliers, byAmount(report.Suppliers))
+	sort.Slice(report.Unassigned, byAmount(report.Unassigned))
+	return report
+}
@@ -0,0 +1,138 @@
+package supplier
+
+import (
+	"strings"
+	"time"
+
+	"cafe-pos/backend/domain/ingredient"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BankAccount is where payments to a supplier are transferred
+type BankAccount struct {
+	BankName      string `bson:"bank_name" json:"bank_name"`
+	AccountNumber string `bson:"account_number" json:"account_number"`
+	AccountName   string `bson:"account_name" json:"account_name"`
+}
+
+// Supplier is a vendor of ingredients, equipment or services. Ingredients, facilities,
+// maintenance records, expenses and purchase orders reference it by ID.
+type Supplier struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name        string             `bson:"name" json:"name"`
+	ContactName string             `bson:"contact_name" json:"contact_name"`
+	Phone       string             `bson:"phone" json:"phone"`
+	Email       string             `bson:"email" json:"email"`
+	Address     string             `bson:"address" json:"address"`
+	TaxCode     string             `bson:"tax_code" json:"tax_code"` // Mã số thuế
+	// PaymentTermDays is the number of days after delivery the invoice is due, 0 for cash on delivery
+	PaymentTermDays int         `bson:"payment_term_days" json:"payment_term_days"`
+	PaymentTerms    string      `bson:"payment_terms" json:"payment_terms"`
+	LeadTimeDays    int         `bson:"lead_time_days" json:"lead_time_days"` // from order to delivery
+	BankAccount     BankAccount `bson:"bank_account" json:"bank_account"`
+	Notes           string      `bson:"notes" json:"notes"`
+	Active          bool        `bson:"active" json:"active"`
+	CreatedAt       time.Time   `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time   `bson:"updated_at" json:"updated_at"`
+}
+
+type SupplierRequest struct {
+	Name            string      `json:"name" binding:"required"`
+	ContactName     string      `json:"contact_name"`
+	Phone           string      `json:"phone"`
+	Email           string      `json:"email" binding:"omitempty,email"`
+	Address         string      `json:"address"`
+	TaxCode         string      `json:"tax_code"`
+	PaymentTermDays int         `json:"payment_term_days" binding:"min=0"`
+	PaymentTerms    string      `json:"payment_terms"`
+	LeadTimeDays    int         `json:"lead_time_days" binding:"min=0"`
+	BankAccount     BankAccount `json:"bank_account"`
+	Notes           string      `json:"notes"`
+	Active          *bool       `json:"active"`
+}
+
+// PriceListEntry is the price a supplier charges for an ingredient, valid from a date
+type PriceListEntry struct {
+	ID             primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	SupplierID     primitive.ObjectID  `bson:"supplier_id" json:"supplier_id"`
+	IngredientID   primitive.ObjectID  `bson:"ingredient_id" json:"ingredient_id"`
+	IngredientName string              `bson:"ingredient_name" json:"ingredient_name"`
+	Unit           ingredient.UnitType `bson:"unit" json:"unit"`
+	Price          float64             `bson:"price" json:"price"` // per Unit
+	MinOrderQty    float64             `bson:"min_order_qty" json:"min_order_qty"`
+	ValidFrom      time.Time           `bson:"valid_from" json:"valid_from"`
+	ValidTo        *time.Time          `bson:"valid_to" json:"valid_to,omitempty"`
+	CreatedAt      time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time           `bson:"updated_at" json:"updated_at"`
+	// Filled when listing prices of an ingredient across suppliers, not stored
+	SupplierName   string  `bson:"-" json:"supplier_name,omitempty"`
+	StockUnitPrice float64 `bson:"-" json:"stock_unit_price,omitempty"`
+}
+
+type PriceListEntryRequest struct {
+	IngredientID string              `json:"ingredient_id" binding:"required"`
+	Unit         ingredient.UnitType `json:"unit"` // defaults to the ingredient stock unit
+	Price        float64             `json:"price" binding:"min=0"`
+	MinOrderQty  float64             `json:"min_order_qty" binding:"min=0"`
+	ValidFrom    *time.Time          `json:"valid_from"` // defaults to now
+	ValidTo      *time.Time          `json:"valid_to"`
+}
+
+// IsValidAt reports whether the price applies at the given time
+func (p *PriceListEntry) IsValidAt(at time.Time) bool {
+	if at.Before(p.ValidFrom) {
+		return false
+	}
+	return p.ValidTo == nil || at.Before(*p.ValidTo)
+}
+
+// CurrentPrices keeps the latest valid entry per supplier and ingredient
+func CurrentPrices(entries []*PriceListEntry, at time.Time) []*PriceListEntry {
+	type key struct{ supplier, ingredient primitive.ObjectID }
+	latest := make(map[key]*PriceListEntry)
+	var order []key
+	for _, e := range entries {
+		if !e.IsValidAt(at) {
+			continue
+		}
+		k := key{e.SupplierID, e.IngredientID}
+		prev, ok := latest[k]
+		if !ok {
+			order = append(order, k)
+		}
+		if !ok || e.ValidFrom.After(prev.ValidFrom) {
+			latest[k] = e
+		}
+	}
+
+	result := make([]*PriceListEntry, 0, len(order))
+	for _, k := range order {
+		result = append(result, latest[k])
+	}
+	return result
+}
+
+// NameKey normalises a supplier name for matching free-text vendor fields
+func NameKey(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// SpendLine is the total expense amount attributed to one supplier
+type SpendLine struct {
+	SupplierID   *primitive.ObjectID `json:"supplier_id,omitempty"`
+	SupplierName string              `json:"supplier_name"`
+	Amount       float64             `json:"amount"`
+	ExpenseCount int                 `json:"expense_count"`
+	// ByCategory maps expense category IDs to amounts
+	ByCategory map[string]float64 `json:"by_category"`
+}
+
+// SpendReport is the expense total per supplier over a period
+type SpendReport struct {
+	From        time.Time   `json:"from"`
+	To          time.Time   `json:"to"`
+	Total       float64     `json:"total"`
+	Suppliers   []SpendLine `json:"suppliers"`
+	Unassigned  []SpendLine `json:"unassigned"` // vendors that do not match any supplier
+	GeneratedAt time.Time   `json:"generated_at"`
+}
@@ -0,0 +1,67 @@
+package supplier
+
+import (
+	"testing"
+	"time"
+
+	"cafe-pos/backend/domain/expense"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestCurrentPrices tests that only the latest valid price per supplier and ingredient is kept
+func TestCurrentPrices(t *testing.T) {
+	now := time.Date(2024, 6, 15, 10, 0, 0, 0, time.UTC)
+	supplierID := primitive.NewObjectID()
+	coffee := primitive.NewObjectID()
+	milk := primitive.NewObjectID()
+	expired := now.AddDate(0, 0, -1)
+
+	entries := []*PriceListEntry{
+		{SupplierID: supplierID, IngredientID: coffee, Price: 280000, ValidFrom: now.AddDate(0, -3, 0)},
+		{SupplierID: supplierID, IngredientID: coffee, Price: 300000, ValidFrom: now.AddDate(0, -1, 0)},
+		{SupplierID: supplierID, IngredientID: coffee, Price: 320000, ValidFrom: now.AddDate(0, 1, 0)}, // future
+		{SupplierID: supplierID, IngredientID: milk, Price: 25000, ValidFrom: now.AddDate(0, -2, 0), ValidTo: &expired},
+	}
+
+	current := CurrentPrices(entries, now)
+	if len(current) != 1 {
+		t.Fatalf("Expected 1 current price, got %d", len(current))
+	}
+	if current[0].IngredientID != coffee || current[0].Price != 300000 {
+		t.Errorf("Expected coffee at 300000, got %v at %v", current[0].IngredientID, current[0].Price)
+	}
+}
+
+// TestBuildSpendReport tests attribution of expenses by supplier ID and by vendor name
+func TestBuildSpendReport(t *testing.T) {
+	trungNguyen := &Supplier{ID: primitive.NewObjectID(), Name: "Trung Nguyên"}
+	vinamilk := &Supplier{ID: primitive.NewObjectID(), Name: "Vinamilk"}
+	category := primitive.NewObjectID()
+
+	expenses := []expense.Expense{
+		{Amount: 3000000, CategoryID: category, SupplierID: &trungNguyen.ID, Vendor: "TN"},
+		{Amount: 500000, CategoryID: category, Vendor: "  trung   nguyên "},
+		{Amount: 800000, CategoryID: category, Vendor: "Vinamilk"},
+		{Amount: 200000, CategoryID: category, Vendor: "Chợ Bến Thành"},
+		{Amount: 100000, CategoryID: category},
+	}
+
+	report := BuildSpendReport(expenses, []*Supplier{trungNguyen, vinamilk}, time.Time{}, time.Now())
+
+	if report.Total != 4500000 {
+		t.Errorf("Expected total 4500000, got %v", report.Total)
+	}
+	if len(report.Suppliers) != 2 {
+		t.Fatalf("Expected 2 suppliers, got %d", len(report.Suppliers))
+	}
+	first := report.Suppliers[0]
+	if first.SupplierName != "Trung Nguyên" || first.Amount != 3500000 || first.ExpenseCount != 2 {
+		t.Errorf("Expected Trung Nguyên 3500000 over 2 expenses, got %s %v over %d", first.SupplierName, first.Amount, first.ExpenseCount)
+	}
+	if first.ByCategory[category.Hex()] != 3500000 {
+		t.Errorf("Expected category total 3500000, got %v", first.ByCategory[category.Hex()])
+	}
+	if len(report.Unassigned) != 1 || report.Unassigned[0].SupplierName != "Chợ Bến Thành" {
+		t.Errorf("Expected Chợ Bến Thành unassigned, got %+v", report.Unassigned)
+	}
+}
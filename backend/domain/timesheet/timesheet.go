@@ -0,0 +1,376 @@
+package timesheet
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cafe-pos/backend/domain/cashier"
+	"cafe-pos/backend/domain/order"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Defaults follow the Vietnamese Labour Code: 8 hours a day, 48 a week, nights from 22:00 to 06:00
+const (
+	DefaultDailyLimitHours  = 8.0
+	DefaultWeeklyLimitHours = 48.0
+	DefaultNightStartHour   = 22
+	DefaultNightEndHour     = 6
+)
+
+// DefaultHolidays are the fixed-date public holidays in Vietnam (MM-DD). Lunar New Year and
+// Hùng Kings' day move every year and have to be configured as dates.
+var DefaultHolidays = []string{"01-01", "04-30", "05-01", "09-02"}
+
+// Rules configure how worked hours are classified
+type Rules struct {
+	DailyLimitHours  float64  `json:"daily_limit_hours"`
+	WeeklyLimitHours float64  `json:"weekly_limit_hours"`
+	NightStartHour   int      `json:"night_start_hour"`
+	NightEndHour     int      `json:"night_end_hour"`
+	Holidays         []string `json:"holidays"` // YYYY-MM-DD for a single date, MM-DD for every year
+}
+
+// DefaultRules returns the default limits, night hours and holidays
+func DefaultRules() Rules {
+	return Rules{
+		DailyLimitHours:  DefaultDailyLimitHours,
+		WeeklyLimitHours: DefaultWeeklyLimitHours,
+		NightStartHour:   DefaultNightStartHour,
+		NightEndHour:     DefaultNightEndHour,
+		Holidays:         append([]string(nil), DefaultHolidays...),
+	}
+}
+
+// ParseHolidays reads a comma separated list of YYYY-MM-DD and MM-DD dates
+func ParseHolidays(s string) ([]string, error) {
+	var holidays []string
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", v); err != nil {
+			if _, err := time.Parse("01-02", v); err != nil {
+				return nil, fmt.Errorf("invalid holiday %q, expected YYYY-MM-DD or MM-DD", v)
+			}
+		}
+		holidays = append(holidays, v)
+	}
+	return holidays, nil
+}
+
+// IsHoliday reports whether the day of t is a holiday
+func (r Rules) IsHoliday(t time.Time) bool {
+	date := t.Format("2006-01-02")
+	for _, h := range r.Holidays {
+		if h == date || h == date[5:] {
+			return true
+		}
+	}
+	return false
+}
+
+// WorkPeriod is a stretch of time worked by an employee, from a waiter/barista or cashier shift
+type WorkPeriod struct {
+	UserID   primitive.ObjectID
+	UserName string
+	Role     string
+	ShiftID  primitive.ObjectID
+	Start    time.Time
+	End      *time.Time // nil while the shift is still open
+	Breaks   []Interval // unpaid breaks, not counted as hours worked
+}
+
+// Interval is the time from Start to End
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// FromShift returns the work period of a waiter or barista shift
+func FromShift(s *order.Shift) WorkPeriod {
+	p := WorkPeriod{UserID: s.UserID, UserName: s.UserName, Role: string(s.RoleType), ShiftID: s.ID, Start: s.StartedAt, End: s.EndedAt}
+	for _, b := range s.Breaks {
+		if b.Type == order.BreakUnpaid && b.EndedAt != nil {
+			p.Breaks = append(p.Breaks, Interval{Start: b.StartedAt, End: *b.EndedAt})
+		}
+	}
+	return p
+}
+
+// FromCashierShift returns the work period of a cashier shift
+func FromCashierShift(s *cashier.CashierShift) WorkPeriod {
+	return WorkPeriod{UserID: s.CashierID, UserName: s.CashierName, Role: "cashier", ShiftID: s.ID, Start: s.StartTime, End: s.EndTime}
+}
+
+// DayHours are the hours worked by an employee on one calendar day
+type DayHours struct {
+	Date          string  `json:"date"`
+	Hours         float64 `json:"hours"`
+	NightHours    float64 `json:"night_hours"`
+	OvertimeHours float64 `json:"overtime_hours"` // beyond the daily limit
+	Holiday       bool    `json:"holiday"`
+}
+
+// EmployeeTimesheet totals the hours of one employee over the period. Hours worked on
+// holidays are counted as holiday hours only, never as regular or overtime hours. Night
+// hours are also counted in the regular, overtime or holiday hours they fall in.
+type EmployeeTimesheet struct {
+	UserID              primitive.ObjectID `json:"user_id"`
+	UserName            string             `json:"user_name"`
+	Roles               []string           `json:"roles"`
+	Shifts              int                `json:"shifts"`
+	OpenShifts          int                `json:"open_shifts"` // still open, not counted
+	TotalHours          float64            `json:"total_hours"`
+	RegularHours        float64            `json:"regular_hours"`
+	NightHours          float64            `json:"night_hours"`
+	DailyOvertimeHours  float64            `json:"daily_overtime_hours"`
+	WeeklyOvertimeHours float64            `json:"weekly_overtime_hours"`
+	OvertimeHours       float64            `json:"overtime_hours"`
+	HolidayHours        float64            `json:"holiday_hours"`
+	UnpaidBreakHours    float64            `json:"unpaid_break_hours"` // already left out of the hours above
+	Days                []DayHours         `json:"days"`
+}
+
+type Timesheet struct {
+	From      time.Time           `json:"from"`
+	To        time.Time           `json:"to"`
+	Rules     Rules               `json:"rules"`
+	Employees []EmployeeTimesheet `json:"employees"`
+}
+
+// Build computes the timesheets of all employees with work periods in [from, to]. Periods
+// are cut at the bounds of the pay period and split at midnight, in the location of from.
+// Weekly overtime is counted per Monday-to-Sunday week, using only the days in the period.
+// Unpaid breaks are left out of the hours worked.
+func Build(periods []WorkPeriod, rules Rules, from, to time.Time) *Timesheet {
+	loc := from.Location()
+	ts := &Timesheet{From: from, To: to, Rules: rules, Employees: []EmployeeTimesheet{}}
+
+	type employee struct {
+		sheet EmployeeTimesheet
+		days  map[string]*DayHours
+		roles map[string]bool
+	}
+	byUser := make(map[primitive.ObjectID]*employee)
+	var users []primitive.ObjectID
+
+	for _, p := range periods {
+		e, ok := byUser[p.UserID]
+		if !ok {
+			e = &employee{
+				sheet: EmployeeTimesheet{UserID: p.UserID, UserName: p.UserName},
+				days:  make(map[string]*DayHours),
+				roles: make(map[string]bool),
+			}
+			byUser[p.UserID] = e
+			users = append(users, p.UserID)
+		}
+		if !e.roles[p.Role] {
+			e.roles[p.Role] = true
+			e.sheet.Roles = append(e.sheet.Roles, p.Role)
+		}
+		if p.End == nil {
+			e.sheet.OpenShifts++
+			continue
+		}
+
+		start, end := p.Start.In(loc), p.End.In(loc)
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if !end.After(start) {
+			continue
+		}
+		e.sheet.Shifts++
+
+		worked := workedIntervals(start, end, p.Breaks)
+		total := end.Sub(start).Hours()
+		for _, interval := range worked {
+			total -= interval.End.Sub(interval.Start).Hours()
+			for start := interval.Start; start.Before(interval.End); {
+				midnight := startOfDay(start).AddDate(0, 0, 1)
+				segmentEnd := interval.End
+				if midnight.Before(segmentEnd) {
+					segmentEnd = midnight
+				}
+				date := start.Format("2006-01-02")
+				day, ok := e.days[date]
+				if !ok {
+					day = &DayHours{Date: date, Holiday: rules.IsHoliday(start)}
+					e.days[date] = day
+				}
+				day.Hours += segmentEnd.Sub(start).Hours()
+				day.NightHours += rules.nightHours(start, segmentEnd)
+				start = segmentEnd
+			}
+		}
+		e.sheet.UnpaidBreakHours += total
+	}
+
+	for _, id := range users {
+		e := byUser[id]
+		sheet := &e.sheet
+		sheet.Days = make([]DayHours, 0, len(e.days))
+		for _, day := range e.days {
+			sheet.Days = append(sheet.Days, *day)
+		}
+		sort.Slice(sheet.Days, func(i, j int) bool { return sheet.Days[i].Date < sheet.Days[j].Date })
+
+		weekRegular := make(map[string]float64)
+		var weeks []string
+		for i := range sheet.Days {
+			day := &sheet.Days[i]
+			sheet.TotalHours += day.Hours
+			sheet.NightHours += day.NightHours
+			if day.Holiday {
+				sheet.HolidayHours += day.Hours
+				continue
+			}
+			regular := day.Hours
+			if rules.DailyLimitHours > 0 && regular > rules.DailyLimitHours {
+				day.OvertimeHours = round2(regular - rules.DailyLimitHours)
+				sheet.DailyOvertimeHours += regular - rules.DailyLimitHours
+				regular = rules.DailyLimitHours
+			}
+			week := weekKey(day.Date, loc)
+			if _, ok := weekRegular[week]; !ok {
+				weeks = append(weeks, week)
+			}
+			weekRegular[week] += regular
+		}
+		for _, week := range weeks {
+			if rules.WeeklyLimitHours > 0 && weekRegular[week] > rules.WeeklyLimitHours {
+				sheet.WeeklyOvertimeHours += weekRegular[week] - rules.WeeklyLimitHours
+			}
+		}
+		sheet.OvertimeHours = sheet.DailyOvertimeHours + sheet.WeeklyOvertimeHours
+		sheet.RegularHours = sheet.TotalHours - sheet.HolidayHours - sheet.OvertimeHours
+
+		for i := range sheet.Days {
+			sheet.Days[i].Hours = round2(sheet.Days[i].Hours)
+			sheet.Days[i].NightHours = round2(sheet.Days[i].NightHours)
+		}
+		sheet.TotalHours = round2(sheet.TotalHours)
+		sheet.RegularHours = round2(sheet.RegularHours)
+		sheet.NightHours = round2(sheet.NightHours)
+		sheet.DailyOvertimeHours = round2(sheet.DailyOvertimeHours)
+		sheet.WeeklyOvertimeHours = round2(sheet.WeeklyOvertimeHours)
+		sheet.OvertimeHours = round2(sheet.OvertimeHours)
+		sheet.HolidayHours = round2(sheet.HolidayHours)
+		sheet.UnpaidBreakHours = round2(sheet.UnpaidBreakHours)
+		ts.Employees = append(ts.Employees, *sheet)
+	}
+
+	sort.SliceStable(ts.Employees, func(i, j int) bool { return ts.Employees[i].UserName < ts.Employees[j].UserName })
+	return ts
+}
+
+// workedIntervals returns [start, end) without the breaks, in order
+func workedIntervals(start, end time.Time, breaks []Interval) []Interval {
+	sorted := append([]Interval(nil), breaks...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	var worked []Interval
+	for _, b := range sorted {
+		if !b.End.After(start) {
+			continue
+		}
+		if !b.Start.Before(end) {
+			break
+		}
+		if b.Start.After(start) {
+			worked = append(worked, Interval{Start: start, End: b.Start.In(start.Location())})
+		}
+		start = b.End.In(start.Location())
+	}
+	if end.After(start) {
+		worked = append(worked, Interval{Start: start, End: end})
+	}
+	return worked
+}
+
+// nightHours returns the hours of [start, end) within the night window. start and end are on
+// the same day; the window wraps around midnight when it starts after it ends.
+func (r Rules) nightHours(start, end time.Time) float64 {
+	day := startOfDay(start)
+	at := func(hour int) time.Time { return day.Add(time.Duration(hour) * time.Hour) }
+
+	if r.NightStartHour == r.NightEndHour {
+		return 0
+	}
+	if r.NightStartHour < r.NightEndHour {
+		return overlap(start, end, at(r.NightStartHour), at(r.NightEndHour))
+	}
+	return overlap(start, end, day, at(r.NightEndHour)) + overlap(start, end, at(r.NightStartHour), day.AddDate(0, 0, 1))
+}
+
+func overlap(start, end, windowStart, windowEnd time.Time) float64 {
+	if windowStart.After(start) {
+		start = windowStart
+	}
+	if windowEnd.Before(end) {
+		end = windowEnd
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start).Hours()
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// weekKey returns the Monday of the week of a YYYY-MM-DD date
+func weekKey(date string, loc *time.Location) string {
+	day, _ := time.ParseInLocation("2006-01-02", date, loc)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset).Format("2006-01-02")
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// ExportColumns are the columns of the CSV/XLSX export of a timesheet
+var ExportColumns = []string{
+	"employee", "roles", "from", "to", "shifts", "total_hours", "regular_hours", "night_hours",
+	"daily_overtime_hours", "weekly_overtime_hours", "overtime_hours", "holiday_hours", "unpaid_break_hours",
+	"open_shifts",
+}
+
+// ExportRecords returns one row per employee including the header, for payroll
+func (ts *Timesheet) ExportRecords() [][]string {
+	hours := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	from := ts.From.Format("2006-01-02")
+	to := ts.To.Format("2006-01-02")
+
+	records := [][]string{ExportColumns}
+	for _, e := range ts.Employees {
+		records = append(records, []string{
+			e.UserName,
+			strings.Join(e.Roles, "/"),
+			from,
+			to,
+			strconv.Itoa(e.Shifts),
+			hours(e.TotalHours),
+			hours(e.RegularHours),
+			hours(e.NightHours),
+			hours(e.DailyOvertimeHours),
+			hours(e.WeeklyOvertimeHours),
+			hours(e.OvertimeHours),
+			hours(e.HolidayHours),
+			hours(e.UnpaidBreakHours),
+			strconv.Itoa(e.OpenShifts),
+		})
+	}
+	return records
+}
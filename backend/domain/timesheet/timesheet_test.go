@@ -0,0 +1,145 @@
+package timesheet
+
+import (
+	"testing"
+	"time"
+
+	"cafe-pos/backend/domain/order"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func period(userID primitive.ObjectID, name string, start time.Time, hours float64) WorkPeriod {
+	end := start.Add(time.Duration(hours * float64(time.Hour)))
+	return WorkPeriod{UserID: userID, UserName: name, Role: "waiter", ShiftID: primitive.NewObjectID(), Start: start, End: &end}
+}
+
+// TestBuildOvertimeAndNightHours tests daily and weekly overtime and night hours across midnight
+func TestBuildOvertimeAndNightHours(t *testing.T) {
+	lan := primitive.NewObjectID()
+	from := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC) // Monday
+	to := from.AddDate(0, 0, 7).Add(-time.Nanosecond)
+
+	var periods []WorkPeriod
+	// Monday 10 hours: 2 hours daily overtime
+	periods = append(periods, period(lan, "Lan", from.Add(8*time.Hour), 10))
+	// Tuesday to Saturday 8 hours each: regular hours reach 48 on Saturday
+	for d := 1; d <= 5; d++ {
+		periods = append(periods, period(lan, "Lan", from.AddDate(0, 0, d).Add(8*time.Hour), 8))
+	}
+	// Sunday 20:00 to Monday 02:00, cut at the end of the period: 4 hours, 2 of them at night
+	periods = append(periods, period(lan, "Lan", from.AddDate(0, 0, 6).Add(20*time.Hour), 6))
+
+	rules := DefaultRules()
+	rules.Holidays = nil
+	ts := Build(periods, rules, from, to)
+	if len(ts.Employees) != 1 {
+		t.Fatalf("Expected one employee, got %d", len(ts.Employees))
+	}
+	e := ts.Employees[0]
+	if e.Shifts != 7 || e.TotalHours != 54 || len(e.Days) != 7 {
+		t.Errorf("Expected 7 shifts, 54 hours on 7 days, got %d, %v, %d", e.Shifts, e.TotalHours, len(e.Days))
+	}
+	if e.DailyOvertimeHours != 2 || e.Days[0].OvertimeHours != 2 {
+		t.Errorf("Expected 2 hours daily overtime on Monday, got %v", e.DailyOvertimeHours)
+	}
+	// 8 + 5*8 + 4 = 52 regular hours in the week, 4 beyond 48
+	if e.WeeklyOvertimeHours != 4 || e.OvertimeHours != 6 || e.RegularHours != 48 {
+		t.Errorf("Expected 4 weekly overtime, 6 overtime and 48 regular hours, got %+v", e)
+	}
+	if e.NightHours != 2 {
+		t.Errorf("Expected 2 night hours, got %v", e.NightHours)
+	}
+}
+
+// TestBuildHolidaysAndOpenShifts tests holiday hours, open shifts and separate employees
+func TestBuildHolidaysAndOpenShifts(t *testing.T) {
+	lan, minh := primitive.NewObjectID(), primitive.NewObjectID()
+	from := time.Date(2024, 4, 29, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 3).Add(-time.Nanosecond)
+
+	// 30/4 is a holiday: all 10 hours are holiday hours, none overtime
+	holiday := period(lan, "Lan", time.Date(2024, 4, 30, 8, 0, 0, 0, time.UTC), 10)
+	// An overnight shift from 29/4 into the holiday, 05:00 - 06:00 is night time
+	overnight := period(minh, "Minh", time.Date(2024, 4, 29, 22, 0, 0, 0, time.UTC), 8)
+	open := WorkPeriod{UserID: minh, UserName: "Minh", Role: "cashier", Start: time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)}
+
+	ts := Build([]WorkPeriod{overnight, holiday, open}, DefaultRules(), from, to)
+	if len(ts.Employees) != 2 || ts.Employees[0].UserName != "Lan" {
+		t.Fatalf("Expected Lan and Minh, got %+v", ts.Employees)
+	}
+	lanSheet, minhSheet := ts.Employees[0], ts.Employees[1]
+	if lanSheet.HolidayHours != 10 || lanSheet.OvertimeHours != 0 || lanSheet.RegularHours != 0 {
+		t.Errorf("Expected 10 holiday hours only, got %+v", lanSheet)
+	}
+	if minhSheet.TotalHours != 8 || minhSheet.HolidayHours != 6 || minhSheet.RegularHours != 2 || minhSheet.NightHours != 8 {
+		t.Errorf("Expected 2 regular and 6 holiday hours, all at night, got %+v", minhSheet)
+	}
+	if minhSheet.OpenShifts != 1 || minhSheet.Shifts != 1 || len(minhSheet.Roles) != 2 {
+		t.Errorf("Expected an open cashier shift not counted, got %+v", minhSheet)
+	}
+
+	records := ts.ExportRecords()
+	if len(records) != 3 || records[1][0] != "Lan" || records[1][11] != "10.00" {
+		t.Errorf("Unexpected export %v", records)
+	}
+}
+
+// TestBuildUnpaidBreaks tests that unpaid breaks are left out of the hours worked, paid ones are not
+func TestBuildUnpaidBreaks(t *testing.T) {
+	from := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	at := func(hour, minute int) *time.Time {
+		t := from.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+		return &t
+	}
+
+	// 14:00 to 23:00 with a 30 minute unpaid break, a 15 minute paid break and an unpaid
+	// break across the 22:00 night start
+	shift := &order.Shift{
+		ID: primitive.NewObjectID(), UserID: primitive.NewObjectID(), UserName: "Lan", RoleType: order.RoleBarista,
+		StartedAt: *at(14, 0), EndedAt: at(23, 0),
+		Breaks: []order.ShiftBreak{
+			{Type: order.BreakUnpaid, StartedAt: *at(17, 0), EndedAt: at(17, 30)},
+			{Type: order.BreakPaid, StartedAt: *at(19, 0), EndedAt: at(19, 15)},
+			{Type: order.BreakUnpaid, StartedAt: *at(21, 45), EndedAt: at(22, 15)},
+		},
+	}
+	period := FromShift(shift)
+	if len(period.Breaks) != 2 {
+		t.Fatalf("Expected the two unpaid breaks, got %+v", period.Breaks)
+	}
+
+	rules := DefaultRules()
+	rules.Holidays = nil
+	e := Build([]WorkPeriod{period}, rules, from, to).Employees[0]
+	if e.TotalHours != 8 || e.UnpaidBreakHours != 1 || e.RegularHours != 8 {
+		t.Errorf("Expected 8 hours worked after 1 hour of unpaid breaks, got %+v", e)
+	}
+	if e.NightHours != 0.75 {
+		t.Errorf("Expected 45 night minutes, got %v", e.NightHours)
+	}
+
+	// A break cut by the end of the pay period only counts within it
+	cut := Build([]WorkPeriod{period}, rules, from, *at(17, 15)).Employees[0]
+	if cut.TotalHours != 3 || cut.UnpaidBreakHours != 0.25 {
+		t.Errorf("Expected 3 hours and 15 break minutes before 17:15, got %+v", cut)
+	}
+}
+
+// TestParseHolidays tests holiday configuration parsing
+func TestParseHolidays(t *testing.T) {
+	holidays, err := ParseHolidays("01-01, 2025-01-29,,09-02")
+	if err != nil || len(holidays) != 3 {
+		t.Fatalf("Expected 3 holidays, got %v (%v)", holidays, err)
+	}
+	rules := Rules{Holidays: holidays}
+	if !rules.IsHoliday(time.Date(2025, 1, 29, 12, 0, 0, 0, time.UTC)) || !rules.IsHoliday(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Error("Expected dated and yearly holidays to match")
+	}
+	if rules.IsHoliday(time.Date(2026, 1, 29, 12, 0, 0, 0, time.UTC)) {
+		t.Error("Expected a dated holiday to match only its year")
+	}
+	if _, err := ParseHolidays("30/04"); err == nil {
+		t.Error("Expected an error for an invalid holiday")
+	}
+}
@@ -1,6 +1,7 @@
 package user
 
 import (
+	"errors"
 	"time"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -24,6 +25,42 @@ type User struct {
 	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
 	LastLogin *time.Time         `bson:"last_login,omitempty" json:"last_login,omitempty"`
+	Pay       *PayRate           `bson:"pay,omitempty" json:"pay,omitempty"`
+}
+
+type PayType string
+
+const (
+	PayHourly  PayType = "HOURLY"
+	PayMonthly PayType = "MONTHLY"
+)
+
+// PayRate is what an employee is paid: an hourly rate, or a monthly salary that covers the
+// regular hours of the month
+type PayRate struct {
+	Type          PayType `bson:"type" json:"type"`
+	HourlyRate    float64 `bson:"hourly_rate,omitempty" json:"hourly_rate,omitempty"`
+	MonthlySalary float64 `bson:"monthly_salary,omitempty" json:"monthly_salary,omitempty"`
+}
+
+// Validate checks that the rate matching the pay type is set
+func (p *PayRate) Validate() error {
+	switch p.Type {
+	case PayHourly:
+		if p.HourlyRate <= 0 {
+			return errors.New("hourly_rate must be greater than 0")
+		}
+	case PayMonthly:
+		if p.MonthlySalary <= 0 {
+			return errors.New("monthly_salary must be greater than 0")
+		}
+	default:
+		return errors.New("pay type must be HOURLY or MONTHLY")
+	}
+	if p.HourlyRate < 0 || p.MonthlySalary < 0 {
+		return errors.New("pay rates cannot be negative")
+	}
+	return nil
 }
 
 type LoginRequest struct {
@@ -34,4 +71,10 @@ type LoginRequest struct {
 type LoginResponse struct {
 	Token string `json:"token"`
 	User  User   `json:"user"`
-}
\ No newline at end of file
+}
+
+// OverrideTokenResponse carries a short-lived manager authorisation for order price overrides
+type OverrideTokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
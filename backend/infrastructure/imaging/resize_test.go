@@ -0,0 +1,112 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+func filled(w, h int, c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		name         string
+		w, h         int
+		maxW, maxH   int
+		wantW, wantH int
+	}{
+		{"within bounds keeps size", 200, 100, 1024, 1024, 200, 100},
+		{"wide image limited by width", 2048, 1024, 1024, 1024, 1024, 512},
+		{"tall image limited by height", 1000, 4000, 1024, 1024, 256, 1024},
+		{"limited by both", 3000, 2000, 1500, 500, 750, 500},
+		{"thin line keeps one pixel", 5000, 2, 1000, 1000, 1000, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Fit(filled(tt.w, tt.h, color.Black), tt.maxW, tt.maxH).Bounds()
+			if got.Dx() != tt.wantW || got.Dy() != tt.wantH {
+				t.Errorf("Fit = %dx%d, want %dx%d", got.Dx(), got.Dy(), tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	// Left and right thirds are red, the middle is blue: the square crop keeps only blue
+	img := filled(300, 100, color.RGBA{R: 255, A: 255})
+	for y := 0; y < 100; y++ {
+		for x := 100; x < 200; x++ {
+			img.Set(x, y, color.RGBA{B: 255, A: 255})
+		}
+	}
+
+	thumb := Thumbnail(img, 50)
+	if b := thumb.Bounds(); b.Dx() != 50 || b.Dy() != 50 {
+		t.Fatalf("Thumbnail = %dx%d, want 50x50", b.Dx(), b.Dy())
+	}
+	for _, p := range []image.Point{{0, 0}, {49, 49}, {25, 25}} {
+		if c := thumb.RGBAAt(p.X, p.Y); c != (color.RGBA{B: 255, A: 255}) {
+			t.Errorf("pixel %v = %v, want blue", p, c)
+		}
+	}
+
+	// Smaller images are not scaled up
+	if b := Thumbnail(filled(40, 80, color.Black), 300).Bounds(); b.Dx() != 40 || b.Dy() != 40 {
+		t.Errorf("small Thumbnail = %dx%d, want 40x40", b.Dx(), b.Dy())
+	}
+}
+
+func TestResizeAveragesArea(t *testing.T) {
+	// A black and white checkerboard shrinks to grey
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			if (x+y)%2 == 0 {
+				img.Set(x, y, color.White)
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	got := Fit(img, 2, 2)
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 2; x++ {
+			if c := got.RGBAAt(x, y); c != (color.RGBA{R: 127, G: 127, B: 127, A: 255}) {
+				t.Errorf("pixel (%d,%d) = %v, want grey", x, y, c)
+			}
+		}
+	}
+}
+
+func TestTransparentBecomesWhite(t *testing.T) {
+	got := Fit(image.NewRGBA(image.Rect(0, 0, 10, 10)), 5, 5)
+	if c := got.RGBAAt(2, 2); c != (color.RGBA{R: 255, G: 255, B: 255, A: 255}) {
+		t.Errorf("transparent pixel = %v, want white", c)
+	}
+}
+
+func TestEncodeJPEG(t *testing.T) {
+	data, err := EncodeJPEG(filled(30, 20, color.Black), 85)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Width != 30 || config.Height != 20 {
+		t.Errorf("decoded %dx%d, want 30x20", config.Width, config.Height)
+	}
+}
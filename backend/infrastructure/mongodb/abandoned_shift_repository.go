@@ -0,0 +1,83 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cafe-pos/backend/domain/abandoned"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AbandonedShiftRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAbandonedShiftRepository(db *mongo.Database) *AbandonedShiftRepository {
+	return &AbandonedShiftRepository{
+		collection: db.Collection("abandoned_shifts"),
+	}
+}
+
+func (r *AbandonedShiftRepository) Create(ctx context.Context, rec *abandoned.Record) error {
+	rec.CreatedAt = time.Now()
+	rec.UpdatedAt = time.Now()
+	result, err := r.collection.InsertOne(ctx, rec)
+	if err != nil {
+		return err
+	}
+	rec.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *AbandonedShiftRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*abandoned.Record, error) {
+	var rec abandoned.Record
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&rec)
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// FindByShiftID returns the record of a shift, or nil if it has not been flagged
+func (r *AbandonedShiftRepository) FindByShiftID(ctx context.Context, shiftID primitive.ObjectID) (*abandoned.Record, error) {
+	var rec abandoned.Record
+	err := r.collection.FindOne(ctx, bson.M{"shift_id": shiftID}).Decode(&rec)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func (r *AbandonedShiftRepository) Update(ctx context.Context, id primitive.ObjectID, rec *abandoned.Record) error {
+	rec.UpdatedAt = time.Now()
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": rec})
+	return err
+}
+
+// FindByStatus returns the records with the given status, or all records if status is
+// empty, most recently detected first
+func (r *AbandonedShiftRepository) FindByStatus(ctx context.Context, status abandoned.Status) ([]*abandoned.Record, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "detected_at", Value: -1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	records := []*abandoned.Record{}
+	if err = cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
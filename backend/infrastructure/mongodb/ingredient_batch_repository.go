@@ -0,0 +1,141 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"cafe-pos/backend/domain/ingredient"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IngredientBatchRepository struct {
+	collection *mongo.Collection
+}
+
+func NewIngredientBatchRepository(db *mongo.Database) *IngredientBatchRepository {
+	return &IngredientBatchRepository{
+		collection: db.Collection("ingredient_batches"),
+	}
+}
+
+func (r *IngredientBatchRepository) Create(ctx context.Context, b *ingredient.Batch) error {
+	b.CreatedAt = time.Now()
+	b.UpdatedAt = time.Now()
+	result, err := r.collection.InsertOne(ctx, b)
+	if err != nil {
+		return err
+	}
+	b.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *IngredientBatchRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*ingredient.Batch, error) {
+	var b ingredient.Batch
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&b)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// FindByIngredient returns the batches of an ingredient, newest first; activeOnly skips
+// depleted and written-off batches
+func (r *IngredientBatchRepository) FindByIngredient(ctx context.Context, ingredientID primitive.ObjectID, activeOnly bool) ([]*ingredient.Batch, error) {
+	filter := bson.M{"ingredient_id": ingredientID}
+	if activeOnly {
+		filter["status"] = ingredient.BatchActive
+	}
+	return r.find(ctx, filter, bson.D{{Key: "received_at", Value: -1}})
+}
+
+// FindActiveExpiringBefore returns active batches with an expiry date before the given time, soonest first
+func (r *IngredientBatchRepository) FindActiveExpiringBefore(ctx context.Context, before time.Time) ([]*ingredient.Batch, error) {
+	filter := bson.M{
+		"status":      ingredient.BatchActive,
+		"expiry_date": bson.M{"$lt": before},
+	}
+	return r.find(ctx, filter, bson.D{{Key: "expiry_date", Value: 1}})
+}
+
+func (r *IngredientBatchRepository) Update(ctx context.Context, id primitive.ObjectID, b *ingredient.Batch) error {
+	b.UpdatedAt = time.Now()
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": b})
+	return err
+}
+
+// Draw takes quantity from an active batch and marks it depleted when nothing remains. The
+// decrement only applies while the batch still holds the quantity, so concurrent draws never
+// take more than it has; otherwise it fails with ingredient.ErrBatchNotActive.
+func (r *IngredientBatchRepository) Draw(ctx context.Context, id primitive.ObjectID, quantity float64) error {
+	var b ingredient.Batch
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": id, "status": ingredient.BatchActive, "quantity": bson.M{"$gte": quantity - 1e-9}},
+		bson.M{
+			"$inc": bson.M{"quantity": -quantity},
+			"$set": bson.M{"updated_at": time.Now()},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&b)
+	if err == mongo.ErrNoDocuments {
+		return ingredient.ErrBatchNotActive
+	}
+	if err != nil {
+		return err
+	}
+	if b.Quantity <= 1e-9 {
+		_, err = r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+			"quantity": 0,
+			"status":   ingredient.BatchDepleted,
+		}})
+	}
+	return err
+}
+
+// ConvertUnit converts the active batches of an ingredient stocked in from to unit to, with
+// factor of the new unit in one old unit
+func (r *IngredientBatchRepository) ConvertUnit(ctx context.Context, ingredientID primitive.ObjectID, from, to ingredient.UnitType, factor float64) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"ingredient_id": ingredientID, "status": ingredient.BatchActive, "unit": from},
+		bson.M{
+			"$mul": bson.M{"quantity": factor, "initial_quantity": factor, "unit_cost": 1 / factor},
+			"$set": bson.M{"unit": to, "updated_at": time.Now()},
+		})
+	return err
+}
+
+// WriteOff marks an active batch as written off with nothing remaining. It fails with
+// ingredient.ErrBatchNotActive if the batch is not active, so a batch is written off once.
+func (r *IngredientBatchRepository) WriteOff(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": ingredient.BatchActive},
+		bson.M{"$set": bson.M{
+			"quantity":       0,
+			"status":         ingredient.BatchWrittenOff,
+			"written_off_at": at,
+			"updated_at":     time.Now(),
+		}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ingredient.ErrBatchNotActive
+	}
+	return nil
+}
+
+func (r *IngredientBatchRepository) find(ctx context.Context, filter bson.M, sort bson.D) ([]*ingredient.Batch, error) {
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(sort))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	batches := []*ingredient.Batch{}
+	if err = cursor.All(ctx, &batches); err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
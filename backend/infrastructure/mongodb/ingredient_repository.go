@@ -51,9 +51,22 @@ func (r *IngredientRepository) FindByID(ctx context.Context, id primitive.Object
 	return &item, nil
 }
 
+// Update saves the details of an ingredient. The stock quantity, cost layers and cost per unit
+// are left unchanged: they only change through IncrementStock and UpdateCostLayers, so a
+// concurrent stock movement is never overwritten.
 func (r *IngredientRepository) Update(ctx context.Context, id primitive.ObjectID, item *ingredient.Ingredient) error {
 	item.UpdatedAt = time.Now()
-	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": item})
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"name":        item.Name,
+		"category":    item.Category,
+		"unit":        item.Unit,
+		"min_stock":   item.MinStock,
+		"supplier":    item.Supplier,
+		"supplier_id": item.SupplierID,
+		"conversions": item.Conversions,
+		"recipe":      item.Recipe,
+		"updated_at":  item.UpdatedAt,
+	}})
 	return err
 }
 
@@ -131,3 +144,65 @@ func (r *IngredientRepository) DeleteCategory(ctx context.Context, id primitive.
 	_, err = categoryCollection.DeleteOne(ctx, bson.M{"_id": id})
 	return err
 }
+
+// IncrementStock atomically adds delta to the stock of an ingredient and returns the ingredient
+// as updated. A decrease below zero matches no document and fails with
+// ingredient.ErrInsufficientStock, leaving the stock unchanged.
+func (r *IngredientRepository) IncrementStock(ctx context.Context, id primitive.ObjectID, delta float64) (*ingredient.Ingredient, error) {
+	filter := bson.M{"_id": id}
+	if delta < 0 {
+		filter["quantity"] = bson.M{"$gte": -delta}
+	}
+	update := bson.M{
+		"$inc": bson.M{"quantity": delta},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var item ingredient.Ingredient
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&item)
+	if err == mongo.ErrNoDocuments {
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, ingredient.ErrInsufficientStock
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// ChangeUnit moves an ingredient from unit from to the unit of item, multiplying its stock and
+// minimum stock by factor in the update so concurrent stock movements are kept. The cost
+// layers and cost per unit are set from item. It fails with ingredient.ErrUnitChanged if the
+// ingredient is no longer stocked in from.
+func (r *IngredientRepository) ChangeUnit(ctx context.Context, id primitive.ObjectID, from ingredient.UnitType, factor float64, item *ingredient.Ingredient) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "unit": from}, bson.M{
+		"$mul": bson.M{"quantity": factor, "min_stock": factor},
+		"$set": bson.M{
+			"unit":          item.Unit,
+			"cost_layers":   item.CostLayers,
+			"cost_per_unit": item.CostPerUnit,
+			"updated_at":    time.Now(),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ingredient.ErrUnitChanged
+	}
+	return nil
+}
+
+// UpdateCostLayers sets the cost layers and cost per unit of an ingredient without touching
+// its stock quantity
+func (r *IngredientRepository) UpdateCostLayers(ctx context.Context, id primitive.ObjectID, layers []ingredient.CostLayer, costPerUnit float64) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"cost_layers":   layers,
+		"cost_per_unit": costPerUnit,
+		"updated_at":    time.Now(),
+	}})
+	return err
+}
@@ -0,0 +1,68 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"cafe-pos/backend/domain/menu"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MenuCategoryRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMenuCategoryRepository(db *mongo.Database) *MenuCategoryRepository {
+	return &MenuCategoryRepository{
+		collection: db.Collection("menu_categories"),
+	}
+}
+
+func (r *MenuCategoryRepository) Create(ctx context.Context, cat *menu.Category) error {
+	cat.CreatedAt = time.Now()
+	cat.UpdatedAt = time.Now()
+	result, err := r.collection.InsertOne(ctx, cat)
+	if err != nil {
+		return err
+	}
+	cat.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *MenuCategoryRepository) FindAll(ctx context.Context) ([]*menu.Category, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "sort_order", Value: 1}, {Key: "name", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var categories []*menu.Category
+	if err = cursor.All(ctx, &categories); err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (r *MenuCategoryRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*menu.Category, error) {
+	var cat menu.Category
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&cat)
+	if err != nil {
+		return nil, err
+	}
+	return &cat, nil
+}
+
+func (r *MenuCategoryRepository) Update(ctx context.Context, id primitive.ObjectID, cat *menu.Category) error {
+	cat.UpdatedAt = time.Now()
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": cat})
+	return err
+}
+
+func (r *MenuCategoryRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
@@ -0,0 +1,69 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"cafe-pos/backend/domain/menu"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MenuPriceRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMenuPriceRepository(db *mongo.Database) *MenuPriceRepository {
+	return &MenuPriceRepository{
+		collection: db.Collection("menu_prices"),
+	}
+}
+
+func (r *MenuPriceRepository) Create(ctx context.Context, e *menu.PriceEntry) error {
+	e.CreatedAt = time.Now()
+	result, err := r.collection.InsertOne(ctx, e)
+	if err != nil {
+		return err
+	}
+	e.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// FindByMenuItemID returns the price entries of a menu item, oldest effective date first
+func (r *MenuPriceRepository) FindByMenuItemID(ctx context.Context, menuItemID primitive.ObjectID) ([]*menu.PriceEntry, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "effective_from", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"menu_item_id": menuItemID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*menu.PriceEntry
+	if err = cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// FindEffectiveBefore returns all entries that have taken effect by the given time
+func (r *MenuPriceRepository) FindEffectiveBefore(ctx context.Context, at time.Time) ([]*menu.PriceEntry, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "effective_from", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"effective_from": bson.M{"$lte": at}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*menu.PriceEntry
+	if err = cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *MenuPriceRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
@@ -23,8 +23,12 @@ func NewMenuRepository(db *mongo.Database) *MenuRepository {
 func (r *MenuRepository) Create(ctx context.Context, item *menu.MenuItem) error {
 	item.CreatedAt = time.Now()
 	item.UpdatedAt = time.Now()
-	_, err := r.collection.InsertOne(ctx, item)
-	return err
+	result, err := r.collection.InsertOne(ctx, item)
+	if err != nil {
+		return err
+	}
+	item.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
 }
 
 func (r *MenuRepository) FindAll(ctx context.Context) ([]*menu.MenuItem, error) {
@@ -57,6 +61,34 @@ func (r *MenuRepository) Update(ctx context.Context, id primitive.ObjectID, item
 	return err
 }
 
+// SetCosting saves the cost snapshot of an item without overwriting the rest of it
+func (r *MenuRepository) SetCosting(ctx context.Context, id primitive.ObjectID, snapshot *menu.CostSnapshot) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"costing": snapshot}})
+	return err
+}
+
+// SetAvailability saves whether an item is available and was switched off automatically,
+// leaving the rest of the item unchanged
+func (r *MenuRepository) SetAvailability(ctx context.Context, id primitive.ObjectID, available, autoDisabled bool) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"available":     available,
+		"auto_disabled": autoDisabled,
+		"updated_at":    time.Now(),
+	}})
+	return err
+}
+
+// SetImage saves the photo URLs and storage keys of an item, leaving the rest of the item unchanged
+func (r *MenuRepository) SetImage(ctx context.Context, id primitive.ObjectID, imageURL, thumbnailURL string, keys []string) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"image_url":     imageURL,
+		"thumbnail_url": thumbnailURL,
+		"image_keys":    keys,
+		"updated_at":    time.Now(),
+	}})
+	return err
+}
+
 func (r *MenuRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
 	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
 	return err
@@ -0,0 +1,68 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"cafe-pos/backend/domain/menu"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MenuScheduleRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMenuScheduleRepository(db *mongo.Database) *MenuScheduleRepository {
+	return &MenuScheduleRepository{
+		collection: db.Collection("menu_schedules"),
+	}
+}
+
+func (r *MenuScheduleRepository) Create(ctx context.Context, s *menu.MenuSchedule) error {
+	s.CreatedAt = time.Now()
+	s.UpdatedAt = time.Now()
+	result, err := r.collection.InsertOne(ctx, s)
+	if err != nil {
+		return err
+	}
+	s.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *MenuScheduleRepository) FindAll(ctx context.Context) ([]*menu.MenuSchedule, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "start_time", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var schedules []*menu.MenuSchedule
+	if err = cursor.All(ctx, &schedules); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+func (r *MenuScheduleRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*menu.MenuSchedule, error) {
+	var s menu.MenuSchedule
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&s)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *MenuScheduleRepository) Update(ctx context.Context, id primitive.ObjectID, s *menu.MenuSchedule) error {
+	s.UpdatedAt = time.Now()
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": s})
+	return err
+}
+
+func (r *MenuScheduleRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
@@ -15,8 +15,16 @@ type OrderRepository struct {
 }
 
 func NewOrderRepository(db *mongo.Database) *OrderRepository {
+	collection := db.Collection("orders")
+
+	// A manager override token authorises the prices of one order only
+	collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "override_token_ids", Value: 1}},
+		Options: options.Index().SetUnique(true).SetSparse(true),
+	})
+
 	return &OrderRepository{
-		collection: db.Collection("orders"),
+		collection: collection,
 	}
 }
 
@@ -24,6 +32,9 @@ func (r *OrderRepository) Create(ctx context.Context, o *order.Order) error {
 	o.CreatedAt = time.Now()
 	o.UpdatedAt = time.Now()
 	result, err := r.collection.InsertOne(ctx, o)
+	if mongo.IsDuplicateKeyError(err) {
+		return order.ErrOverrideTokenUsed
+	}
 	if err != nil {
 		return err
 	}
@@ -43,9 +54,18 @@ func (r *OrderRepository) FindByID(ctx context.Context, id primitive.ObjectID) (
 func (r *OrderRepository) Update(ctx context.Context, id primitive.ObjectID, o *order.Order) error {
 	o.UpdatedAt = time.Now()
 	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": o})
+	if mongo.IsDuplicateKeyError(err) {
+		return order.ErrOverrideTokenUsed
+	}
 	return err
 }
 
+// OverrideTokenUsed reports whether an order other than exceptID used the override token
+func (r *OrderRepository) OverrideTokenUsed(ctx context.Context, tokenID string, exceptID primitive.ObjectID) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"override_token_ids": tokenID, "_id": bson.M{"$ne": exceptID}})
+	return count > 0, err
+}
+
 func (r *OrderRepository) FindByShiftID(ctx context.Context, shiftID primitive.ObjectID) ([]*order.Order, error) {
 	opts := options.Find().SetSort(bson.D{{"created_at", -1}})
 	cursor, err := r.collection.Find(ctx, bson.M{"shift_id": shiftID}, opts)
@@ -114,3 +134,23 @@ func (r *OrderRepository) FindByOrderNumber(ctx context.Context, orderNumber str
 	}
 	return &o, nil
 }
+
+func (r *OrderRepository) FindByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*order.Order, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := r.collection.Find(ctx, bson.M{
+		"created_at": bson.M{
+			"$gte": startDate,
+			"$lte": endDate,
+		},
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var orders []*order.Order
+	if err = cursor.All(ctx, &orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
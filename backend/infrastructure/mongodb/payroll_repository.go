@@ -0,0 +1,123 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"cafe-pos/backend/domain/payroll"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PayrollRepository struct {
+	collection *mongo.Collection
+}
+
+func NewPayrollRepository(db *mongo.Database) *PayrollRepository {
+	return &PayrollRepository{
+		collection: db.Collection("payroll_runs"),
+	}
+}
+
+func (r *PayrollRepository) Create(ctx context.Context, run *payroll.PayrollRun) error {
+	run.CreatedAt = time.Now()
+	run.UpdatedAt = time.Now()
+	result, err := r.collection.InsertOne(ctx, run)
+	if err != nil {
+		return err
+	}
+	run.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *PayrollRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*payroll.PayrollRun, error) {
+	var run payroll.PayrollRun
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&run)
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (r *PayrollRepository) Update(ctx context.Context, id primitive.ObjectID, run *payroll.PayrollRun) error {
+	run.UpdatedAt = time.Now()
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": run})
+	return err
+}
+
+// MarkPosted moves a draft run to posted. It fails with payroll.ErrRunNotDraft if the run
+// is no longer a draft, so a run is posted only once.
+func (r *PayrollRepository) MarkPosted(ctx context.Context, id primitive.ObjectID, postedBy string, postedAt time.Time) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": payroll.RunDraft},
+		bson.M{"$set": bson.M{
+			"status":     payroll.RunPosted,
+			"posted_by":  postedBy,
+			"posted_at":  postedAt,
+			"updated_at": time.Now(),
+		}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return payroll.ErrRunNotDraft
+	}
+	return nil
+}
+
+// ReopenDraft moves a posted run back to draft when its expense could not be recorded
+func (r *PayrollRepository) ReopenDraft(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": payroll.RunPosted},
+		bson.M{
+			"$set":   bson.M{"status": payroll.RunDraft, "updated_at": time.Now()},
+			"$unset": bson.M{"posted_by": "", "posted_at": ""},
+		},
+	)
+	return err
+}
+
+// SetExpenseID links a posted run to its salary expense
+func (r *PayrollRepository) SetExpenseID(ctx context.Context, id, expenseID primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"expense_id": expenseID,
+		"updated_at": time.Now(),
+	}})
+	return err
+}
+
+func (r *PayrollRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// FindAll returns the payroll runs, latest period first
+func (r *PayrollRepository) FindAll(ctx context.Context) ([]*payroll.PayrollRun, error) {
+	return r.find(ctx, bson.M{})
+}
+
+// FindPostedByUser returns the posted runs with a payslip for the user, latest period first
+func (r *PayrollRepository) FindPostedByUser(ctx context.Context, userID primitive.ObjectID) ([]*payroll.PayrollRun, error) {
+	return r.find(ctx, bson.M{
+		"status":           payroll.RunPosted,
+		"payslips.user_id": userID,
+	})
+}
+
+func (r *PayrollRepository) find(ctx context.Context, filter bson.M) ([]*payroll.PayrollRun, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "from", Value: -1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	runs := []*payroll.PayrollRun{}
+	if err = cursor.All(ctx, &runs); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
@@ -0,0 +1,85 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"cafe-pos/backend/domain/roster"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PlannedShiftRepository struct {
+	collection *mongo.Collection
+}
+
+func NewPlannedShiftRepository(db *mongo.Database) *PlannedShiftRepository {
+	return &PlannedShiftRepository{
+		collection: db.Collection("planned_shifts"),
+	}
+}
+
+func (r *PlannedShiftRepository) Create(ctx context.Context, p *roster.PlannedShift) error {
+	p.CreatedAt = time.Now()
+	p.UpdatedAt = time.Now()
+	result, err := r.collection.InsertOne(ctx, p)
+	if err != nil {
+		return err
+	}
+	p.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *PlannedShiftRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*roster.PlannedShift, error) {
+	var p roster.PlannedShift
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&p)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *PlannedShiftRepository) Update(ctx context.Context, id primitive.ObjectID, p *roster.PlannedShift) error {
+	p.UpdatedAt = time.Now()
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": p})
+	return err
+}
+
+func (r *PlannedShiftRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// FindByDateRange returns the planned shifts overlapping [from, to], earliest first
+func (r *PlannedShiftRepository) FindByDateRange(ctx context.Context, from, to time.Time) ([]*roster.PlannedShift, error) {
+	return r.find(ctx, bson.M{
+		"starts_at": bson.M{"$lte": to},
+		"ends_at":   bson.M{"$gt": from},
+	})
+}
+
+// FindByUser returns the planned shifts of a staff member overlapping [from, to], earliest first
+func (r *PlannedShiftRepository) FindByUser(ctx context.Context, userID primitive.ObjectID, from, to time.Time) ([]*roster.PlannedShift, error) {
+	return r.find(ctx, bson.M{
+		"user_id":   userID,
+		"starts_at": bson.M{"$lte": to},
+		"ends_at":   bson.M{"$gt": from},
+	})
+}
+
+func (r *PlannedShiftRepository) find(ctx context.Context, filter bson.M) ([]*roster.PlannedShift, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "starts_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	planned := []*roster.PlannedShift{}
+	if err = cursor.All(ctx, &planned); err != nil {
+		return nil, err
+	}
+	return planned, nil
+}
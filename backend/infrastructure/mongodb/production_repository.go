@@ -0,0 +1,49 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"cafe-pos/backend/domain/ingredient"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ProductionRepository struct {
+	collection *mongo.Collection
+}
+
+func NewProductionRepository(db *mongo.Database) *ProductionRepository {
+	return &ProductionRepository{
+		collection: db.Collection("productions"),
+	}
+}
+
+func (r *ProductionRepository) Create(ctx context.Context, p *ingredient.Production) error {
+	p.CreatedAt = time.Now()
+	p.UpdatedAt = time.Now()
+	result, err := r.collection.InsertOne(ctx, p)
+	if err != nil {
+		return err
+	}
+	p.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// FindByIngredient returns the production runs of a prepared item, newest first
+func (r *ProductionRepository) FindByIngredient(ctx context.Context, ingredientID primitive.ObjectID) ([]*ingredient.Production, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"ingredient_id": ingredientID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	productions := []*ingredient.Production{}
+	if err = cursor.All(ctx, &productions); err != nil {
+		return nil, err
+	}
+	return productions, nil
+}
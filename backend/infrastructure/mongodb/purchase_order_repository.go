@@ -0,0 +1,91 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"cafe-pos/backend/domain/purchasing"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PurchaseOrderRepository struct {
+	collection *mongo.Collection
+}
+
+func NewPurchaseOrderRepository(db *mongo.Database) *PurchaseOrderRepository {
+	return &PurchaseOrderRepository{
+		collection: db.Collection("purchase_orders"),
+	}
+}
+
+func (r *PurchaseOrderRepository) Create(ctx context.Context, po *purchasing.PurchaseOrder) error {
+	po.CreatedAt = time.Now()
+	po.UpdatedAt = time.Now()
+	result, err := r.collection.InsertOne(ctx, po)
+	if err != nil {
+		return err
+	}
+	po.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// FindAll returns purchase orders newest first, optionally filtered by status
+func (r *PurchaseOrderRepository) FindAll(ctx context.Context, status purchasing.Status) ([]*purchasing.PurchaseOrder, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var orders []*purchasing.PurchaseOrder
+	if err = cursor.All(ctx, &orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (r *PurchaseOrderRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*purchasing.PurchaseOrder, error) {
+	var po purchasing.PurchaseOrder
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&po)
+	if err != nil {
+		return nil, err
+	}
+	return &po, nil
+}
+
+// Update saves a purchase order if it is still at the version it was read at, and increments
+// its version. It fails with purchasing.ErrConcurrentUpdate if the order was changed since.
+func (r *PurchaseOrderRepository) Update(ctx context.Context, id primitive.ObjectID, po *purchasing.PurchaseOrder) error {
+	filter := bson.M{"_id": id, "version": po.Version}
+	if po.Version == 0 {
+		// Orders saved before versioning have no version field
+		filter = bson.M{"_id": id, "$or": []bson.M{{"version": 0}, {"version": bson.M{"$exists": false}}}}
+	}
+
+	updated := *po
+	updated.UpdatedAt = time.Now()
+	updated.Version = po.Version + 1
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": &updated})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return purchasing.ErrConcurrentUpdate
+	}
+	po.UpdatedAt = updated.UpdatedAt
+	po.Version = updated.Version
+	return nil
+}
+
+func (r *PurchaseOrderRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
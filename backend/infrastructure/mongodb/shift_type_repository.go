@@ -0,0 +1,69 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"cafe-pos/backend/domain/shifttype"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ShiftTypeRepository struct {
+	collection *mongo.Collection
+}
+
+func NewShiftTypeRepository(db *mongo.Database) *ShiftTypeRepository {
+	return &ShiftTypeRepository{
+		collection: db.Collection("shift_types"),
+	}
+}
+
+func (r *ShiftTypeRepository) Create(ctx context.Context, d *shifttype.Definition) error {
+	d.CreatedAt = time.Now()
+	d.UpdatedAt = time.Now()
+	result, err := r.collection.InsertOne(ctx, d)
+	if err != nil {
+		return err
+	}
+	d.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *ShiftTypeRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*shifttype.Definition, error) {
+	var d shifttype.Definition
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&d)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *ShiftTypeRepository) Update(ctx context.Context, id primitive.ObjectID, d *shifttype.Definition) error {
+	d.UpdatedAt = time.Now()
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": d})
+	return err
+}
+
+func (r *ShiftTypeRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// FindAll returns the definitions of every store, ordered by store and start time
+func (r *ShiftTypeRepository) FindAll(ctx context.Context) ([]*shifttype.Definition, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "store", Value: 1}, {Key: "start_time", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	defs := []*shifttype.Definition{}
+	if err = cursor.All(ctx, &defs); err != nil {
+		return nil, err
+	}
+	return defs, nil
+}
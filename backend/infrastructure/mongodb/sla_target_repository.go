@@ -0,0 +1,64 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"cafe-pos/backend/domain/order"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SLATargetRepository struct {
+	collection *mongo.Collection
+}
+
+func NewSLATargetRepository(db *mongo.Database) *SLATargetRepository {
+	return &SLATargetRepository{
+		collection: db.Collection("sla_targets"),
+	}
+}
+
+// Upsert creates or replaces the target for the target's category
+func (r *SLATargetRepository) Upsert(ctx context.Context, t *order.SLATarget) error {
+	now := time.Now()
+	t.UpdatedAt = now
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"category": t.Category},
+		bson.M{
+			"$set": bson.M{
+				"max_wait_minutes":  t.MaxWaitMinutes,
+				"max_prep_minutes":  t.MaxPrepMinutes,
+				"max_serve_minutes": t.MaxServeMinutes,
+				"updated_at":        now,
+			},
+			"$setOnInsert": bson.M{"created_at": now},
+		},
+		opts,
+	).Decode(t)
+	return err
+}
+
+func (r *SLATargetRepository) FindAll(ctx context.Context) ([]*order.SLATarget, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "category", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var targets []*order.SLATarget
+	if err = cursor.All(ctx, &targets); err != nil {
+		return nil, err
+	}
+	return targets, nil
+}
+
+func (r *SLATargetRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
@@ -39,4 +39,21 @@ func (r *StockHistoryRepository) FindByIngredientID(ctx context.Context, ingredi
 		return nil, err
 	}
 	return histories, nil
-}
\ No newline at end of file
+}
+
+// FindByPeriod returns all stock history created within the period, oldest first
+func (r *StockHistoryRepository) FindByPeriod(ctx context.Context, from, to time.Time) ([]*ingredient.StockHistory, error) {
+	filter := bson.M{"created_at": bson.M{"$gte": from, "$lte": to}}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	histories := []*ingredient.StockHistory{}
+	if err = cursor.All(ctx, &histories); err != nil {
+		return nil, err
+	}
+	return histories, nil
+}
@@ -0,0 +1,68 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"cafe-pos/backend/domain/stocktake"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type StocktakeRepository struct {
+	collection *mongo.Collection
+}
+
+func NewStocktakeRepository(db *mongo.Database) *StocktakeRepository {
+	return &StocktakeRepository{
+		collection: db.Collection("stocktakes"),
+	}
+}
+
+func (r *StocktakeRepository) Create(ctx context.Context, st *stocktake.Stocktake) error {
+	st.CreatedAt = time.Now()
+	st.UpdatedAt = time.Now()
+	result, err := r.collection.InsertOne(ctx, st)
+	if err != nil {
+		return err
+	}
+	st.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// FindAll returns stocktakes newest first, optionally filtered by status
+func (r *StocktakeRepository) FindAll(ctx context.Context, status stocktake.Status) ([]*stocktake.Stocktake, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "opened_at", Value: -1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	stocktakes := []*stocktake.Stocktake{}
+	if err = cursor.All(ctx, &stocktakes); err != nil {
+		return nil, err
+	}
+	return stocktakes, nil
+}
+
+func (r *StocktakeRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*stocktake.Stocktake, error) {
+	var st stocktake.Stocktake
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&st)
+	if err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+func (r *StocktakeRepository) Update(ctx context.Context, id primitive.ObjectID, st *stocktake.Stocktake) error {
+	st.UpdatedAt = time.Now()
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": st})
+	return err
+}
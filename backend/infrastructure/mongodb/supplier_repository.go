@@ -0,0 +1,113 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"cafe-pos/backend/domain/supplier"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SupplierRepository struct {
+	collection *mongo.Collection
+	prices     *mongo.Collection
+}
+
+func NewSupplierRepository(db *mongo.Database) *SupplierRepository {
+	return &SupplierRepository{
+		collection: db.Collection("suppliers"),
+		prices:     db.Collection("supplier_prices"),
+	}
+}
+
+func (r *SupplierRepository) Create(ctx context.Context, s *supplier.Supplier) error {
+	s.CreatedAt = time.Now()
+	s.UpdatedAt = time.Now()
+	result, err := r.collection.InsertOne(ctx, s)
+	if err != nil {
+		return err
+	}
+	s.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *SupplierRepository) FindAll(ctx context.Context) ([]*supplier.Supplier, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var suppliers []*supplier.Supplier
+	if err = cursor.All(ctx, &suppliers); err != nil {
+		return nil, err
+	}
+	return suppliers, nil
+}
+
+func (r *SupplierRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*supplier.Supplier, error) {
+	var s supplier.Supplier
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&s)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *SupplierRepository) Update(ctx context.Context, id primitive.ObjectID, s *supplier.Supplier) error {
+	s.UpdatedAt = time.Now()
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": s})
+	return err
+}
+
+func (r *SupplierRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	if _, err := r.prices.DeleteMany(ctx, bson.M{"supplier_id": id}); err != nil {
+		return err
+	}
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+func (r *SupplierRepository) CreatePrice(ctx context.Context, p *supplier.PriceListEntry) error {
+	p.CreatedAt = time.Now()
+	p.UpdatedAt = time.Now()
+	result, err := r.prices.InsertOne(ctx, p)
+	if err != nil {
+		return err
+	}
+	p.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// FindPrices returns price list entries filtered by supplier and/or ingredient (nil IDs are ignored),
+// newest first
+func (r *SupplierRepository) FindPrices(ctx context.Context, supplierID, ingredientID primitive.ObjectID) ([]*supplier.PriceListEntry, error) {
+	filter := bson.M{}
+	if !supplierID.IsZero() {
+		filter["supplier_id"] = supplierID
+	}
+	if !ingredientID.IsZero() {
+		filter["ingredient_id"] = ingredientID
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "valid_from", Value: -1}})
+	cursor, err := r.prices.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*supplier.PriceListEntry
+	if err = cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *SupplierRepository) DeletePrice(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.prices.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
@@ -0,0 +1,67 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync/atomic"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// TransactionRunner runs multi-document writes in a MongoDB transaction. Transactions need a
+// replica set (the docker compose files run a single-node one). On a standalone server the
+// writes run one by one without a transaction, so a failure part way leaves the earlier
+// writes in place.
+type TransactionRunner struct {
+	client      *mongo.Client
+	unsupported atomic.Bool
+}
+
+func NewTransactionRunner(db *mongo.Database) *TransactionRunner {
+	return &TransactionRunner{client: db.Client()}
+}
+
+// Supported reports whether the server is a replica set member and can run transactions
+func (t *TransactionRunner) Supported(ctx context.Context) bool {
+	var hello struct {
+		SetName string `bson:"setName"`
+	}
+	err := t.client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	return err == nil && hello.SetName != ""
+}
+
+// Run calls fn in a transaction, committing if it returns nil. Repository calls made with the
+// context passed to fn take part in the transaction. fn may be called again when the
+// transaction is retried after a write conflict, so it must not depend on state from an
+// earlier attempt.
+func (t *TransactionRunner) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	if t.unsupported.Load() {
+		return fn(ctx)
+	}
+
+	session, err := t.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	if transactionsUnsupported(err) {
+		t.unsupported.Store(true)
+		log.Println("⚠️ [MongoDB] Transactions are not supported by this server (not a replica set), stock changes are written WITHOUT transactions")
+		return fn(ctx)
+	}
+	return err
+}
+
+// transactionsUnsupported reports whether the server rejected the transaction because it is
+// a standalone server. The first write of the transaction fails, so nothing was written.
+func transactionsUnsupported(err error) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorCode(20) && strings.Contains(err.Error(), "Transaction numbers")
+}
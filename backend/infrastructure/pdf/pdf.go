@@ -0,0 +1,136 @@
+// Package pdf writes simple text documents as PDF using the built-in Courier font.
+// It is meant for printable business documents (purchase orders, reports) where a
+// fixed-width layout is enough. The standard fonts only cover Latin-1, so Vietnamese
+// letters are written without their diacritics.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	pageWidth  = 595 // A4 in points
+	pageHeight = 842
+	margin     = 40
+	fontSize   = 10
+	lineHeight = 13
+)
+
+// LinesPerPage is the number of text lines that fit on one page
+const LinesPerPage = (pageHeight - 2*margin) / lineHeight
+
+// CharsPerLine is the number of Courier characters that fit on one line
+const CharsPerLine = (pageWidth - 2*margin) * 10 / (fontSize * 6)
+
+// WriteText writes lines of text as an A4 PDF, starting a new page when a page is full
+// or at a form feed line ("\f")
+func WriteText(w io.Writer, title string, lines []string) error {
+	var pages [][]string
+	var current []string
+	for _, line := range lines {
+		if line == "\f" || len(current) == LinesPerPage {
+			pages = append(pages, current)
+			current = nil
+			if line == "\f" {
+				continue
+			}
+		}
+		current = append(current, line)
+	}
+	pages = append(pages, current)
+
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// Objects 1-4: catalog, page tree, font, info. Pages and their content streams follow.
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	object(fmt.Sprintf("<< /Title (%s) /Producer (Cafe POS) >>", escape(title)))
+
+	for i, page := range pages {
+		var content bytes.Buffer
+		fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", fontSize, lineHeight, margin, pageHeight-margin-fontSize)
+		for _, line := range page {
+			fmt.Fprintf(&content, "(%s) '\n", escape(line))
+		}
+		content.WriteString("ET")
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] "+
+			"/Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", pageWidth, pageHeight, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 4 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// vietnameseFolds maps each base letter to its Vietnamese accented forms
+var vietnameseFolds = map[rune]string{
+	'a': "àáảãạăằắẳẵặâầấẩẫậ",
+	'A': "ÀÁẢÃẠĂẰẮẲẴẶÂẦẤẨẪẬ",
+	'e': "èéẻẽẹêềếểễệ",
+	'E': "ÈÉẺẼẸÊỀẾỂỄỆ",
+	'i': "ìíỉĩị",
+	'I': "ÌÍỈĨỊ",
+	'o': "òóỏõọôồốổỗộơờớởỡợ",
+	'O': "ÒÓỎÕỌÔỒỐỔỖỘƠỜỚỞỠỢ",
+	'u': "ùúủũụưừứửữự",
+	'U': "ÙÚỦŨỤƯỪỨỬỮỰ",
+	'y': "ỳýỷỹỵ",
+	'Y': "ỲÝỶỸỴ",
+	'd': "đ",
+	'D': "Đ",
+}
+
+var foldTable = func() map[rune]rune {
+	table := make(map[rune]rune)
+	for base, forms := range vietnameseFolds {
+		for _, r := range forms {
+			table[r] = base
+		}
+	}
+	return table
+}()
+
+// escape converts text to single-byte WinAnsi and escapes PDF string delimiters
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if base, ok := foldTable[r]; ok {
+			r = base
+		}
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,195 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Config configures an S3-compatible object store (AWS S3, MinIO, Cloudflare R2, ...)
+type S3Config struct {
+	Endpoint  string // e.g. https://s3.ap-southeast-1.amazonaws.com
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PublicURL string // optional base URL for public links, defaults to Endpoint/Bucket
+}
+
+// S3Storage stores files in an S3-compatible bucket using path-style requests
+// signed with AWS Signature Version 4
+type S3Storage struct {
+	config S3Config
+	client *http.Client
+}
+
+func NewS3Storage(config S3Config) (*S3Storage, error) {
+	if config.Endpoint == "" || config.Bucket == "" || config.AccessKey == "" || config.SecretKey == "" {
+		return nil, fmt.Errorf("s3 storage requires endpoint, bucket, access key and secret key")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	config.Endpoint = strings.TrimRight(config.Endpoint, "/")
+	if config.PublicURL == "" {
+		config.PublicURL = config.Endpoint + "/" + config.Bucket
+	}
+	config.PublicURL = strings.TrimRight(config.PublicURL, "/")
+
+	return &S3Storage{
+		config: config,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (s *S3Storage) Put(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	if key == "" {
+		return "", ErrInvalidKey
+	}
+	req, err := s.newRequest(ctx, http.MethodPut, key, data, contentType)
+	if err != nil {
+		return "", err
+	}
+	if err := s.do(req); err != nil {
+		return "", err
+	}
+	return s.config.PublicURL + "/" + escapePath(key), nil
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	if key == "" {
+		return ErrInvalidKey
+	}
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil, "")
+	if err != nil {
+		return err
+	}
+	return s.do(req)
+}
+
+func (s *S3Storage) do(req *http.Request) error {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("s3 %s %s failed: %s %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func (s *S3Storage) newRequest(ctx context.Context, method, key string, body []byte, contentType string) (*http.Request, error) {
+	endpoint, err := url.Parse(s.config.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	canonicalURI := "/" + escapePath(s.config.Bucket) + "/" + escapePath(key)
+	endpoint.Path = ""
+	endpoint.RawPath = ""
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint.String()+canonicalURI, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	s.sign(req, canonicalURI, body, time.Now().UTC())
+	return req, nil
+}
+
+// sign adds AWS Signature Version 4 headers to the request
+func (s *S3Storage) sign(req *http.Request, canonicalURI string, body []byte, now time.Time) {
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           req.Header.Get("X-Amz-Date"),
+	}
+	if ct := req.Header.Get("Content-Type"); ct != "" {
+		headers["content-type"] = ct
+	}
+
+	scope, signedHeaders, signature := signV4(s.config.SecretKey, s.config.Region, req.Method, canonicalURI, "", headers, now)
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKey, scope, signedHeaders, signature))
+}
+
+// signV4 returns the credential scope, signed header list and signature of a request. Header
+// names must be lower case and include x-amz-content-sha256, the hash of the payload.
+func signV4(secretKey, region, method, canonicalURI, canonicalQuery string, headers map[string]string, now time.Time) (string, string, string) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		canonicalURI,
+		canonicalQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		headers["x-amz-content-sha256"],
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return scope, signedHeaders, hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// escapePath URI-encodes each segment of a key as required by SigV4 for S3
+func escapePath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, seg := range segments {
+		segments[i] = strings.ReplaceAll(url.PathEscape(seg), "+", "%2B")
+	}
+	return strings.Join(segments, "/")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,6 @@
+package storage
+
+import "errors"
+
+// ErrInvalidKey is returned for empty keys or keys outside the storage root
+var ErrInvalidKey = errors.New("invalid storage key")
@@ -0,0 +1,309 @@
+// Package xlsx reads and writes single-sheet Office Open XML spreadsheets.
+// It supports plain cell values only (no styles, formulas or dates), which is
+// enough for data import and export.
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Sheet size limits of Excel. Read rejects row and column references beyond them, so a
+// crafted file cannot make it allocate without bound.
+const (
+	MaxRows    = 1048576
+	MaxColumns = 16384
+)
+
+// Write writes rows as the only sheet of an .xlsx workbook. All cells are written as text.
+func Write(w io.Writer, sheetName string, rows [][]string) error {
+	zw := zip.NewWriter(w)
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", contentTypesXML},
+		{"_rels/.rels", rootRelsXML},
+		{"xl/workbook.xml", fmt.Sprintf(workbookXML, escape(sheetName))},
+		{"xl/_rels/workbook.xml.rels", workbookRelsXML},
+	}
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, f.content); err != nil {
+			return err
+		}
+	}
+
+	fw, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	if err := writeSheet(fw, rows); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func writeSheet(w io.Writer, rows [][]string) error {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for r, row := range rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for c, value := range row {
+			if value == "" {
+				continue
+			}
+			fmt.Fprintf(&b, `<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`,
+				columnName(c), r+1, escape(value))
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Read returns the cell values of the first sheet of an .xlsx workbook.
+// Rows are padded so every cell keeps its column position.
+func Read(r io.ReaderAt, size int64) ([][]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.New("not a valid xlsx file")
+	}
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if shared, err = readSharedStrings(f); err != nil {
+			return nil, err
+		}
+	}
+
+	f, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("sheet %s not found in xlsx file", sheetPath)
+	}
+	var sheet struct {
+		Rows []struct {
+			R     int `xml:"r,attr"`
+			Cells []struct {
+				Ref    string   `xml:"r,attr"`
+				Type   string   `xml:"t,attr"`
+				Value  string   `xml:"v"`
+				Inline richText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := decodeXML(f, &sheet); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for i, row := range sheet.Rows {
+		rowNum := row.R
+		if rowNum == 0 {
+			rowNum = i + 1
+		}
+		if rowNum < 1 || rowNum > MaxRows {
+			return nil, fmt.Errorf("invalid row number %d", row.R)
+		}
+		for len(rows) < rowNum {
+			rows = append(rows, nil)
+		}
+
+		var values []string
+		for j, c := range row.Cells {
+			col := j
+			if c.Ref != "" {
+				if col, err = columnIndex(c.Ref); err != nil {
+					return nil, err
+				}
+			}
+			if col >= MaxColumns {
+				return nil, fmt.Errorf("too many columns in row %d", rowNum)
+			}
+			for len(values) <= col {
+				values = append(values, "")
+			}
+
+			switch c.Type {
+			case "s":
+				idx, err := strconv.Atoi(c.Value)
+				if err != nil || idx < 0 || idx >= len(shared) {
+					return nil, fmt.Errorf("invalid shared string reference in cell %s", c.Ref)
+				}
+				values[col] = shared[idx]
+			case "inlineStr":
+				values[col] = c.Inline.String()
+			case "b":
+				values[col] = map[string]string{"1": "true", "0": "false"}[c.Value]
+			default:
+				values[col] = c.Value
+			}
+		}
+		rows[rowNum-1] = values
+	}
+	return rows, nil
+}
+
+// richText is a string item made of a plain <t> or several <r><t> runs
+type richText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (rt richText) String() string {
+	if len(rt.Runs) == 0 {
+		return rt.T
+	}
+	var b strings.Builder
+	b.WriteString(rt.T)
+	for _, r := range rt.Runs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+func readSharedStrings(f *zip.File) ([]string, error) {
+	var sst struct {
+		Items []richText `xml:"si"`
+	}
+	if err := decodeXML(f, &sst); err != nil {
+		return nil, err
+	}
+	strs := make([]string, len(sst.Items))
+	for i, item := range sst.Items {
+		strs[i] = item.String()
+	}
+	return strs, nil
+}
+
+// firstSheetPath resolves the part name of the first sheet through the workbook relationships
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	const fallback = "xl/worksheets/sheet1.xml"
+
+	wb, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", errors.New("not a valid xlsx file: workbook missing")
+	}
+	var workbook struct {
+		Sheets []struct {
+			RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodeXML(wb, &workbook); err != nil {
+		return "", err
+	}
+	rels, ok := files["xl/_rels/workbook.xml.rels"]
+	if len(workbook.Sheets) == 0 || !ok {
+		return fallback, nil
+	}
+
+	var relationships struct {
+		Items []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodeXML(rels, &relationships); err != nil {
+		return "", err
+	}
+	for _, rel := range relationships.Items {
+		if rel.ID == workbook.Sheets[0].RID {
+			if strings.HasPrefix(rel.Target, "/") {
+				return strings.TrimPrefix(rel.Target, "/"), nil
+			}
+			return path.Join("xl", rel.Target), nil
+		}
+	}
+	return fallback, nil
+}
+
+func decodeXML(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("invalid xlsx part %s: %w", f.Name, err)
+	}
+	return nil
+}
+
+// columnName converts a zero-based column index to letters (0 -> A, 26 -> AA)
+func columnName(i int) string {
+	name := ""
+	for i >= 0 {
+		name = string(rune('A'+i%26)) + name
+		i = i/26 - 1
+	}
+	return name
+}
+
+// columnIndex extracts the zero-based column index from a cell reference like "AB12"
+func columnIndex(ref string) (int, error) {
+	col := 0
+	n := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = col*26 + int(ch-'A'+1)
+		n++
+		if col > MaxColumns {
+			return 0, fmt.Errorf("cell reference %q is beyond the last column", ref)
+		}
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("invalid cell reference %q", ref)
+	}
+	return col - 1, nil
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+const contentTypesXML = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`</Types>`
+
+const rootRelsXML = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const workbookXML = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+	`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+const workbookRelsXML = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`</Relationships>`
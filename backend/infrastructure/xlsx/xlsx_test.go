@@ -0,0 +1,87 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestWriteRead tests that a written workbook reads back the same values
+func TestWriteRead(t *testing.T) {
+	rows := [][]string{
+		{"name", "price", "category"},
+		{"Cà phê sữa", "25000", "Coffee"},
+		{"Trà <đào> & cam", "", "Tea"},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, "Menu", rows); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got, err := Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// Empty cells are not written but keep their position
+	if !reflect.DeepEqual(got, rows) {
+		t.Errorf("Expected %q, got %q", rows, got)
+	}
+}
+
+// sheetWith builds a workbook whose only sheet has the given sheetData content
+func sheetWith(t *testing.T, sheetData string) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := map[string]string{
+		"xl/workbook.xml":          `<workbook><sheets></sheets></workbook>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData>` + sheetData + `</sheetData></worksheet>`,
+	}
+	for name, content := range files {
+		fw, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+// TestReadMalformedReferences tests that invalid row and column references are rejected
+func TestReadMalformedReferences(t *testing.T) {
+	tests := []struct {
+		name      string
+		sheetData string
+		wantErr   string
+	}{
+		{"negative row", `<row r="-3"><c r="A1"><v>1</v></c></row>`, "invalid row number"},
+		{"row beyond the sheet", `<row r="2000000"><c><v>1</v></c></row>`, "invalid row number"},
+		{"column beyond the sheet", `<row r="1"><c r="XFDXFDXFD1"><v>1</v></c></row>`, "beyond the last column"},
+		{"column after XFD", `<row r="1"><c r="XFE1"><v>1</v></c></row>`, "beyond the last column"},
+		{"no column letters", `<row r="1"><c r="12"><v>1</v></c></row>`, "invalid cell reference"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := sheetWith(t, tt.sheetData)
+			_, err := Read(r, r.Size())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	// The last column and rows without numbers are still accepted
+	r := sheetWith(t, `<row><c r="XFD1"><v>x</v></c></row><row><c><v>y</v></c></row>`)
+	rows, err := Read(r, r.Size())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(rows) != 2 || len(rows[0]) != MaxColumns || rows[0][MaxColumns-1] != "x" || rows[1][0] != "y" {
+		t.Errorf("Unexpected rows %d %q", len(rows), rows[1])
+	}
+}
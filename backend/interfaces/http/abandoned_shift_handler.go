@@ -0,0 +1,121 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"cafe-pos/backend/application/services"
+	"cafe-pos/backend/domain/abandoned"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AbandonedShiftHandler struct {
+	abandonedShiftService *services.AbandonedShiftService
+}
+
+func NewAbandonedShiftHandler(abandonedShiftService *services.AbandonedShiftService) *AbandonedShiftHandler {
+	return &AbandonedShiftHandler{abandonedShiftService: abandonedShiftService}
+}
+
+// GetAbandonedShifts lists flagged shifts. Query: status=FLAGGED|FORCE_ENDED|RECONCILED|CLOSED
+func (h *AbandonedShiftHandler) GetAbandonedShifts(c *gin.Context) {
+	status := abandoned.Status(c.Query("status"))
+	switch status {
+	case "", abandoned.StatusFlagged, abandoned.StatusForceEnded, abandoned.StatusReconciled, abandoned.StatusClosed:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+
+	records, err := h.abandonedShiftService.GetRecords(c.Request.Context(), status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+// CheckNow runs the abandoned shift check immediately and returns the newly flagged shifts
+func (h *AbandonedShiftHandler) CheckNow(c *gin.Context) {
+	records, err := h.abandonedShiftService.DetectAbandonedShifts(c.Request.Context(), time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if records == nil {
+		records = []*abandoned.Record{}
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+// GetAlerts - Shifts the manager still has to end or reconcile, with the unread count
+func (h *AbandonedShiftHandler) GetAlerts(c *gin.Context) {
+	alerts, err := h.abandonedShiftService.GetAlerts(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, alerts)
+}
+
+// Acknowledge - Mark a flagged shift as seen
+func (h *AbandonedShiftHandler) Acknowledge(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	username, _ := c.Get("username")
+	rec, err := h.abandonedShiftService.Acknowledge(c.Request.Context(), id, username.(string))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rec)
+}
+
+func (h *AbandonedShiftHandler) ForceEnd(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	username, _ := c.Get("username")
+	rec, err := h.abandonedShiftService.ForceEnd(c.Request.Context(), id, username.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rec)
+}
+
+func (h *AbandonedShiftHandler) Reconcile(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req abandoned.ReconcileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	username, _ := c.Get("username")
+	rec, err := h.abandonedShiftService.Reconcile(c.Request.Context(), id, &req, username.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rec)
+}
@@ -29,4 +29,21 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, resp)
-}
\ No newline at end of file
+}
+
+// IssuePriceOverrideToken - Manager enters credentials on a staff device to authorise a price override
+func (h *AuthHandler) IssuePriceOverrideToken(c *gin.Context) {
+	var req user.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.authService.IssuePriceOverrideToken(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -2,12 +2,10 @@ package http
 
 import (
 	"net/http"
-	"strconv"
 	"time"
 
 	"cafe-pos/backend/application/services"
 	"cafe-pos/backend/domain/handover"
-	"cafe-pos/backend/domain/user"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -577,6 +575,12 @@ func (h *CashHandoverHandler) ApproveDiscrepancy(c *gin.Context) {
 		return
 	}
 
+	username, exists := c.Get("username")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Username not found"})
+		return
+	}
+
 	// Parse request
 	var req handover.ApproveDiscrepancyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -596,6 +600,7 @@ func (h *CashHandoverHandler) ApproveDiscrepancy(c *gin.Context) {
 		c.Request.Context(),
 		handoverID,
 		managerID,
+		username.(string),
 		req.Approved,
 		req.ManagerNotes,
 	); err != nil {
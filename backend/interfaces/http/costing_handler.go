@@ -0,0 +1,89 @@
+package http
+
+import (
+	"net/http"
+
+	"cafe-pos/backend/application/services"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CostingHandler struct {
+	costingService *services.CostingService
+}
+
+func NewCostingHandler(costingService *services.CostingService) *CostingHandler {
+	return &CostingHandler{costingService: costingService}
+}
+
+// GetItemCosts - Theoretical cost, food cost % and gross margin of every menu item
+func (h *CostingHandler) GetItemCosts(c *gin.Context) {
+	costs, err := h.costingService.GetItemCosts(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"target_margin_percent": h.costingService.TargetMarginPercent(),
+		"items":                 costs,
+	})
+}
+
+// GetBelowTarget - Menu items whose gross margin is below the configured target
+func (h *CostingHandler) GetBelowTarget(c *gin.Context) {
+	costs, err := h.costingService.GetBelowTarget(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"target_margin_percent": h.costingService.TargetMarginPercent(),
+		"items":                 costs,
+	})
+}
+
+// GetItemCost - Cost breakdown of one menu item per recipe line
+func (h *CostingHandler) GetItemCost(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	cost, err := h.costingService.GetItemCost(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "menu item not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, cost)
+}
+
+// GetMenuEngineeringReport - Stars, plowhorses, puzzles and dogs per menu category
+// Query: from, to (YYYY-MM-DD, inclusive). Defaults to today.
+func (h *CostingHandler) GetMenuEngineeringReport(c *gin.Context) {
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.costingService.GetMenuEngineeringReport(c.Request.Context(), from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// RecalculateCosts - Refresh the cost snapshot stored on every menu item
+func (h *CostingHandler) RecalculateCosts(c *gin.Context) {
+	if err := h.costingService.RecalculateAll(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "menu costs recalculated"})
+}
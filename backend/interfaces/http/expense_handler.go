@@ -26,6 +26,7 @@ func (h *ExpenseHandler) CreateExpense(c *gin.Context) {
 		Description   string  `json:"description"`
 		PaymentMethod string  `json:"payment_method"`
 		Vendor        string  `json:"vendor,omitempty"`
+		SupplierID    string  `json:"supplier_id,omitempty"`
 		Notes         string  `json:"notes,omitempty"`
 	}
 	
@@ -47,6 +48,11 @@ func (h *ExpenseHandler) CreateExpense(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category_id"})
 		return
 	}
+
+	supplierID, ok := parseSupplierID(c, req.SupplierID)
+	if !ok {
+		return
+	}
 	
 	// Get username from context (set by auth middleware)
 	username, _ := c.Get("username")
@@ -62,6 +68,7 @@ func (h *ExpenseHandler) CreateExpense(c *gin.Context) {
 		Description:   req.Description,
 		PaymentMethod: req.PaymentMethod,
 		Vendor:        req.Vendor,
+		SupplierID:    supplierID,
 		Notes:         req.Notes,
 		CreatedBy:     createdBy,
 		CreatedAt:     time.Now(),
@@ -96,6 +103,11 @@ func (h *ExpenseHandler) GetExpenses(c *gin.Context) {
 			filter["category_id"] = id
 		}
 	}
+	if supplierID := c.Query("supplier_id"); supplierID != "" {
+		if id, err := primitive.ObjectIDFromHex(supplierID); err == nil {
+			filter["supplier_id"] = id
+		}
+	}
 	expenses, err := h.service.GetExpenses(c.Request.Context(), filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -118,6 +130,7 @@ func (h *ExpenseHandler) UpdateExpense(c *gin.Context) {
 		Description   string  `json:"description"`
 		PaymentMethod string  `json:"payment_method"`
 		Vendor        string  `json:"vendor,omitempty"`
+		SupplierID    string  `json:"supplier_id,omitempty"`
 		Notes         string  `json:"notes,omitempty"`
 	}
 	
@@ -139,6 +152,11 @@ func (h *ExpenseHandler) UpdateExpense(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category_id"})
 		return
 	}
+
+	supplierID, ok := parseSupplierID(c, req.SupplierID)
+	if !ok {
+		return
+	}
 	
 	e := expense.Expense{
 		ID:            id,
@@ -148,6 +166,7 @@ func (h *ExpenseHandler) UpdateExpense(c *gin.Context) {
 		Description:   req.Description,
 		PaymentMethod: req.PaymentMethod,
 		Vendor:        req.Vendor,
+		SupplierID:    supplierID,
 		Notes:         req.Notes,
 	}
 	
@@ -259,3 +278,16 @@ func (h *ExpenseHandler) DeletePrepaid(c *gin.Context) {
 	}
 	c.Status(http.StatusNoContent)
 }
+
+// parseSupplierID parses an optional supplier_id, writing a 400 response if it is invalid
+func parseSupplierID(c *gin.Context, hex string) (*primitive.ObjectID, bool) {
+	if hex == "" {
+		return nil, true
+	}
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid supplier_id"})
+		return nil, false
+	}
+	return &id, true
+}
@@ -1,7 +1,11 @@
 package http
 
 import (
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
+	"time"
 	"cafe-pos/backend/application/services"
 	"cafe-pos/backend/domain/ingredient"
 	"github.com/gin-gonic/gin"
@@ -32,7 +36,7 @@ func (h *IngredientHandler) CreateIngredient(c *gin.Context) {
 
 	item, err := h.ingredientService.CreateIngredient(c.Request.Context(), &req, createdBy)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(unitErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -82,7 +86,7 @@ func (h *IngredientHandler) UpdateIngredient(c *gin.Context) {
 
 	item, err := h.ingredientService.UpdateIngredient(c.Request.Context(), id, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(unitErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -119,6 +123,10 @@ func (h *IngredientHandler) AdjustStock(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if req.Quantity < 0 && (req.LotNumber != "" || req.ExpiryDate != nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "lot number and expiry date can only be recorded when adding stock"})
+		return
+	}
 
 	// Get user info from context
 	userID, _ := c.Get("user_id")
@@ -128,7 +136,7 @@ func (h *IngredientHandler) AdjustStock(c *gin.Context) {
 
 	item, err := h.ingredientService.AdjustStock(c.Request.Context(), id, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(unitErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -205,3 +213,157 @@ func (h *IngredientHandler) DeleteCategory(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "category deleted"})
 }
+
+// GetBatches - Stock batches of an ingredient, newest first
+// Query: active=true to skip depleted and written-off batches
+func (h *IngredientHandler) GetBatches(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	batches, err := h.ingredientService.GetBatches(c.Request.Context(), id, c.Query("active") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, batches)
+}
+
+// GetExpiring - Batches expiring within N days, including expired batches not yet written off
+// Query: days (default 3)
+func (h *IngredientHandler) GetExpiring(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "3"))
+	if err != nil || days < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a non-negative number"})
+		return
+	}
+
+	batches, err := h.ingredientService.GetExpiringBatches(c.Request.Context(), days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, batches)
+}
+
+// WriteOffBatch - Remove the remaining quantity of a batch from stock as waste
+func (h *IngredientHandler) WriteOffBatch(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req ingredient.WriteOffRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, _ := c.Get("user_id")
+	username, _ := c.Get("username")
+	req.UserID = userID.(string)
+	req.Username = username.(string)
+
+	batch, err := h.ingredientService.WriteOffBatch(c.Request.Context(), id, &req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, ingredient.ErrBatchNotActive) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, batch)
+}
+
+// WriteOffExpired - Write off every batch whose expiry date has passed
+func (h *IngredientHandler) WriteOffExpired(c *gin.Context) {
+	batches, err := h.ingredientService.WriteOffExpiredBatches(c.Request.Context(), time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if batches == nil {
+		batches = []*ingredient.Batch{}
+	}
+
+	c.JSON(http.StatusOK, batches)
+}
+
+// ProduceBatch - Make a batch of a prepared item from its recipe, consuming the recipe ingredients
+func (h *IngredientHandler) ProduceBatch(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req ingredient.ProduceRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, _ := c.Get("user_id")
+	username, _ := c.Get("username")
+	req.UserID = userID.(string)
+	req.Username = username.(string)
+
+	production, err := h.ingredientService.ProduceBatch(c.Request.Context(), id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, production)
+}
+
+// GetProductions - Production runs of a prepared item, newest first
+func (h *IngredientHandler) GetProductions(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	productions, err := h.ingredientService.GetProductions(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, productions)
+}
+
+// GetStockValuation - Value of the stock on hand by the configured costing method
+func (h *IngredientHandler) GetStockValuation(c *gin.Context) {
+	valuation, err := h.ingredientService.GetStockValuation(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, valuation)
+}
+
+// GetUnits - Supported units of measure with their dimension and size in the base unit
+func (h *IngredientHandler) GetUnits(c *gin.Context) {
+	c.JSON(http.StatusOK, ingredient.SupportedUnits())
+}
+
+// unitErrorStatus maps unknown or incompatible units and invalid recipes to 400, removing more
+// stock than is on hand or a concurrent unit or batch change to 409, other failures to 500
+func unitErrorStatus(err error) int {
+	if errors.Is(err, ingredient.ErrInvalidUnit) || errors.Is(err, ingredient.ErrInvalidRecipe) {
+		return http.StatusBadRequest
+	}
+	if errors.Is(err, ingredient.ErrInsufficientStock) || errors.Is(err, ingredient.ErrUnitChanged) ||
+		errors.Is(err, ingredient.ErrBatchNotActive) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
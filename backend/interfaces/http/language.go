@@ -0,0 +1,17 @@
+package http
+
+import (
+	"cafe-pos/backend/domain/menu"
+	"github.com/gin-gonic/gin"
+)
+
+// requestLanguages returns the preferred languages of the request: the lang query
+// parameter first, then the Accept-Language header. Vietnamese is the fallback.
+func requestLanguages(c *gin.Context) []string {
+	var langs []string
+	if lang := menu.NormalizeLanguage(c.Query("lang")); lang != "" {
+		langs = append(langs, lang)
+	}
+	langs = append(langs, menu.ParseAcceptLanguage(c.GetHeader("Accept-Language"))...)
+	return append(langs, menu.DefaultLanguage)
+}
@@ -0,0 +1,46 @@
+package http
+
+import (
+	"net/http"
+
+	"cafe-pos/backend/application/services"
+	"github.com/gin-gonic/gin"
+)
+
+type MenuAvailabilityHandler struct {
+	availabilityService *services.MenuAvailabilityService
+	menuService         *services.MenuService
+}
+
+func NewMenuAvailabilityHandler(availabilityService *services.MenuAvailabilityService, menuService *services.MenuService) *MenuAvailabilityHandler {
+	return &MenuAvailabilityHandler{
+		availabilityService: availabilityService,
+		menuService:         menuService,
+	}
+}
+
+// GetWaiterMenu - Menu items with remaining portions computed from ingredient stock
+func (h *MenuAvailabilityHandler) GetWaiterMenu(c *gin.Context) {
+	items, err := h.availabilityService.GetWaiterMenu(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.menuService.LocalizeItems(c.Request.Context(), items, requestLanguages(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// RefreshAvailability - Re-evaluate availability of all menu items against current stock
+func (h *MenuAvailabilityHandler) RefreshAvailability(c *gin.Context) {
+	if err := h.availabilityService.RefreshAvailability(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "menu availability refreshed"})
+}
@@ -25,7 +25,7 @@ func (h *MenuHandler) CreateMenuItem(c *gin.Context) {
 
 	item, err := h.menuService.CreateMenuItem(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(unitErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -39,6 +39,11 @@ func (h *MenuHandler) GetAllMenuItems(c *gin.Context) {
 		return
 	}
 
+	if err := h.menuService.LocalizeItems(c.Request.Context(), items, requestLanguages(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, items)
 }
 
@@ -56,6 +61,11 @@ func (h *MenuHandler) GetMenuItem(c *gin.Context) {
 		return
 	}
 
+	if err := h.menuService.LocalizeItems(c.Request.Context(), []*menu.MenuItem{item}, requestLanguages(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, item)
 }
 
@@ -75,7 +85,7 @@ func (h *MenuHandler) UpdateMenuItem(c *gin.Context) {
 
 	item, err := h.menuService.UpdateMenuItem(c.Request.Context(), id, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(unitErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -97,4 +107,67 @@ func (h *MenuHandler) DeleteMenuItem(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "menu item deleted"})
-}
\ No newline at end of file
+}
+
+func (h *MenuHandler) CreateCategory(c *gin.Context) {
+	var req menu.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cat, err := h.menuService.CreateCategory(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, cat)
+}
+
+func (h *MenuHandler) GetCategories(c *gin.Context) {
+	categories, err := h.menuService.GetCategories(c.Request.Context(), requestLanguages(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
+func (h *MenuHandler) UpdateCategory(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req menu.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cat, err := h.menuService.UpdateCategory(c.Request.Context(), id, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, cat)
+}
+
+func (h *MenuHandler) DeleteCategory(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.menuService.DeleteCategory(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "menu category deleted"})
+}
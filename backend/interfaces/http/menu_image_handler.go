@@ -0,0 +1,74 @@
+package http
+
+import (
+	"io"
+	"net/http"
+
+	"cafe-pos/backend/application/services"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MenuImageHandler struct {
+	imageService *services.MenuImageService
+}
+
+func NewMenuImageHandler(imageService *services.MenuImageService) *MenuImageHandler {
+	return &MenuImageHandler{imageService: imageService}
+}
+
+// UploadImage - Upload a photo for a menu item (multipart form field "image")
+func (h *MenuImageHandler) UploadImage(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("image")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "image file is required"})
+		return
+	}
+	if fileHeader.Size > services.MaxMenuImageBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "image is too large"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, services.MaxMenuImageBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	item, err := h.imageService.UploadImage(c.Request.Context(), id, data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *MenuImageHandler) DeleteImage(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	item, err := h.imageService.DeleteImage(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
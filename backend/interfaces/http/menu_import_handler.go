@@ -0,0 +1,118 @@
+package http
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cafe-pos/backend/infrastructure/xlsx"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxMenuImportBytes = 5 << 20
+	xlsxContentType    = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// ImportMenu - Bulk create/update menu items from a CSV or XLSX file (multipart field "file")
+// Query: dry_run=true to only validate and report the changes
+func (h *MenuHandler) ImportMenu(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	if fileHeader.Size > maxMenuImportBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file is too large"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxMenuImportBytes))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var records [][]string
+	switch strings.ToLower(filepath.Ext(fileHeader.Filename)) {
+	case ".csv":
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.FieldsPerRecord = -1
+		records, err = reader.ReadAll()
+	case ".xlsx":
+		records, err = xlsx.Read(bytes.NewReader(data), int64(len(data)))
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported file type, use .csv or .xlsx"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("cannot read file: %v", err)})
+		return
+	}
+
+	dryRun := c.Query("dry_run") == "true"
+	result, err := h.menuService.ImportMenu(c.Request.Context(), records, dryRun)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	status := http.StatusOK
+	if len(result.Errors) > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	c.JSON(status, result)
+}
+
+// ExportMenu - Download all menu items. Query: format=csv|xlsx (default csv)
+func (h *MenuHandler) ExportMenu(c *gin.Context) {
+	records, err := h.menuService.ExportMenu(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	writeSpreadsheet(c, fmt.Sprintf("menu-%s", time.Now().Format("20060102")), "Menu", records)
+}
+
+// GetImportTemplate - Download an import template with the expected columns and an example row
+func (h *MenuHandler) GetImportTemplate(c *gin.Context) {
+	writeSpreadsheet(c, "menu-template", "Menu", h.menuService.MenuImportTemplate())
+}
+
+// writeSpreadsheet sends records as a CSV or XLSX attachment depending on the format query parameter
+func writeSpreadsheet(c *gin.Context, filename, sheet string, records [][]string) {
+	var buf bytes.Buffer
+
+	if c.DefaultQuery("format", "csv") == "xlsx" {
+		if err := xlsx.Write(&buf, sheet, records); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.xlsx"`, filename))
+		c.Data(http.StatusOK, xlsxContentType, buf.Bytes())
+		return
+	}
+
+	// UTF-8 BOM so Excel shows Vietnamese text correctly
+	buf.WriteString("\ufeff")
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(records); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, filename))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cafe-pos/backend/application/services"
+	"cafe-pos/backend/domain/user"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestAuthMiddlewareRejectsOverrideToken tests that a price override token cannot be used to log in
+func TestAuthMiddlewareRejectsOverrideToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	jwtService := services.NewJWTService("test-secret")
+	manager := &user.User{ID: primitive.NewObjectID(), Username: "hoa", Role: user.RoleManager}
+
+	login, err := jwtService.GenerateToken(manager)
+	if err != nil {
+		t.Fatal(err)
+	}
+	override, _, err := jwtService.GeneratePriceOverrideToken(manager)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	router.GET("/protected", AuthMiddleware(jwtService), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"login token", login, http.StatusOK},
+		{"price override token", override, http.StatusUnauthorized},
+		{"no token", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("Expected %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+
+	// The override token itself is still valid for overriding prices
+	if claims, err := jwtService.ValidatePriceOverrideToken(override); err != nil || claims.ID == "" {
+		t.Errorf("Expected a valid override token with an ID, got %+v %v", claims, err)
+	}
+}
@@ -0,0 +1,131 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"cafe-pos/backend/application/services"
+	"cafe-pos/backend/domain/order"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type OrderSLAHandler struct {
+	slaService *services.OrderSLAService
+}
+
+func NewOrderSLAHandler(slaService *services.OrderSLAService) *OrderSLAHandler {
+	return &OrderSLAHandler{slaService: slaService}
+}
+
+// GetLateOrders - Live list of orders exceeding their SLA target
+func (h *OrderSLAHandler) GetLateOrders(c *gin.Context) {
+	orders, err := h.slaService.GetLateOrders(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, orders)
+}
+
+func (h *OrderSLAHandler) GetOrderTimings(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	timings, err := h.slaService.GetOrderTimings(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, timings)
+}
+
+func (h *OrderSLAHandler) GetTargets(c *gin.Context) {
+	targets, err := h.slaService.GetTargets(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, targets)
+}
+
+func (h *OrderSLAHandler) SetTarget(c *gin.Context) {
+	var req order.SLATargetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	target, err := h.slaService.SetTarget(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, target)
+}
+
+func (h *OrderSLAHandler) DeleteTarget(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.slaService.DeleteTarget(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "sla target deleted"})
+}
+
+// GetPrepTimeReport - Average and p90 preparation time per barista and hour of day
+// Query: from, to (YYYY-MM-DD, inclusive). Defaults to today.
+func (h *OrderSLAHandler) GetPrepTimeReport(c *gin.Context) {
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.slaService.GetPrepTimeReport(c.Request.Context(), from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// parseDateRange reads the from/to query parameters (YYYY-MM-DD) as an inclusive day range.
+// Missing values default to today. Writes a 400 response and returns false on invalid input.
+func parseDateRange(c *gin.Context) (time.Time, time.Time, bool) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	from, to := today, today
+
+	if v := c.Query("from"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, now.Location())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date. Use YYYY-MM-DD"})
+			return time.Time{}, time.Time{}, false
+		}
+		from = t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, now.Location())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date. Use YYYY-MM-DD"})
+			return time.Time{}, time.Time{}, false
+		}
+		to = t
+	}
+
+	return from, to.Add(24*time.Hour - time.Nanosecond), true
+}
@@ -0,0 +1,135 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"cafe-pos/backend/application/services"
+	"cafe-pos/backend/domain/payroll"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PayrollHandler struct {
+	payrollService *services.PayrollService
+}
+
+func NewPayrollHandler(payrollService *services.PayrollService) *PayrollHandler {
+	return &PayrollHandler{payrollService: payrollService}
+}
+
+func (h *PayrollHandler) RunPayroll(c *gin.Context) {
+	var req payroll.RunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	username, _ := c.Get("username")
+	run, err := h.payrollService.RunPayroll(c.Request.Context(), &req, username.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, run)
+}
+
+func (h *PayrollHandler) GetRuns(c *gin.Context) {
+	runs, err := h.payrollService.GetRuns(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, runs)
+}
+
+func (h *PayrollHandler) GetRun(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	run, err := h.payrollService.GetRun(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "payroll run not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+func (h *PayrollHandler) PostRun(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	username, _ := c.Get("username")
+	run, err := h.payrollService.PostRun(c.Request.Context(), id, username.(string))
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, payroll.ErrRunNotDraft) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+func (h *PayrollHandler) DeleteRun(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.payrollService.DeleteRun(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "payroll run deleted"})
+}
+
+// ExportRun - Download the payslips of a run. Query: format=csv (default) or xlsx
+func (h *PayrollHandler) ExportRun(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	run, err := h.payrollService.GetRun(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "payroll run not found"})
+		return
+	}
+
+	filename := fmt.Sprintf("payroll-%s-%s", run.From.Format("20060102"), run.To.Format("20060102"))
+	writeSpreadsheet(c, filename, "Payroll", run.ExportRecords())
+}
+
+// GetMyPayslips returns the posted payslips of the current user
+func (h *PayrollHandler) GetMyPayslips(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid, err := primitive.ObjectIDFromHex(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	slips, err := h.payrollService.GetMyPayslips(c.Request.Context(), uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, slips)
+}
@@ -0,0 +1,206 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"cafe-pos/backend/application/services"
+	"cafe-pos/backend/domain/purchasing"
+	"cafe-pos/backend/infrastructure/pdf"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PurchaseOrderHandler struct {
+	poService *services.PurchaseOrderService
+}
+
+func NewPurchaseOrderHandler(poService *services.PurchaseOrderService) *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{poService: poService}
+}
+
+func (h *PurchaseOrderHandler) CreatePurchaseOrder(c *gin.Context) {
+	var req purchasing.PurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	username, _ := c.Get("username")
+	po, err := h.poService.CreatePurchaseOrder(c.Request.Context(), &req, username.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, po)
+}
+
+// GetPurchaseOrders - List purchase orders, newest first
+// Query: status (DRAFT, SENT, PARTIALLY_RECEIVED, RECEIVED, CANCELLED)
+func (h *PurchaseOrderHandler) GetPurchaseOrders(c *gin.Context) {
+	orders, err := h.poService.GetPurchaseOrders(c.Request.Context(), purchasing.Status(c.Query("status")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, orders)
+}
+
+func (h *PurchaseOrderHandler) GetPurchaseOrder(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	po, err := h.poService.GetPurchaseOrder(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "purchase order not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, po)
+}
+
+func (h *PurchaseOrderHandler) UpdatePurchaseOrder(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req purchasing.PurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	po, err := h.poService.UpdatePurchaseOrder(c.Request.Context(), id, &req)
+	if err != nil {
+		c.JSON(purchaseOrderErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, po)
+}
+
+func (h *PurchaseOrderHandler) DeletePurchaseOrder(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.poService.DeletePurchaseOrder(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "purchase order deleted"})
+}
+
+// SendPurchaseOrder - Mark the purchase order as sent to the supplier
+func (h *PurchaseOrderHandler) SendPurchaseOrder(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	username, _ := c.Get("username")
+	po, err := h.poService.SendPurchaseOrder(c.Request.Context(), id, username.(string))
+	if err != nil {
+		c.JSON(purchaseOrderErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, po)
+}
+
+func (h *PurchaseOrderHandler) CancelPurchaseOrder(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	po, err := h.poService.CancelPurchaseOrder(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(purchaseOrderErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, po)
+}
+
+// ReceivePurchaseOrder - Receive goods fully or partially with actual quantities and prices
+func (h *PurchaseOrderHandler) ReceivePurchaseOrder(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req purchasing.ReceiveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	username, _ := c.Get("username")
+	po, err := h.poService.ReceivePurchaseOrder(c.Request.Context(), id, &req, userID.(string), username.(string))
+	if err != nil {
+		c.JSON(purchaseOrderErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, po)
+}
+
+// ExportPurchaseOrder - Download the purchase order to send to the supplier
+// Query: format=pdf (default), csv or xlsx
+func (h *PurchaseOrderHandler) ExportPurchaseOrder(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	po, err := h.poService.GetPurchaseOrder(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "purchase order not found"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "pdf")
+	if format == "csv" || format == "xlsx" {
+		writeSpreadsheet(c, po.PONumber, "Purchase order", po.ExportRecords())
+		return
+	}
+	if format != "pdf" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be pdf, csv or xlsx"})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.WriteText(&buf, po.PONumber, po.DocumentLines(pdf.CharsPerLine)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, po.PONumber))
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+// purchaseOrderErrorStatus maps a purchase order changed by another request to 409, other
+// failures to 400
+func purchaseOrderErrorStatus(err error) int {
+	if errors.Is(err, purchasing.ErrConcurrentUpdate) {
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
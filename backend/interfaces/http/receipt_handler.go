@@ -0,0 +1,46 @@
+package http
+
+import (
+	"net/http"
+
+	"cafe-pos/backend/application/services"
+	"cafe-pos/backend/domain/menu"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReceiptHandler struct {
+	receiptService *services.ReceiptService
+}
+
+func NewReceiptHandler(receiptService *services.ReceiptService) *ReceiptHandler {
+	return &ReceiptHandler{receiptService: receiptService}
+}
+
+// GetReceipt - Receipt of an order in Vietnamese and the customer's language
+// Query: lang (e.g. en) for the second language, format=text for plain text output
+func (h *ReceiptHandler) GetReceipt(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	// The receipt language is the customer's choice, not the staff device language
+	var langs []string
+	if lang := menu.NormalizeLanguage(c.Query("lang")); lang != "" {
+		langs = append(langs, lang)
+	}
+
+	receipt, err := h.receiptService.GetReceipt(c.Request.Context(), id, langs)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+		return
+	}
+
+	if c.Query("format") == "text" {
+		c.String(http.StatusOK, receipt.Text)
+		return
+	}
+	c.JSON(http.StatusOK, receipt)
+}
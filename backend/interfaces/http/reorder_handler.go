@@ -0,0 +1,47 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"cafe-pos/backend/application/services"
+	"cafe-pos/backend/domain/purchasing"
+	"github.com/gin-gonic/gin"
+)
+
+type ReorderHandler struct {
+	reorderService *services.ReorderService
+}
+
+func NewReorderHandler(reorderService *services.ReorderService) *ReorderHandler {
+	return &ReorderHandler{reorderService: reorderService}
+}
+
+// GetReorderSuggestions - Forecast days of cover and suggested purchase quantities per supplier
+// Query: lookback_days (history used, default 28), safety_days (default 2),
+// review_days (days an order should cover after delivery, default 7)
+func (h *ReorderHandler) GetReorderSuggestions(c *gin.Context) {
+	var params purchasing.ReorderParams
+	for param, dst := range map[string]*int{
+		"lookback_days": &params.LookbackDays,
+		"safety_days":   &params.SafetyDays,
+		"review_days":   &params.ReviewDays,
+	} {
+		if v := c.Query(param); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 || n > 365 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": param + " must be between 1 and 365"})
+				return
+			}
+			*dst = n
+		}
+	}
+
+	suggestions, err := h.reorderService.GetReorderSuggestions(c.Request.Context(), params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestions)
+}
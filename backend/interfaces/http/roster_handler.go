@@ -0,0 +1,164 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"cafe-pos/backend/application/services"
+	"cafe-pos/backend/domain/roster"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// scheduleDays is how many days ahead the roster is shown when no end date is given
+const scheduleDays = 14
+
+type RosterHandler struct {
+	rosterService *services.RosterService
+}
+
+func NewRosterHandler(rosterService *services.RosterService) *RosterHandler {
+	return &RosterHandler{rosterService: rosterService}
+}
+
+func (h *RosterHandler) PlanShifts(c *gin.Context) {
+	var req roster.PlannedShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	username, _ := c.Get("username")
+	planned, err := h.rosterService.PlanShifts(c.Request.Context(), &req, username.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, planned)
+}
+
+func (h *RosterHandler) UpdatePlannedShift(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req roster.PlannedShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	planned, err := h.rosterService.UpdatePlannedShift(c.Request.Context(), id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, planned)
+}
+
+func (h *RosterHandler) DeletePlannedShift(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.rosterService.DeletePlannedShift(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "planned shift deleted"})
+}
+
+// GetRoster returns the planned shifts between from and to (default the next two weeks),
+// optionally for one staff member given by user_id
+func (h *RosterHandler) GetRoster(c *gin.Context) {
+	from, to, ok := parseScheduleRange(c)
+	if !ok {
+		return
+	}
+
+	var userID *primitive.ObjectID
+	if v := c.Query("user_id"); v != "" {
+		id, err := primitive.ObjectIDFromHex(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+			return
+		}
+		userID = &id
+	}
+
+	planned, err := h.rosterService.GetRoster(c.Request.Context(), from, to, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, planned)
+}
+
+// GetMySchedule returns the planned shifts of the current user
+func (h *RosterHandler) GetMySchedule(c *gin.Context) {
+	from, to, ok := parseScheduleRange(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	uid, err := primitive.ObjectIDFromHex(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	planned, err := h.rosterService.GetRoster(c.Request.Context(), from, to, &uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, planned)
+}
+
+// GetAttendanceReport reports no-shows, late starts and early leaves for the planned shifts
+// between from and to. grace_minutes (default 5) is how late or early is tolerated.
+func (h *RosterHandler) GetAttendanceReport(c *gin.Context) {
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	grace := roster.DefaultGraceMinutes
+	if v := c.Query("grace_minutes"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > 120 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "grace_minutes must be between 0 and 120"})
+			return
+		}
+		grace = n
+	}
+
+	report, err := h.rosterService.GetAttendanceReport(c.Request.Context(), from, to, time.Duration(grace)*time.Minute)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// parseScheduleRange reads from/to like parseDateRange, but without a to date the range
+// runs scheduleDays from the start
+func parseScheduleRange(c *gin.Context) (time.Time, time.Time, bool) {
+	from, to, ok := parseDateRange(c)
+	if ok && c.Query("to") == "" {
+		to = from.AddDate(0, 0, scheduleDays).Add(-time.Nanosecond)
+	}
+	return from, to, ok
+}
@@ -139,6 +139,50 @@ func (h *ShiftHandler) CloseShift(c *gin.Context) {
 	c.JSON(http.StatusOK, shift)
 }
 
+// StartBreak starts a PAID or UNPAID break on the caller's open shift
+func (h *ShiftHandler) StartBreak(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req order.StartBreakRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	userOID, _ := primitive.ObjectIDFromHex(userID.(string))
+	shift, err := h.shiftService.StartBreak(c.Request.Context(), id, userOID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, shift)
+}
+
+// EndBreak ends the break in progress on the caller's shift
+func (h *ShiftHandler) EndBreak(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	userOID, _ := primitive.ObjectIDFromHex(userID.(string))
+	shift, err := h.shiftService.EndBreak(c.Request.Context(), id, userOID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, shift)
+}
+
 func (h *ShiftHandler) GetCurrentShift(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	role, _ := c.Get("role")
@@ -0,0 +1,110 @@
+package http
+
+import (
+	"net/http"
+
+	"cafe-pos/backend/application/services"
+	"cafe-pos/backend/domain/shifttype"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ShiftTypeHandler struct {
+	shiftTypeService *services.ShiftTypeService
+}
+
+func NewShiftTypeHandler(shiftTypeService *services.ShiftTypeService) *ShiftTypeHandler {
+	return &ShiftTypeHandler{shiftTypeService: shiftTypeService}
+}
+
+// GetActiveShiftTypes returns the shift types staff can start at this store
+func (h *ShiftTypeHandler) GetActiveShiftTypes(c *gin.Context) {
+	defs, err := h.shiftTypeService.GetActiveDefinitions(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, defs)
+}
+
+// GetShiftTypes returns the shift types of every store, including inactive ones
+func (h *ShiftTypeHandler) GetShiftTypes(c *gin.Context) {
+	defs, err := h.shiftTypeService.GetDefinitions(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, defs)
+}
+
+func (h *ShiftTypeHandler) CreateShiftType(c *gin.Context) {
+	var req shifttype.DefinitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	d, err := h.shiftTypeService.CreateDefinition(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, d)
+}
+
+func (h *ShiftTypeHandler) UpdateShiftType(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req shifttype.DefinitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	d, err := h.shiftTypeService.UpdateDefinition(c.Request.Context(), id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, d)
+}
+
+func (h *ShiftTypeHandler) DeleteShiftType(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.shiftTypeService.DeleteDefinition(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "shift type deleted"})
+}
+
+// GetShiftTypeReport returns shifts, hours, orders and revenue per shift type for the
+// shifts started between from and to (YYYY-MM-DD, default today)
+func (h *ShiftTypeHandler) GetShiftTypeReport(c *gin.Context) {
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.shiftTypeService.GetShiftTypeReport(c.Request.Context(), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,127 @@
+package http
+
+import (
+	"net/http"
+
+	"cafe-pos/backend/application/services"
+	"cafe-pos/backend/domain/stocktake"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type StocktakeHandler struct {
+	stocktakeService *services.StocktakeService
+}
+
+func NewStocktakeHandler(stocktakeService *services.StocktakeService) *StocktakeHandler {
+	return &StocktakeHandler{stocktakeService: stocktakeService}
+}
+
+// OpenStocktake - Start a physical count of all ingredients or one category
+func (h *StocktakeHandler) OpenStocktake(c *gin.Context) {
+	var req stocktake.OpenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	username, _ := c.Get("username")
+	st, err := h.stocktakeService.OpenStocktake(c.Request.Context(), &req, username.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, st)
+}
+
+// GetStocktakes - List stocktakes, newest first
+// Query: status (OPEN, CLOSED, CANCELLED)
+func (h *StocktakeHandler) GetStocktakes(c *gin.Context) {
+	stocktakes, err := h.stocktakeService.GetStocktakes(c.Request.Context(), stocktake.Status(c.Query("status")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stocktakes)
+}
+
+func (h *StocktakeHandler) GetStocktake(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	st, err := h.stocktakeService.GetStocktake(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "stocktake not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, st)
+}
+
+// RecordCounts - Enter counted quantities per ingredient and optional storage area
+func (h *StocktakeHandler) RecordCounts(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req stocktake.CountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	username, _ := c.Get("username")
+	st, err := h.stocktakeService.RecordCounts(c.Request.Context(), id, &req, username.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, st)
+}
+
+// CloseStocktake - Compute variances and adjust stock to the counted quantities
+func (h *StocktakeHandler) CloseStocktake(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req stocktake.CloseRequest
+	c.ShouldBindJSON(&req) // notes are optional
+
+	userID, _ := c.Get("user_id")
+	username, _ := c.Get("username")
+	st, err := h.stocktakeService.CloseStocktake(c.Request.Context(), id, &req, userID.(string), username.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, st)
+}
+
+func (h *StocktakeHandler) CancelStocktake(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	username, _ := c.Get("username")
+	st, err := h.stocktakeService.CancelStocktake(c.Request.Context(), id, username.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, st)
+}
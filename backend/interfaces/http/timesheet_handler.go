@@ -0,0 +1,57 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"cafe-pos/backend/application/services"
+	"github.com/gin-gonic/gin"
+)
+
+type TimesheetHandler struct {
+	timesheetService *services.TimesheetService
+}
+
+func NewTimesheetHandler(timesheetService *services.TimesheetService) *TimesheetHandler {
+	return &TimesheetHandler{timesheetService: timesheetService}
+}
+
+// GetTimesheets returns hours, night hours, overtime and holiday hours per employee for the
+// pay period from/to (default the current month up to today).
+// Query: format=json (default), csv or xlsx
+func (h *TimesheetHandler) GetTimesheets(c *gin.Context) {
+	from, to, ok := parsePayPeriod(c)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" && format != "xlsx" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json, csv or xlsx"})
+		return
+	}
+
+	ts, err := h.timesheetService.GetTimesheets(c.Request.Context(), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if format != "json" {
+		filename := fmt.Sprintf("timesheet-%s-%s", from.Format("20060102"), to.Format("20060102"))
+		writeSpreadsheet(c, filename, "Timesheet", ts.ExportRecords())
+		return
+	}
+	c.JSON(http.StatusOK, ts)
+}
+
+// parsePayPeriod reads from/to like parseDateRange, but without a from date the period
+// starts on the first day of the month of to
+func parsePayPeriod(c *gin.Context) (time.Time, time.Time, bool) {
+	from, to, ok := parseDateRange(c)
+	if ok && c.Query("from") == "" {
+		from = time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, to.Location())
+	}
+	return from, to, ok
+}
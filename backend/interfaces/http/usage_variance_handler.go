@@ -0,0 +1,53 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"cafe-pos/backend/application/services"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type UsageVarianceHandler struct {
+	usageVarianceService *services.UsageVarianceService
+}
+
+func NewUsageVarianceHandler(usageVarianceService *services.UsageVarianceService) *UsageVarianceHandler {
+	return &UsageVarianceHandler{usageVarianceService: usageVarianceService}
+}
+
+// GetUsageVarianceReport - Theoretical vs actual ingredient usage between two stocktakes
+// Query: opening, closing (stocktake IDs, default the two most recent closed stocktakes),
+// tolerance (percent of theoretical usage before flagging shrinkage, default 5)
+func (h *UsageVarianceHandler) GetUsageVarianceReport(c *gin.Context) {
+	var ids [2]*primitive.ObjectID
+	for i, param := range []string{"opening", "closing"} {
+		if v := c.Query(param); v != "" {
+			id, err := primitive.ObjectIDFromHex(v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param + " stocktake id"})
+				return
+			}
+			ids[i] = &id
+		}
+	}
+
+	tolerance := 0.0
+	if v := c.Query("tolerance"); v != "" {
+		t, err := strconv.ParseFloat(v, 64)
+		if err != nil || t < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "tolerance must be a non-negative number"})
+			return
+		}
+		tolerance = t
+	}
+
+	report, err := h.usageVarianceService.GetUsageVarianceReport(c.Request.Context(), ids[0], ids[1], tolerance)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -4,11 +4,19 @@ import (
 	"context"
 	"log"
 	"os"
+	"strconv"
 	"time"
 	"cafe-pos/backend/application/services"
 	"cafe-pos/backend/domain"
+	"cafe-pos/backend/domain/abandoned"
+	"cafe-pos/backend/domain/costing"
+	"cafe-pos/backend/domain/ingredient"
+	"cafe-pos/backend/domain/menu"
+	"cafe-pos/backend/domain/payroll"
+	"cafe-pos/backend/domain/timesheet"
 	"cafe-pos/backend/domain/user"
 	"cafe-pos/backend/infrastructure/mongodb"
+	"cafe-pos/backend/infrastructure/storage"
 	"cafe-pos/backend/interfaces/http"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -89,10 +97,20 @@ func main() {
 	stateMachineHandler := http.NewStateMachineHandler(smManager)
 	menuRepo := mongodb.NewMenuRepository(db)
 	menuService := services.NewMenuService(menuRepo)
+	menuCategoryRepo := mongodb.NewMenuCategoryRepository(db)
+	menuService.SetCategoryRepository(menuCategoryRepo)
 	menuHandler := http.NewMenuHandler(menuService)
 	ingredientRepo := mongodb.NewIngredientRepository(db)
 	stockHistoryRepo := mongodb.NewStockHistoryRepository(db)
 	ingredientService := services.NewIngredientService(ingredientRepo, stockHistoryRepo)
+	ingredientService.SetBatchRepository(mongodb.NewIngredientBatchRepository(db))
+	ingredientService.SetProductionRepository(mongodb.NewProductionRepository(db))
+	txRunner := mongodb.NewTransactionRunner(db)
+	if !txRunner.Supported(context.Background()) {
+		log.Println("⚠️ MongoDB is not a replica set: stock changes and their history are written WITHOUT transactions. Run MongoDB as a replica set (see docker-compose.yml).")
+	}
+	ingredientService.SetTransactionRunner(txRunner)
+	ingredientService.SetCostingMethod(costingMethod())
 	ingredientHandler := http.NewIngredientHandler(ingredientService)
 	facilityRepo := mongodb.NewFacilityRepository(db)
 	facilityService := services.NewFacilityService(facilityRepo)
@@ -100,12 +118,97 @@ func main() {
 	expenseRepo := mongodb.NewExpenseRepository(db)
 	expenseService := services.NewExpenseService(expenseRepo)
 	expenseHandler := http.NewExpenseHandler(expenseService)
+	slaTargetRepo := mongodb.NewSLATargetRepository(db)
+	orderSLAService := services.NewOrderSLAService(orderRepo, menuRepo, slaTargetRepo)
+	orderSLAHandler := http.NewOrderSLAHandler(orderSLAService)
 
 	// Auto Expense Service - wire up with other services
 	autoExpenseService := services.NewAutoExpenseService(expenseService)
 	ingredientService.SetAutoExpenseService(autoExpenseService)
 	facilityService.SetAutoExpenseService(autoExpenseService)
 
+	// Menu availability follows ingredient stock
+	menuAvailabilityService := services.NewMenuAvailabilityService(menuRepo, ingredientRepo)
+	ingredientService.SetMenuAvailabilityService(menuAvailabilityService)
+	menuService.SetMenuAvailabilityService(menuAvailabilityService)
+	menuService.SetIngredientRepository(ingredientRepo)
+	menuAvailabilityHandler := http.NewMenuAvailabilityHandler(menuAvailabilityService, menuService)
+
+	// Time-based menus and scheduled prices
+	menuScheduleRepo := mongodb.NewMenuScheduleRepository(db)
+	menuPriceRepo := mongodb.NewMenuPriceRepository(db)
+	menuScheduleService := services.NewMenuScheduleService(menuScheduleRepo, menuPriceRepo, menuRepo)
+	menuAvailabilityService.SetMenuScheduleService(menuScheduleService)
+	orderService.SetMenuRepository(menuRepo)
+	orderService.SetMenuScheduleService(menuScheduleService)
+	orderService.SetJWTService(jwtService)
+	menuScheduleHandler := http.NewMenuScheduleHandler(menuScheduleService)
+
+	// Bilingual receipts
+	receiptService := services.NewReceiptService(orderRepo, menuRepo)
+	receiptHandler := http.NewReceiptHandler(receiptService)
+
+	// Menu images
+	imageStorage, localUploadDir := newImageStorage()
+	menuImageService := services.NewMenuImageService(menuRepo, imageStorage)
+	menuImageHandler := http.NewMenuImageHandler(menuImageService)
+
+	// Recipe costing and gross margin
+	costingService := services.NewCostingService(menuRepo, ingredientRepo, targetMarginPercent())
+	ingredientService.SetCostingService(costingService)
+	menuService.SetCostingService(costingService)
+	costingService.SetOrderRepository(orderRepo)
+	costingHandler := http.NewCostingHandler(costingService)
+
+	// Purchase orders and goods receiving
+	purchaseOrderRepo := mongodb.NewPurchaseOrderRepository(db)
+	purchaseOrderService := services.NewPurchaseOrderService(purchaseOrderRepo, ingredientRepo, ingredientService)
+	purchaseOrderService.SetAutoExpenseService(autoExpenseService)
+	purchaseOrderHandler := http.NewPurchaseOrderHandler(purchaseOrderService)
+
+	// Supplier directory and price lists
+	supplierRepo := mongodb.NewSupplierRepository(db)
+	supplierService := services.NewSupplierService(supplierRepo, ingredientRepo, expenseService)
+	expenseService.SetSupplierService(supplierService)
+	ingredientService.SetSupplierService(supplierService)
+	facilityService.SetSupplierService(supplierService)
+	purchaseOrderService.SetSupplierService(supplierService)
+	supplierHandler := http.NewSupplierHandler(supplierService)
+	reorderService := services.NewReorderService(ingredientRepo, stockHistoryRepo, purchaseOrderRepo)
+	reorderService.SetSupplierService(supplierService)
+	reorderHandler := http.NewReorderHandler(reorderService)
+
+	// Stocktakes
+	stocktakeRepo := mongodb.NewStocktakeRepository(db)
+	stocktakeService := services.NewStocktakeService(stocktakeRepo, ingredientRepo, ingredientService)
+	stocktakeHandler := http.NewStocktakeHandler(stocktakeService)
+	usageVarianceService := services.NewUsageVarianceService(stocktakeRepo, stockHistoryRepo, menuRepo, ingredientRepo, orderRepo)
+	usageVarianceHandler := http.NewUsageVarianceHandler(usageVarianceService)
+
+	// Shift types configured per store
+	shiftTypeService := services.NewShiftTypeService(mongodb.NewShiftTypeRepository(db), shiftRepo, os.Getenv("STORE_CODE"))
+	shiftTypeHandler := http.NewShiftTypeHandler(shiftTypeService)
+	shiftService.SetShiftTypeService(shiftTypeService)
+
+	// Staff roster
+	plannedShiftRepo := mongodb.NewPlannedShiftRepository(db)
+	shiftService.SetPlannedShiftRepository(plannedShiftRepo)
+	rosterService := services.NewRosterService(plannedShiftRepo, shiftRepo, userRepo)
+	rosterService.SetShiftTypeService(shiftTypeService)
+	rosterHandler := http.NewRosterHandler(rosterService)
+
+	// Timesheets for payroll
+	timesheetService := services.NewTimesheetService(shiftRepo, cashierShiftRepo, timesheetRules())
+	timesheetHandler := http.NewTimesheetHandler(timesheetService)
+
+	// Payroll, posted as salary expenses
+	payrollService := services.NewPayrollService(mongodb.NewPayrollRepository(db), timesheetService, userRepo, cashDiscrepancyRepo, autoExpenseService, payroll.DefaultRules())
+	payrollHandler := http.NewPayrollHandler(payrollService)
+
+	// Shifts left open by staff
+	abandonedShiftService := services.NewAbandonedShiftService(mongodb.NewAbandonedShiftRepository(db), shiftRepo, cashierShiftRepo, shiftService, abandonedShiftPolicy())
+	abandonedShiftHandler := http.NewAbandonedShiftHandler(abandonedShiftService)
+
 	// Router
 	r := gin.Default()
 	
@@ -121,6 +224,11 @@ func main() {
 		c.Next()
 	})
 
+	// Uploaded files served directly when stored on the local filesystem
+	if localUploadDir != "" {
+		r.Static("/uploads", localUploadDir)
+	}
+
 	// Routes
 	api := r.Group("/api")
 	{
@@ -139,6 +247,8 @@ func main() {
 			// Common routes for all authenticated users
 			protected.GET("/profile", userManagementHandler.GetCurrentUser)
 			protected.POST("/change-password", userManagementHandler.ChangePassword)
+			// Manager authorises an order price override with their credentials
+			protected.POST("/price-override-token", authHandler.IssuePriceOverrideToken)
 			
 			// Shift management - available for waiter and barista only
 			// Note: Cashier shifts use separate endpoints under /cashier-shifts
@@ -147,6 +257,8 @@ func main() {
 				shifts.POST("/start", shiftHandler.StartShift)
 				shifts.POST("/:id/end", shiftHandler.EndShift)
 				shifts.POST("/:id/close", shiftHandler.CloseShift)
+				shifts.POST("/:id/break/start", shiftHandler.StartBreak)
+				shifts.POST("/:id/break/end", shiftHandler.EndBreak)
 				shifts.GET("/current", shiftHandler.GetCurrentShift)
 				shifts.GET("/my", shiftHandler.GetMyShifts)
 				shifts.GET("/:id", shiftHandler.GetShift)
@@ -157,6 +269,12 @@ func main() {
 				shifts.GET("/:id/pending-handover", cashHandoverHandler.GetPendingHandover)
 				shifts.GET("/:id/handovers", cashHandoverHandler.GetHandoverHistory)
 			}
+
+			// Planned shifts of the current user
+			protected.GET("/roster/my", rosterHandler.GetMySchedule)
+			protected.GET("/shift-types", shiftTypeHandler.GetActiveShiftTypes)
+			// Posted payslips of the current user
+			protected.GET("/payroll/my-payslips", payrollHandler.GetMyPayslips)
 			
 			// Cashier shift management - separate from waiter/barista shifts
 			cashierShifts := protected.Group("/cashier-shifts")
@@ -183,6 +301,23 @@ func main() {
 				cashierShiftsManager.GET("", cashierShiftHandler.GetAllCashierShifts)
 			}
 			
+			// Stocktakes - staff enter counts, managers open and close
+			stocktakes := protected.Group("/stocktakes")
+			stocktakes.Use(http.RequireRole(user.RoleWaiter, user.RoleBarista, user.RoleCashier, user.RoleManager))
+			{
+				stocktakes.GET("", stocktakeHandler.GetStocktakes)
+				stocktakes.GET("/:id", stocktakeHandler.GetStocktake)
+				stocktakes.POST("/:id/counts", stocktakeHandler.RecordCounts)
+			}
+
+			stocktakesManager := protected.Group("/stocktakes")
+			stocktakesManager.Use(http.RequireRole(user.RoleManager))
+			{
+				stocktakesManager.POST("", stocktakeHandler.OpenStocktake)
+				stocktakesManager.POST("/:id/close", stocktakeHandler.CloseStocktake)
+				stocktakesManager.POST("/:id/cancel", stocktakeHandler.CancelStocktake)
+			}
+			
 			// Waiter routes
 			waiter := protected.Group("/waiter")
 			waiter.Use(http.RequireRole(user.RoleWaiter, user.RoleCashier, user.RoleManager))
@@ -197,7 +332,9 @@ func main() {
 				waiter.GET("/orders/:id", orderHandler.GetOrder)
 				
 				// Menu (read-only)
-				waiter.GET("/menu", menuHandler.GetAllMenuItems)
+				waiter.GET("/menu", menuAvailabilityHandler.GetWaiterMenu)
+				waiter.GET("/menu-categories", menuHandler.GetCategories)
+				waiter.GET("/orders/:id/receipt", receiptHandler.GetReceipt)
 				
 				waiter.GET("/profile", func(c *gin.Context) {
 					c.JSON(200, gin.H{"message": "waiter access"})
@@ -218,6 +355,8 @@ func main() {
 				barista.GET("/orders/queue", orderHandler.GetQueuedOrders)
 				// View my orders (in progress + ready)
 				barista.GET("/orders/my", orderHandler.GetMyBaristaOrders)
+				// Orders exceeding their SLA target
+				barista.GET("/orders/late", orderSLAHandler.GetLateOrders)
 				// Accept order from queue
 				barista.POST("/orders/:id/accept", orderHandler.AcceptOrder)
 				// Mark order as ready
@@ -280,7 +419,28 @@ func main() {
 				// Menu management routes
 				manager.POST("/menu", menuHandler.CreateMenuItem)
 				manager.GET("/menu", menuHandler.GetAllMenuItems)
+				manager.GET("/menu/export", menuHandler.ExportMenu)
+				manager.GET("/menu/import-template", menuHandler.GetImportTemplate)
+				manager.POST("/menu/import", menuHandler.ImportMenu)
+				manager.GET("/menu-categories", menuHandler.GetCategories)
+				manager.POST("/menu-categories", menuHandler.CreateCategory)
+				manager.PUT("/menu-categories/:id", menuHandler.UpdateCategory)
+				manager.DELETE("/menu-categories/:id", menuHandler.DeleteCategory)
+				manager.GET("/menu/costs", costingHandler.GetItemCosts)
+				manager.GET("/menu/costs/below-target", costingHandler.GetBelowTarget)
+				manager.POST("/menu/costs/recalculate", costingHandler.RecalculateCosts)
 				manager.GET("/menu/:id", menuHandler.GetMenuItem)
+				manager.GET("/menu/:id/cost", costingHandler.GetItemCost)
+				manager.POST("/menu/refresh-availability", menuAvailabilityHandler.RefreshAvailability)
+				manager.POST("/menu/:id/image", menuImageHandler.UploadImage)
+				manager.DELETE("/menu/:id/image", menuImageHandler.DeleteImage)
+				manager.GET("/menu/:id/prices", menuScheduleHandler.GetPriceEntries)
+				manager.POST("/menu/:id/prices", menuScheduleHandler.CreatePriceEntry)
+				manager.DELETE("/menu-prices/:id", menuScheduleHandler.DeletePriceEntry)
+				manager.GET("/menu-schedules", menuScheduleHandler.GetSchedules)
+				manager.POST("/menu-schedules", menuScheduleHandler.CreateSchedule)
+				manager.PUT("/menu-schedules/:id", menuScheduleHandler.UpdateSchedule)
+				manager.DELETE("/menu-schedules/:id", menuScheduleHandler.DeleteSchedule)
 				manager.PUT("/menu/:id", menuHandler.UpdateMenuItem)
 				manager.DELETE("/menu/:id", menuHandler.DeleteMenuItem)
 				
@@ -288,16 +448,47 @@ func main() {
 				manager.POST("/ingredients", ingredientHandler.CreateIngredient)
 				manager.GET("/ingredients", ingredientHandler.GetAllIngredients)
 				manager.GET("/ingredients/low-stock", ingredientHandler.GetLowStock)
+				manager.GET("/ingredients/units", ingredientHandler.GetUnits)
+				manager.GET("/ingredients/expiring", ingredientHandler.GetExpiring)
+				manager.GET("/ingredients/valuation", ingredientHandler.GetStockValuation)
 				manager.GET("/ingredients/:id", ingredientHandler.GetIngredient)
 				manager.GET("/ingredients/:id/history", ingredientHandler.GetStockHistory)
 				manager.PUT("/ingredients/:id", ingredientHandler.UpdateIngredient)
 				manager.DELETE("/ingredients/:id", ingredientHandler.DeleteIngredient)
 				manager.POST("/ingredients/:id/adjust", ingredientHandler.AdjustStock)
+				manager.GET("/ingredients/:id/batches", ingredientHandler.GetBatches)
+				manager.POST("/ingredients/:id/produce", ingredientHandler.ProduceBatch)
+				manager.GET("/ingredients/:id/productions", ingredientHandler.GetProductions)
+				manager.POST("/ingredient-batches/write-off-expired", ingredientHandler.WriteOffExpired)
+				manager.POST("/ingredient-batches/:id/write-off", ingredientHandler.WriteOffBatch)
 				
 				// Ingredient category routes
 				manager.POST("/ingredient-categories", ingredientHandler.CreateCategory)
 				manager.GET("/ingredient-categories", ingredientHandler.GetCategories)
 				manager.DELETE("/ingredient-categories/:id", ingredientHandler.DeleteCategory)
+
+				// Purchase orders
+				manager.POST("/purchase-orders", purchaseOrderHandler.CreatePurchaseOrder)
+				manager.GET("/purchase-orders", purchaseOrderHandler.GetPurchaseOrders)
+				manager.GET("/purchase-orders/suggestions", reorderHandler.GetReorderSuggestions)
+				manager.GET("/purchase-orders/:id", purchaseOrderHandler.GetPurchaseOrder)
+				manager.PUT("/purchase-orders/:id", purchaseOrderHandler.UpdatePurchaseOrder)
+				manager.DELETE("/purchase-orders/:id", purchaseOrderHandler.DeletePurchaseOrder)
+				manager.POST("/purchase-orders/:id/send", purchaseOrderHandler.SendPurchaseOrder)
+				manager.POST("/purchase-orders/:id/cancel", purchaseOrderHandler.CancelPurchaseOrder)
+				manager.POST("/purchase-orders/:id/receive", purchaseOrderHandler.ReceivePurchaseOrder)
+				manager.GET("/purchase-orders/:id/export", purchaseOrderHandler.ExportPurchaseOrder)
+
+				// Suppliers and price lists
+				manager.POST("/suppliers", supplierHandler.CreateSupplier)
+				manager.GET("/suppliers", supplierHandler.GetSuppliers)
+				manager.GET("/suppliers/:id", supplierHandler.GetSupplier)
+				manager.PUT("/suppliers/:id", supplierHandler.UpdateSupplier)
+				manager.DELETE("/suppliers/:id", supplierHandler.DeleteSupplier)
+				manager.GET("/suppliers/:id/prices", supplierHandler.GetPriceList)
+				manager.POST("/suppliers/:id/prices", supplierHandler.AddPrice)
+				manager.DELETE("/supplier-prices/:id", supplierHandler.DeletePrice)
+				manager.GET("/ingredients/:id/supplier-prices", supplierHandler.CompareIngredientPrices)
 				
 				// Facility management routes
 				manager.GET("/facilities", facilityHandler.GetAllFacilities)
@@ -360,9 +551,51 @@ func main() {
 				manager.POST("/orders/:id/refund", orderHandler.RefundPartial)
 				manager.PUT("/orders/:id/edit", orderHandler.EditOrder)
 				
+				// Preparation SLA routes
+				manager.GET("/orders/late", orderSLAHandler.GetLateOrders)
+				manager.GET("/orders/:id/timings", orderSLAHandler.GetOrderTimings)
+				manager.GET("/sla-targets", orderSLAHandler.GetTargets)
+				manager.PUT("/sla-targets", orderSLAHandler.SetTarget)
+				manager.DELETE("/sla-targets/:id", orderSLAHandler.DeleteTarget)
+				manager.GET("/reports/prep-time", orderSLAHandler.GetPrepTimeReport)
+				manager.GET("/reports/menu-engineering", costingHandler.GetMenuEngineeringReport)
+				manager.GET("/reports/supplier-spend", supplierHandler.GetSpendReport)
+				manager.GET("/reports/usage-variance", usageVarianceHandler.GetUsageVarianceReport)
+				manager.GET("/reports/attendance", rosterHandler.GetAttendanceReport)
+				manager.GET("/reports/timesheets", timesheetHandler.GetTimesheets)
+				manager.GET("/reports/shift-types", shiftTypeHandler.GetShiftTypeReport)
+				
 				// Shift management routes
 				manager.GET("/shifts", shiftHandler.GetAllShifts)
 				manager.GET("/shifts/:id", shiftHandler.GetShift)
+
+				// Shift type routes
+				manager.GET("/shift-types", shiftTypeHandler.GetShiftTypes)
+				manager.POST("/shift-types", shiftTypeHandler.CreateShiftType)
+				manager.PUT("/shift-types/:id", shiftTypeHandler.UpdateShiftType)
+				manager.DELETE("/shift-types/:id", shiftTypeHandler.DeleteShiftType)
+
+				// Abandoned shift routes
+				manager.GET("/abandoned-shifts", abandonedShiftHandler.GetAbandonedShifts)
+				manager.GET("/abandoned-shifts/alerts", abandonedShiftHandler.GetAlerts)
+				manager.POST("/abandoned-shifts/check", abandonedShiftHandler.CheckNow)
+				manager.POST("/abandoned-shifts/:id/acknowledge", abandonedShiftHandler.Acknowledge)
+				manager.POST("/abandoned-shifts/:id/force-end", abandonedShiftHandler.ForceEnd)
+				manager.POST("/abandoned-shifts/:id/reconcile", abandonedShiftHandler.Reconcile)
+
+				// Staff roster routes
+				manager.GET("/roster", rosterHandler.GetRoster)
+				manager.POST("/roster", rosterHandler.PlanShifts)
+				manager.PUT("/roster/:id", rosterHandler.UpdatePlannedShift)
+				manager.DELETE("/roster/:id", rosterHandler.DeletePlannedShift)
+
+				// Payroll routes
+				manager.GET("/payroll", payrollHandler.GetRuns)
+				manager.POST("/payroll", payrollHandler.RunPayroll)
+				manager.GET("/payroll/:id", payrollHandler.GetRun)
+				manager.GET("/payroll/:id/export", payrollHandler.ExportRun)
+				manager.POST("/payroll/:id/post", payrollHandler.PostRun)
+				manager.DELETE("/payroll/:id", payrollHandler.DeleteRun)
 				
 				// Cash handover management routes
 				manager.GET("/cash-handovers/pending-approval", cashHandoverHandler.GetPendingApprovals)
@@ -375,6 +608,11 @@ func main() {
 	// Create default admin user
 	createDefaultUsers(authService, userRepo)
 
+	// Expired ingredient batches are written off as waste every hour
+	go ingredientService.RunExpiredBatchWriteOff(context.Background(), time.Hour)
+	// Shifts left open are flagged every 15 minutes
+	go abandonedShiftService.RunAbandonedShiftCheck(context.Background(), 15*time.Minute)
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "3000"
@@ -384,6 +622,117 @@ func main() {
 	r.Run(":" + port)
 }
 
+// targetMarginPercent reads the gross margin target from TARGET_GROSS_MARGIN (percent, default 70)
+func targetMarginPercent() float64 {
+	if v := os.Getenv("TARGET_GROSS_MARGIN"); v != "" {
+		if target, err := strconv.ParseFloat(v, 64); err == nil && target > 0 && target < 100 {
+			return target
+		}
+		log.Printf("⚠️ Invalid TARGET_GROSS_MARGIN %q, using default", v)
+	}
+	return costing.DefaultTargetMarginPercent
+}
+
+// costingMethod reads INGREDIENT_COSTING_METHOD: WEIGHTED_AVERAGE (default) or FIFO
+func costingMethod() ingredient.CostingMethod {
+	if v := os.Getenv("INGREDIENT_COSTING_METHOD"); v != "" {
+		if method, err := ingredient.ParseCostingMethod(v); err == nil {
+			return method
+		}
+		log.Printf("⚠️ Invalid INGREDIENT_COSTING_METHOD %q, using weighted average", v)
+	}
+	return ingredient.CostingWeightedAverage
+}
+
+// timesheetRules reads the overtime limits from TIMESHEET_DAILY_HOURS (default 8) and
+// TIMESHEET_WEEKLY_HOURS (default 48), and holidays from PUBLIC_HOLIDAYS, a comma separated
+// list of YYYY-MM-DD and yearly MM-DD dates (default the fixed-date Vietnamese holidays)
+func timesheetRules() timesheet.Rules {
+	rules := timesheet.DefaultRules()
+	if v := os.Getenv("TIMESHEET_DAILY_HOURS"); v != "" {
+		if hours, err := strconv.ParseFloat(v, 64); err == nil && hours > 0 && hours <= 24 {
+			rules.DailyLimitHours = hours
+		} else {
+			log.Printf("⚠️ Invalid TIMESHEET_DAILY_HOURS %q, using default", v)
+		}
+	}
+	if v := os.Getenv("TIMESHEET_WEEKLY_HOURS"); v != "" {
+		if hours, err := strconv.ParseFloat(v, 64); err == nil && hours > 0 && hours <= 168 {
+			rules.WeeklyLimitHours = hours
+		} else {
+			log.Printf("⚠️ Invalid TIMESHEET_WEEKLY_HOURS %q, using default", v)
+		}
+	}
+	if v := os.Getenv("PUBLIC_HOLIDAYS"); v != "" {
+		if holidays, err := timesheet.ParseHolidays(v); err == nil {
+			rules.Holidays = holidays
+		} else {
+			log.Printf("⚠️ Invalid PUBLIC_HOLIDAYS: %v, using defaults", err)
+		}
+	}
+	return rules
+}
+
+// abandonedShiftPolicy reads when open shifts are flagged: after ABANDONED_SHIFT_MAX_HOURS
+// (default 14) or at BUSINESS_DAY_CUTOFF (HH:MM, default 04:00, "off" to disable).
+// ABANDONED_SHIFT_FORCE_END=true also ends flagged waiter and barista shifts.
+func abandonedShiftPolicy() abandoned.Policy {
+	policy := abandoned.DefaultPolicy()
+	if v := os.Getenv("ABANDONED_SHIFT_MAX_HOURS"); v != "" {
+		if hours, err := strconv.ParseFloat(v, 64); err == nil && hours > 0 {
+			policy.MaxOpenHours = hours
+		} else {
+			log.Printf("⚠️ Invalid ABANDONED_SHIFT_MAX_HOURS %q, using default", v)
+		}
+	}
+	if v := os.Getenv("BUSINESS_DAY_CUTOFF"); v == "off" {
+		policy.CutoffMinutes = -1
+	} else if v != "" {
+		if minutes, err := menu.ParseClock(v); err == nil {
+			policy.CutoffMinutes = minutes
+		} else {
+			log.Printf("⚠️ Invalid BUSINESS_DAY_CUTOFF %q, using default", v)
+		}
+	}
+	policy.ForceEnd = os.Getenv("ABANDONED_SHIFT_FORCE_END") == "true"
+	return policy
+}
+
+// newImageStorage configures where uploaded images are stored.
+// IMAGE_STORAGE=s3 uses an S3-compatible bucket, otherwise files go to UPLOAD_DIR (default ./uploads).
+// Returns the local directory to serve under /uploads, empty when using S3.
+func newImageStorage() (services.ImageStorage, string) {
+	if os.Getenv("IMAGE_STORAGE") == "s3" {
+		s3Storage, err := storage.NewS3Storage(storage.S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PublicURL: os.Getenv("S3_PUBLIC_URL"),
+		})
+		if err != nil {
+			log.Fatalf("❌ Image storage configuration failed: %v", err)
+		}
+		log.Println("✅ Using S3 image storage")
+		return s3Storage, ""
+	}
+
+	uploadDir := os.Getenv("UPLOAD_DIR")
+	if uploadDir == "" {
+		uploadDir = "./uploads"
+	}
+	baseURL := os.Getenv("UPLOAD_BASE_URL")
+	if baseURL == "" {
+		baseURL = "/uploads"
+	}
+	localStorage, err := storage.NewLocalStorage(uploadDir, baseURL)
+	if err != nil {
+		log.Fatalf("❌ Image storage configuration failed: %v", err)
+	}
+	return localStorage, uploadDir
+}
+
 func createDefaultUsers(authService *services.AuthService, userRepo *mongodb.UserRepository) {
 	ctx := context.Background()
 	